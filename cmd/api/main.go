@@ -2,17 +2,39 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	"github.com/sawalreverr/recything/config"
+	adminRepo "github.com/sawalreverr/recything/internal/admin/repository"
+	articleRepo "github.com/sawalreverr/recything/internal/article/repository"
+	articleUc "github.com/sawalreverr/recything/internal/article/usecase"
 	"github.com/sawalreverr/recything/internal/database"
+	leaderboardRepo "github.com/sawalreverr/recything/internal/leaderboard/repository"
+	leaderboardUc "github.com/sawalreverr/recything/internal/leaderboard/usecase"
 	"github.com/sawalreverr/recything/internal/server"
 	"github.com/sawalreverr/recything/internal/task/manage_task/repository"
+	taskTemplateRepo "github.com/sawalreverr/recything/internal/task/task_template/repository"
+	taskTemplateUc "github.com/sawalreverr/recything/internal/task/task_template/usecase"
+	userRepo "github.com/sawalreverr/recything/internal/user/repository"
+	"github.com/sawalreverr/recything/internal/webhook"
+	webhookRepo "github.com/sawalreverr/recything/internal/webhook/repository"
+	webhookUc "github.com/sawalreverr/recything/internal/webhook/usecase"
 )
 
 func main() {
 	conf := config.GetConfig()
 	db := database.NewMySQLDatabase(conf)
+
+	// Carry the badge bonuses that used to be hard-coded onto achievements
+	database.MigrateAchievementBonus(db)
+
+	// Publish articles written before the editorial workflow existed
+	database.MigrateArticleStatus(db)
+
+	// Resize the custom ID columns before AutoMigrate reaches them
+	database.MigrateIDColumns(db)
+
 	database.AutoMigrate(db)
 
 	// Init User
@@ -57,17 +79,74 @@ func main() {
 	// Init Comment
 	db.InitComment()
 
+	// Sync ID sequences with seeded data
+	database.MigrateSequences(db)
+
+	// Order task steps and move participant progress onto current steps
+	database.MigrateTaskSteps(db)
+
+	// Record when older submissions were sent for review
+	database.MigrateSubmissions(db)
+
+	// Open the point ledger with the balances users already hold
+	database.MigratePointLedger(db)
+
+	// Link users to the achievement they hold
+	database.MigrateUserAchievements(db)
+
 	app := server.NewEchoServer(conf, db)
 
 	// cronjob for update status task
 	c := cron.New()
 
 	taskRepo := repository.NewManageTaskRepository(db)
-	c.AddFunc("@daily", func() {
-		log.Println("Updating task challenge status...")
+	// challenges start and end at day boundaries, checking every minute
+	// flips their status as soon as a boundary is crossed
+	c.AddFunc("@every 1m", func() {
 		taskRepo.UpdateTaskChallengeStatus()
 	})
 
+	templateUsecase := taskTemplateUc.NewTaskTemplateUsecase(taskTemplateRepo.NewTaskTemplateRepository(db))
+	c.AddFunc("@hourly", func() {
+		created, err := templateUsecase.GenerateInstancesUsecase(time.Now())
+		if err != nil {
+			log.Println("Generating task challenges from templates failed:", err)
+			return
+		}
+		log.Printf("Generated %d task challenge(s) from templates", created)
+	})
+
+	webhookUsecase := webhookUc.NewWebhookUsecase(webhookRepo.NewWebhookRepository(db), webhook.NewHTTPSender(webhook.SendTimeout))
+	// a slow subscriber can make a run outlast the interval, the next run is
+	// skipped instead of overlapping it
+	c.AddJob("@every 30s", cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
+		if _, err := webhookUsecase.ProcessDueDeliveries(50); err != nil {
+			log.Println("Processing webhook deliveries failed:", err)
+		}
+	})))
+
+	seasonUsecase := leaderboardUc.NewLeaderboardUsecase(leaderboardRepo.NewLeaderboardRepository(db))
+	c.AddFunc("@every 1m", func() {
+		closed, err := seasonUsecase.CloseEndedSeasonsUsecase(time.Now())
+		if err != nil {
+			log.Println("Closing ended seasons failed:", err)
+		}
+		if closed > 0 {
+			log.Printf("Closed %d season(s)", closed)
+		}
+	})
+
+	articleUsecase := articleUc.NewArticleUsecase(articleRepo.NewArticleRepository(db), adminRepo.NewAdminRepository(db), userRepo.NewUserRepository(db))
+	c.AddFunc("@every 1m", func() {
+		published, err := articleUsecase.PublishScheduled(time.Now())
+		if err != nil {
+			log.Println("Publishing scheduled articles failed:", err)
+		}
+		if published > 0 {
+			log.Printf("Published %d scheduled article(s)", published)
+		}
+	})
+
 	c.Start()
 	defer c.Stop()
 
@@ -0,0 +1,46 @@
+// Command reconcile-points compares every user's cached point balance with the
+// sum of their point ledger entries. With -fix it resets drifted caches to the
+// ledger sum, otherwise it exits non-zero when any drift is found.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/sawalreverr/recything/config"
+	"github.com/sawalreverr/recything/internal/database"
+	pointRepo "github.com/sawalreverr/recything/internal/point/repository"
+	pointUc "github.com/sawalreverr/recything/internal/point/usecase"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "reset drifted cached balances to the ledger sum")
+	flag.Parse()
+
+	conf := config.GetConfig()
+	db := database.NewMySQLDatabase(conf)
+
+	usecase := pointUc.NewPointUsecase(pointRepo.NewPointRepository(db))
+	report, err := usecase.Reconcile(*fix)
+	if err != nil {
+		log.Fatalf("Reconciling points failed: %v", err)
+	}
+
+	for _, drift := range report.Drifts {
+		log.Printf("user %s: cached %d, ledger %d", drift.UserID, drift.Cached, drift.Ledger)
+	}
+
+	if *fix {
+		log.Printf("%d drifted balances found, %d fixed", len(report.Drifts), report.Fixed)
+		if report.Fixed < len(report.Drifts) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	log.Printf("%d drifted balances found", len(report.Drifts))
+	if len(report.Drifts) > 0 {
+		os.Exit(1)
+	}
+}
@@ -12,6 +12,7 @@ type (
 		SMTP       *SMTP
 		OpenAI     *OpenAI
 		YouTube    *YouTube
+		Report     *Report
 	}
 
 	Server struct {
@@ -47,6 +48,10 @@ type (
 	YouTube struct {
 		APIKey string
 	}
+
+	Report struct {
+		LocationGrid float64
+	}
 )
 
 var (
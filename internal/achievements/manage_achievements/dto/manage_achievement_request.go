@@ -1,6 +1,20 @@
 package dto
 
+type CreateAchievementRequest struct {
+	Level           string `json:"level" form:"level" validate:"required,max=50"`
+	Kind            string `json:"kind" form:"kind" validate:"required,oneof=point report_count challenge_count weekly_streak waste_material"`
+	Description     string `json:"description" form:"description"`
+	TargetPoint     int    `json:"target_point" form:"target_point" validate:"min=0"`
+	TargetCount     int    `json:"target_count" form:"target_count" validate:"min=0"`
+	WasteMaterialId string `json:"waste_material_id" form:"waste_material_id"`
+	BonusPercent    int    `json:"bonus_percent" form:"bonus_percent" validate:"min=0,max=100"`
+}
+
 type UpdateAchievementRequest struct {
 	Level       string `json:"level" form:"level"`
+	Description string `json:"description" form:"description"`
 	TargetPoint int    `json:"target_point" form:"target_point"`
+	TargetCount int    `json:"target_count" form:"target_count" validate:"min=0"`
+	// BonusPercent is left unchanged when omitted.
+	BonusPercent *int `json:"bonus_percent" form:"bonus_percent" validate:"omitempty,min=0,max=100"`
 }
@@ -1,12 +1,39 @@
 package dto
 
+import "time"
+
 type DataAchievement struct {
-	Id          int    `json:"id"`
-	Level       string `json:"level"`
-	TargetPoint int    `json:"target_point"`
-	BadgeUrl    string `json:"badge_url"`
+	Id              int    `json:"id"`
+	Level           string `json:"level"`
+	Kind            string `json:"kind"`
+	Description     string `json:"description"`
+	TargetPoint     int    `json:"target_point"`
+	TargetCount     int    `json:"target_count"`
+	WasteMaterialId string `json:"waste_material_id"`
+	BonusPercent    int    `json:"bonus_percent"`
+	BadgeUrl        string `json:"badge_url"`
+	BadgeUrlUser    string `json:"badge_url_user"`
+}
+
+type CreateAchievementResponse struct {
+	Achievement *DataAchievement `json:"achievement"`
+	LevelJob    *DataLevelJob    `json:"level_job"`
 }
 
 type GetAllAchievementResponse struct {
 	Data []*DataAchievement `json:"data"`
 }
+
+type DataLevelJob struct {
+	Id             uint       `json:"id"`
+	Reason         string     `json:"reason"`
+	Status         string     `json:"status"`
+	TotalUsers     int        `json:"total_users"`
+	ProcessedUsers int        `json:"processed_users"`
+	ChangedUsers   int        `json:"changed_users"`
+	Progress       int        `json:"progress"`
+	Error          string     `json:"error"`
+	StartedAt      *time.Time `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
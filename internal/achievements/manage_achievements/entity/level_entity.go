@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// level recompute job status
+const (
+	LevelJobQueued  = "queued"
+	LevelJobRunning = "running"
+	LevelJobDone    = "done"
+	LevelJobFailed  = "failed"
+)
+
+// causes of a level change
+const (
+	LevelChangePoint     = "point_change"
+	LevelChangeRecompute = "recompute"
+)
+
+// LevelRecomputeJob re-evaluates the achievement of every user after the
+// achievements themselves changed. It runs in the background and its counters
+// are updated as it goes, so the admin that triggered it can follow along.
+type LevelRecomputeJob struct {
+	ID             uint   `gorm:"primaryKey"`
+	TriggeredBy    string `gorm:"index"`
+	Reason         string
+	Status         string `gorm:"type:enum('queued', 'running', 'done', 'failed');default:'queued'"`
+	TotalUsers     int
+	ProcessedUsers int
+	ChangedUsers   int
+	Error          string `gorm:"type:text"`
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// UserLevelChange records a user moving from one achievement to another, Point
+// is the earned points the move was based on. JobID is set when the move came
+// from a recompute job.
+type UserLevelChange struct {
+	ID                uint   `gorm:"primaryKey"`
+	UserID            string `gorm:"index;type:varchar(20)"`
+	FromAchievementID int
+	ToAchievementID   int
+	Point             int
+	Cause             string    `gorm:"type:enum('point_change', 'recompute')"`
+	JobID             *uint     `gorm:"index"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+}
@@ -6,13 +6,66 @@ import (
 	"gorm.io/gorm"
 )
 
+// achievement kinds
+const (
+	KindPoint          = "point"
+	KindReportCount    = "report_count"
+	KindChallengeCount = "challenge_count"
+	KindWeeklyStreak   = "weekly_streak"
+	KindWasteMaterial  = "waste_material"
+)
+
+// Achievement is either a badge level or a collectible. Point achievements
+// are the levels: users hold the highest one their points reach and
+// BonusPercent is the extra share of every task reward it earns. The other
+// kinds are unlocked once TargetCount is reached, WasteMaterialID names the
+// material counted by waste material achievements.
 type Achievement struct {
-	ID           int    `json:"id" gorm:"primaryKey"`
-	Level        string `gorm:"unique"`
-	TargetPoint  int
-	BadgeUrl     string
-	BadgeUrlUser string
-	CreatedAt    time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ID              int    `json:"id" gorm:"primaryKey"`
+	Level           string `gorm:"unique"`
+	Kind            string `gorm:"type:enum('point', 'report_count', 'challenge_count', 'weekly_streak', 'waste_material');default:'point'"`
+	Description     string
+	TargetPoint     int
+	TargetCount     int
+	WasteMaterialID string `gorm:"type:varchar(50)"`
+	BonusPercent    int    `gorm:"default:0"`
+	BadgeUrl        string
+	BadgeUrlUser    string
+	CreatedAt       time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// Target is the amount to reach, points for levels and a count otherwise.
+func (a Achievement) Target() int {
+	if a.Kind == KindPoint {
+		return a.TargetPoint
+	}
+	return a.TargetCount
+}
+
+// PointLevels limits a query to the achievements that are levels.
+func PointLevels(db *gorm.DB) *gorm.DB {
+	return db.Where("kind = ?", KindPoint)
+}
+
+// ReachedBy limits a query to the levels the given earned points reach.
+func ReachedBy(earned int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(PointLevels).Where("target_point <= ?", earned)
+	}
+}
+
+// LevelReachedBy returns the highest of the levels the given earned points
+// reach, nil when they reach none. Levels must be ordered by target point,
+// the lowest first.
+func LevelReachedBy(levels []Achievement, earned int) *Achievement {
+	var reached *Achievement
+	for i := range levels {
+		if levels[i].TargetPoint > earned {
+			break
+		}
+		reached = &levels[i]
+	}
+	return reached
 }
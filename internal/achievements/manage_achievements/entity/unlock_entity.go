@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// UserAchievement is an achievement a user has unlocked. Once unlocked it
+// stays unlocked, even if the progress that earned it is later undone.
+type UserAchievement struct {
+	ID            uint   `gorm:"primaryKey"`
+	UserID        string `gorm:"type:varchar(20);uniqueIndex:idx_user_achievement"`
+	AchievementID int    `gorm:"uniqueIndex:idx_user_achievement"`
+	UnlockedAt    time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
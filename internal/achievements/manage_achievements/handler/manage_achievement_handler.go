@@ -3,8 +3,13 @@ package handler
 import "github.com/labstack/echo/v4"
 
 type ManageAchievementHandler interface {
+	CreateAchievementHandler(c echo.Context) error
 	GetAllAchievementHandler(c echo.Context) error
 	GetAchievementByIdHandler(c echo.Context) error
 	UpdateAchievementHandler(c echo.Context) error
 	DeleteAchievementHandler(c echo.Context) error
+
+	StartLevelRecomputeHandler(c echo.Context) error
+	GetLevelJobHandler(c echo.Context) error
+	GetLevelJobsHandler(c echo.Context) error
 }
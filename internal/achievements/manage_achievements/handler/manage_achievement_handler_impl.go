@@ -7,6 +7,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/sawalreverr/recything/internal/achievements/manage_achievements/dto"
+	"github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/achievements/manage_achievements/usecase"
 	"github.com/sawalreverr/recything/internal/helper"
 	"github.com/sawalreverr/recything/pkg"
@@ -20,6 +21,46 @@ func NewManageAchievementHandler(usecae usecase.ManageAchievementUsecase) Manage
 	return &ManageAchievementHandlerImpl{usecae: usecae}
 }
 
+func (handler ManageAchievementHandlerImpl) CreateAchievementHandler(c echo.Context) error {
+	request := dto.CreateAchievementRequest{}
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "invalid request body, detail : "+err.Error())
+	}
+	badge, _ := c.FormFile("badge")
+	badgeUser, _ := c.FormFile("badge_user")
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	achievement, job, err := handler.usecae.CreateAchievementUsecase(&request, badge, badgeUser, adminId)
+	if err != nil {
+		if errors.Is(err, pkg.ErrAchievementLevelAlreadyExist) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrAchievementLevelAlreadyExist.Error())
+		}
+		if errors.Is(err, pkg.ErrBadge) || errors.Is(err, pkg.ErrAchievementTarget) || errors.Is(err, pkg.ErrAchievementWasteMaterial) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, pkg.ErrFileTooLarge) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrFileTooLarge.Error())
+		}
+		if errors.Is(err, pkg.ErrInvalidFileType) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrInvalidFileType.Error())
+		}
+		if errors.Is(err, pkg.ErrOpenFile) {
+			return helper.ErrorHandler(c, http.StatusInternalServerError, pkg.ErrOpenFile.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, details: "+err.Error())
+	}
+
+	responseData := &dto.CreateAchievementResponse{
+		Achievement: achievementData(achievement),
+		LevelJob:    levelJobData(job),
+	}
+	return helper.ResponseHandler(c, http.StatusCreated, "achievement created", responseData)
+}
+
 func (handler ManageAchievementHandlerImpl) GetAllAchievementHandler(c echo.Context) error {
 	achievements, err := handler.usecae.GetAllArchievementUsecase()
 	if err != nil {
@@ -27,12 +68,7 @@ func (handler ManageAchievementHandlerImpl) GetAllAchievementHandler(c echo.Cont
 	}
 	var data []*dto.DataAchievement
 	for _, achievement := range achievements {
-		data = append(data, &dto.DataAchievement{
-			Id:          achievement.ID,
-			Level:       achievement.Level,
-			TargetPoint: achievement.TargetPoint,
-			BadgeUrl:    achievement.BadgeUrl,
-		})
+		data = append(data, achievementData(achievement))
 	}
 	responseData := &dto.GetAllAchievementResponse{
 		Data: data,
@@ -55,13 +91,7 @@ func (handler ManageAchievementHandlerImpl) GetAchievementByIdHandler(c echo.Con
 		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, details: "+err.Error())
 	}
-	responseData := &dto.DataAchievement{
-		Id:          achievement.ID,
-		Level:       achievement.Level,
-		TargetPoint: achievement.TargetPoint,
-		BadgeUrl:    achievement.BadgeUrl,
-	}
-	return helper.ResponseHandler(c, http.StatusOK, "Success", responseData)
+	return helper.ResponseHandler(c, http.StatusOK, "Success", achievementData(achievement))
 }
 
 func (handler ManageAchievementHandlerImpl) UpdateAchievementHandler(c echo.Context) error {
@@ -76,8 +106,13 @@ func (handler ManageAchievementHandlerImpl) UpdateAchievementHandler(c echo.Cont
 	if err := c.Bind(&request); err != nil {
 		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
 	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "invalid request body, detail : "+err.Error())
+	}
 	badge, _ := c.FormFile("badge")
-	err := handler.usecae.UpdateAchievementUsecase(&request, badge, achievementIdInt)
+	badgeUser, _ := c.FormFile("badge_user")
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	job, err := handler.usecae.UpdateAchievementUsecase(&request, badge, badgeUser, achievementIdInt, adminId)
 	if err != nil {
 		if errors.Is(err, pkg.ErrAchievementNotFound) {
 			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrAchievementNotFound.Error())
@@ -96,7 +131,7 @@ func (handler ManageAchievementHandlerImpl) UpdateAchievementHandler(c echo.Cont
 		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, details: "+err.Error())
 	}
-	return helper.ResponseHandler(c, http.StatusOK, "achievement updated", nil)
+	return helper.ResponseHandler(c, http.StatusOK, "achievement updated", levelJobData(job))
 }
 
 func (handler ManageAchievementHandlerImpl) DeleteAchievementHandler(c echo.Context) error {
@@ -105,12 +140,95 @@ func (handler ManageAchievementHandlerImpl) DeleteAchievementHandler(c echo.Cont
 	if errConvert != nil {
 		return helper.ErrorHandler(c, http.StatusBadRequest, "Invalid request param, details: "+errConvert.Error())
 	}
-	err := handler.usecae.DeleteAchievementUsecase(achievementIdInt)
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	job, err := handler.usecae.DeleteAchievementUsecase(achievementIdInt, adminId)
 	if err != nil {
 		if errors.Is(err, pkg.ErrAchievementNotFound) {
 			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrAchievementNotFound.Error())
 		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, details: "+err.Error())
 	}
-	return helper.ResponseHandler(c, http.StatusOK, "achievement deleted", nil)
+	return helper.ResponseHandler(c, http.StatusOK, "achievement deleted", levelJobData(job))
+}
+
+func (handler ManageAchievementHandlerImpl) StartLevelRecomputeHandler(c echo.Context) error {
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	job, err := handler.usecae.StartLevelRecomputeUsecase(adminId, "started manually")
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, details: "+err.Error())
+	}
+	return helper.ResponseHandler(c, http.StatusAccepted, "level recompute started", levelJobData(job))
+}
+
+func (handler ManageAchievementHandlerImpl) GetLevelJobHandler(c echo.Context) error {
+	jobId, errConvert := strconv.Atoi(c.Param("jobId"))
+	if errConvert != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "Invalid request param, details: "+errConvert.Error())
+	}
+
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	job, err := handler.usecae.GetLevelJobUsecase(uint(jobId), adminId)
+	if err != nil {
+		if errors.Is(err, pkg.ErrLevelJobNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrLevelJobNotFound.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, details: "+err.Error())
+	}
+	return helper.ResponseHandler(c, http.StatusOK, "Success", levelJobData(job))
+}
+
+func (handler ManageAchievementHandlerImpl) GetLevelJobsHandler(c echo.Context) error {
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	jobs, err := handler.usecae.GetLevelJobsUsecase(adminId)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, details: "+err.Error())
+	}
+
+	data := []*dto.DataLevelJob{}
+	for _, job := range jobs {
+		data = append(data, levelJobData(job))
+	}
+	return helper.ResponseHandler(c, http.StatusOK, "Success", data)
+}
+
+func achievementData(achievement *entity.Achievement) *dto.DataAchievement {
+	return &dto.DataAchievement{
+		Id:              achievement.ID,
+		Level:           achievement.Level,
+		Kind:            achievement.Kind,
+		Description:     achievement.Description,
+		TargetPoint:     achievement.TargetPoint,
+		TargetCount:     achievement.TargetCount,
+		WasteMaterialId: achievement.WasteMaterialID,
+		BonusPercent:    achievement.BonusPercent,
+		BadgeUrl:        achievement.BadgeUrl,
+		BadgeUrlUser:    achievement.BadgeUrlUser,
+	}
+}
+
+func levelJobData(job *entity.LevelRecomputeJob) *dto.DataLevelJob {
+	if job == nil {
+		return nil
+	}
+
+	progress := 0
+	if job.Status == entity.LevelJobDone {
+		progress = 100
+	} else if job.TotalUsers > 0 {
+		progress = job.ProcessedUsers * 100 / job.TotalUsers
+	}
+
+	return &dto.DataLevelJob{
+		Id:             job.ID,
+		Reason:         job.Reason,
+		Status:         job.Status,
+		TotalUsers:     job.TotalUsers,
+		ProcessedUsers: job.ProcessedUsers,
+		ChangedUsers:   job.ChangedUsers,
+		Progress:       progress,
+		Error:          job.Error,
+		StartedAt:      job.StartedAt,
+		FinishedAt:     job.FinishedAt,
+		CreatedAt:      job.CreatedAt,
+	}
 }
@@ -2,12 +2,25 @@ package repository
 
 import (
 	archievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	"github.com/sawalreverr/recything/internal/user"
 )
 
 type ManageAchievementRepository interface {
 	FindArchievementByLevel(level string) (*archievement.Achievement, error)
 	GetAllArchievement() ([]*archievement.Achievement, error)
+	GetLevelAchievements() ([]*archievement.Achievement, error)
+	CreateAchievement(achievement *archievement.Achievement) error
+	WasteMaterialExists(id string) (bool, error)
 	GetAchievementById(id int) (*archievement.Achievement, error)
 	UpdateAchievement(achievement *archievement.Achievement, id int) error
 	DeleteAchievement(id int) error
+
+	CreateLevelJob(job *archievement.LevelRecomputeJob) error
+	FindLevelJob(jobId uint) (*archievement.LevelRecomputeJob, error)
+	FindLevelJobsByAdmin(adminId string) ([]*archievement.LevelRecomputeJob, error)
+	UpdateLevelJob(jobId uint, fields map[string]interface{}) error
+	CountUsers() (int, error)
+	FindUsersAfter(lastId string, limit int) ([]*user.User, error)
+	EarnedPoints(userIds []string) (map[string]int, error)
+	ApplyLevelChange(change *archievement.UserLevelChange, badge string, balance uint) (bool, error)
 }
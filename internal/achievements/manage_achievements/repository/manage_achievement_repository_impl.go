@@ -3,6 +3,10 @@ package repository
 import (
 	archievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/internal/report"
+	"github.com/sawalreverr/recything/internal/user"
+	"gorm.io/gorm"
 )
 
 type ManageAchievementRepositoryImpl struct {
@@ -29,6 +33,30 @@ func (repository ManageAchievementRepositoryImpl) GetAllArchievement() ([]*archi
 	return achievements, nil
 }
 
+// GetLevelAchievements lists the point achievements, highest target first.
+func (repository ManageAchievementRepositoryImpl) GetLevelAchievements() ([]*archievement.Achievement, error) {
+	var achievements []*archievement.Achievement
+	if err := repository.DB.GetDB().Scopes(archievement.PointLevels).Order("target_point desc").Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+func (repository ManageAchievementRepositoryImpl) CreateAchievement(achievement *archievement.Achievement) error {
+	if err := repository.DB.GetDB().Create(achievement).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository ManageAchievementRepositoryImpl) WasteMaterialExists(id string) (bool, error) {
+	var total int64
+	if err := repository.DB.GetDB().Model(&report.WasteMaterial{}).Where("id = ?", id).Count(&total).Error; err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
 func (repository ManageAchievementRepositoryImpl) GetAchievementById(id int) (*archievement.Achievement, error) {
 	var achievement archievement.Achievement
 	if err := repository.DB.GetDB().Where("id = ?", id).First(&achievement).Error; err != nil {
@@ -37,16 +65,137 @@ func (repository ManageAchievementRepositoryImpl) GetAchievementById(id int) (*a
 	return &achievement, nil
 }
 
+// UpdateAchievement saves every editable column, so a bonus or target of 0 is
+// kept, and refreshes the badge image of the users holding it.
 func (repository ManageAchievementRepositoryImpl) UpdateAchievement(achievement *archievement.Achievement, id int) error {
-	if err := repository.DB.GetDB().Where("id = ?", id).Updates(achievement).Error; err != nil {
+	return repository.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&archievement.Achievement{}).Where("id = ?", id).
+			Select("level", "description", "target_point", "target_count", "waste_material_id", "bonus_percent", "badge_url", "badge_url_user").
+			Updates(achievement).Error; err != nil {
+			return err
+		}
+
+		if achievement.Kind != archievement.KindPoint {
+			return nil
+		}
+		return tx.Model(&user.User{}).Where("achievement_id = ?", id).
+			Update("badge", achievement.BadgeUrlUser).Error
+	})
+}
+
+func (repository ManageAchievementRepositoryImpl) DeleteAchievement(id int) error {
+	if err := repository.DB.GetDB().Delete(&archievement.Achievement{}, id).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (repository ManageAchievementRepositoryImpl) DeleteAchievement(id int) error {
-	if err := repository.DB.GetDB().Delete(&archievement.Achievement{}, id).Error; err != nil {
+func (repository ManageAchievementRepositoryImpl) CreateLevelJob(job *archievement.LevelRecomputeJob) error {
+	if err := repository.DB.GetDB().Create(job).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+func (repository ManageAchievementRepositoryImpl) FindLevelJob(jobId uint) (*archievement.LevelRecomputeJob, error) {
+	var job archievement.LevelRecomputeJob
+	if err := repository.DB.GetDB().Where("id = ?", jobId).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (repository ManageAchievementRepositoryImpl) FindLevelJobsByAdmin(adminId string) ([]*archievement.LevelRecomputeJob, error) {
+	var jobs []*archievement.LevelRecomputeJob
+	if err := repository.DB.GetDB().Where("triggered_by = ?", adminId).Order("id desc").Limit(20).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (repository ManageAchievementRepositoryImpl) UpdateLevelJob(jobId uint, fields map[string]interface{}) error {
+	if err := repository.DB.GetDB().Model(&archievement.LevelRecomputeJob{}).Where("id = ?", jobId).Updates(fields).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository ManageAchievementRepositoryImpl) CountUsers() (int, error) {
+	var total int64
+	if err := repository.DB.GetDB().Model(&user.User{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+// FindUsersAfter pages through users by id, which stays stable while users
+// are being updated.
+func (repository ManageAchievementRepositoryImpl) FindUsersAfter(lastId string, limit int) ([]*user.User, error) {
+	var users []*user.User
+	if err := repository.DB.GetDB().Select("id", "point", "achievement_id").
+		Where("id > ?", lastId).
+		Order("id").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// EarnedPoints sums the points each of the users earned, users without any
+// are left out.
+func (repository ManageAchievementRepositoryImpl) EarnedPoints(userIds []string) (map[string]int, error) {
+	earned := make(map[string]int, len(userIds))
+	if len(userIds) == 0 {
+		return earned, nil
+	}
+
+	var rows []struct {
+		UserID string
+		Earned int
+	}
+	if err := repository.DB.GetDB().Model(&point.Entry{}).
+		Scopes(point.Earned).
+		Select("user_id, SUM(amount) AS earned").
+		Where("user_id IN ?", userIds).
+		Group("user_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		earned[row.UserID] = row.Earned
+	}
+	return earned, nil
+}
+
+// ApplyLevelChange moves a user to another achievement and records the move.
+// It only applies while the user still has the balance and achievement the
+// change was computed from; otherwise their points moved in the meantime and
+// the ledger already gave them the right achievement, so false is returned.
+func (repository ManageAchievementRepositoryImpl) ApplyLevelChange(change *archievement.UserLevelChange, badge string, balance uint) (bool, error) {
+	applied := false
+	err := repository.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		fields := map[string]interface{}{"achievement_id": change.ToAchievementID}
+		if badge != "" {
+			fields["badge"] = badge
+		}
+
+		result := tx.Model(&user.User{}).
+			Where("id = ? AND achievement_id = ? AND point = ?", change.UserID, change.FromAchievementID, balance).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		applied = true
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
@@ -8,8 +8,13 @@ import (
 )
 
 type ManageAchievementUsecase interface {
+	CreateAchievementUsecase(request *dto.CreateAchievementRequest, badge *multipart.FileHeader, badgeUser *multipart.FileHeader, adminId string) (*archievement.Achievement, *archievement.LevelRecomputeJob, error)
 	GetAllArchievementUsecase() ([]*archievement.Achievement, error)
 	GetAchievementByIdUsecase(id int) (*archievement.Achievement, error)
-	UpdateAchievementUsecase(request *dto.UpdateAchievementRequest, badge *multipart.FileHeader, id int) error
-	DeleteAchievementUsecase(id int) error
+	UpdateAchievementUsecase(request *dto.UpdateAchievementRequest, badge *multipart.FileHeader, badgeUser *multipart.FileHeader, id int, adminId string) (*archievement.LevelRecomputeJob, error)
+	DeleteAchievementUsecase(id int, adminId string) (*archievement.LevelRecomputeJob, error)
+
+	StartLevelRecomputeUsecase(adminId string, reason string) (*archievement.LevelRecomputeJob, error)
+	GetLevelJobUsecase(jobId uint, adminId string) (*archievement.LevelRecomputeJob, error)
+	GetLevelJobsUsecase(adminId string) ([]*archievement.LevelRecomputeJob, error)
 }
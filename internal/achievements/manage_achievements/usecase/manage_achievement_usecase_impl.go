@@ -1,8 +1,10 @@
 package usecase
 
 import (
+	"log"
 	"mime/multipart"
 	"strings"
+	"time"
 
 	"github.com/sawalreverr/recything/internal/achievements/manage_achievements/dto"
 	archievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
@@ -11,6 +13,9 @@ import (
 	"github.com/sawalreverr/recything/pkg"
 )
 
+// levelJobBatch is how many users a level recompute loads at a time.
+const levelJobBatch = 500
+
 type ManageAchievementUsecaseImpl struct {
 	repository repository.ManageAchievementRepository
 }
@@ -19,6 +24,69 @@ func NewManageAchievementUsecase(repository repository.ManageAchievementReposito
 	return &ManageAchievementUsecaseImpl{repository: repository}
 }
 
+// CreateAchievementUsecase adds an achievement. A new level may be within
+// reach of existing users, so creating one starts a level recompute.
+func (repository ManageAchievementUsecaseImpl) CreateAchievementUsecase(request *dto.CreateAchievementRequest, badge *multipart.FileHeader, badgeUser *multipart.FileHeader, adminId string) (*archievement.Achievement, *archievement.LevelRecomputeJob, error) {
+	if _, err := repository.repository.FindArchievementByLevel(request.Level); err == nil {
+		return nil, nil, pkg.ErrAchievementLevelAlreadyExist
+	}
+	if badge == nil || (request.Kind == archievement.KindPoint && badgeUser == nil) {
+		return nil, nil, pkg.ErrBadge
+	}
+	if request.Kind != archievement.KindPoint && request.TargetCount < 1 {
+		return nil, nil, pkg.ErrAchievementTarget
+	}
+	if request.Kind == archievement.KindWasteMaterial {
+		exists, err := repository.repository.WasteMaterialExists(request.WasteMaterialId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			return nil, nil, pkg.ErrAchievementWasteMaterial
+		}
+	}
+
+	urlBadge, err := uploadBadge(badge, "achievement_badge")
+	if err != nil {
+		return nil, nil, err
+	}
+	urlBadgeUser, err := uploadBadge(badgeUser, "user_badge")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	achievement := archievement.Achievement{
+		Level:        request.Level,
+		Kind:         request.Kind,
+		Description:  request.Description,
+		BadgeUrl:     urlBadge,
+		BadgeUrlUser: urlBadgeUser,
+	}
+	switch request.Kind {
+	case archievement.KindPoint:
+		achievement.TargetPoint = request.TargetPoint
+		achievement.BonusPercent = request.BonusPercent
+	case archievement.KindWasteMaterial:
+		achievement.TargetCount = request.TargetCount
+		achievement.WasteMaterialID = request.WasteMaterialId
+	default:
+		achievement.TargetCount = request.TargetCount
+	}
+
+	if err := repository.repository.CreateAchievement(&achievement); err != nil {
+		return nil, nil, err
+	}
+
+	if achievement.Kind != archievement.KindPoint {
+		return &achievement, nil, nil
+	}
+	job, err := repository.StartLevelRecomputeUsecase(adminId, achievement.Level+" created")
+	if err != nil {
+		return nil, nil, err
+	}
+	return &achievement, job, nil
+}
+
 func (repository ManageAchievementUsecaseImpl) GetAllArchievementUsecase() ([]*archievement.Achievement, error) {
 	achievements, err := repository.repository.GetAllArchievement()
 	if err != nil {
@@ -36,54 +104,214 @@ func (repository ManageAchievementUsecaseImpl) GetAchievementByIdUsecase(id int)
 	return achievement, nil
 }
 
-func (repository ManageAchievementUsecaseImpl) UpdateAchievementUsecase(request *dto.UpdateAchievementRequest, badge *multipart.FileHeader, id int) error {
+// UpdateAchievementUsecase starts a level recompute when the target point
+// changed, since users may now hold a different achievement.
+func (repository ManageAchievementUsecaseImpl) UpdateAchievementUsecase(request *dto.UpdateAchievementRequest, badge *multipart.FileHeader, badgeUser *multipart.FileHeader, id int, adminId string) (*archievement.LevelRecomputeJob, error) {
 	achievement, err := repository.repository.GetAchievementById(id)
 	if err != nil {
-		return pkg.ErrAchievementNotFound
+		return nil, pkg.ErrAchievementNotFound
 	}
-	var urlBadge string
-	if badge != nil {
-		if badge.Size > 2*1024*1024 {
-			return pkg.ErrFileTooLarge
-		}
-		if !strings.HasPrefix(badge.Header.Get("Content-Type"), "image") {
-			return pkg.ErrInvalidFileType
-		}
-		src, errOpen := badge.Open()
-		if errOpen != nil {
-			return pkg.ErrOpenFile
-		}
-
-		urlBadgeUpload, errUpload := helper.UploadToCloudinary(src, "achievement_badge")
-		if errUpload != nil {
-			return errUpload
-		}
-		urlBadge = urlBadgeUpload
-		defer src.Close()
+	urlBadge, err := uploadBadge(badge, "achievement_badge")
+	if err != nil {
+		return nil, err
+	}
+	urlBadgeUser, err := uploadBadge(badgeUser, "user_badge")
+	if err != nil {
+		return nil, err
 	}
+	previousTarget := achievement.TargetPoint
 
 	if request.Level != "" {
 		achievement.Level = request.Level
 	}
-	if request.TargetPoint != 0 {
+	if request.Description != "" {
+		achievement.Description = request.Description
+	}
+	if request.TargetPoint != 0 && achievement.Kind == archievement.KindPoint {
 		achievement.TargetPoint = request.TargetPoint
 	}
+	if request.TargetCount != 0 && achievement.Kind != archievement.KindPoint {
+		achievement.TargetCount = request.TargetCount
+	}
+	if request.BonusPercent != nil {
+		achievement.BonusPercent = *request.BonusPercent
+	}
 	if urlBadge != "" {
 		achievement.BadgeUrl = urlBadge
 	}
+	if urlBadgeUser != "" {
+		achievement.BadgeUrlUser = urlBadgeUser
+	}
 
+	// users holding this achievement follow its new image, their bonus is
+	// looked up by achievement id and is not affected
 	if err := repository.repository.UpdateAchievement(achievement, id); err != nil {
-		return err
+		return nil, err
+	}
+
+	if achievement.Kind != archievement.KindPoint || achievement.TargetPoint == previousTarget {
+		return nil, nil
+	}
+	return repository.StartLevelRecomputeUsecase(adminId, "target point of "+achievement.Level+" changed")
+}
+
+func uploadBadge(badge *multipart.FileHeader, folder string) (string, error) {
+	if badge == nil {
+		return "", nil
+	}
+	if badge.Size > 2*1024*1024 {
+		return "", pkg.ErrFileTooLarge
+	}
+	if !strings.HasPrefix(badge.Header.Get("Content-Type"), "image") {
+		return "", pkg.ErrInvalidFileType
 	}
-	return nil
+	src, errOpen := badge.Open()
+	if errOpen != nil {
+		return "", pkg.ErrOpenFile
+	}
+	defer src.Close()
+
+	return helper.UploadToCloudinary(src, folder)
 }
 
-func (repository ManageAchievementUsecaseImpl) DeleteAchievementUsecase(id int) error {
-	if _, err := repository.repository.GetAchievementById(id); err != nil {
-		return pkg.ErrAchievementNotFound
+func (repository ManageAchievementUsecaseImpl) DeleteAchievementUsecase(id int, adminId string) (*archievement.LevelRecomputeJob, error) {
+	achievement, err := repository.repository.GetAchievementById(id)
+	if err != nil {
+		return nil, pkg.ErrAchievementNotFound
 	}
 	if err := repository.repository.DeleteAchievement(id); err != nil {
+		return nil, err
+	}
+	if achievement.Kind != archievement.KindPoint {
+		return nil, nil
+	}
+	return repository.StartLevelRecomputeUsecase(adminId, achievement.Level+" deleted")
+}
+
+// StartLevelRecomputeUsecase queues a job and runs it in the background.
+func (repository ManageAchievementUsecaseImpl) StartLevelRecomputeUsecase(adminId string, reason string) (*archievement.LevelRecomputeJob, error) {
+	job := archievement.LevelRecomputeJob{
+		TriggeredBy: adminId,
+		Reason:      reason,
+		Status:      archievement.LevelJobQueued,
+	}
+	if err := repository.repository.CreateLevelJob(&job); err != nil {
+		return nil, err
+	}
+
+	go repository.runLevelRecompute(job.ID)
+	return &job, nil
+}
+
+// GetLevelJobUsecase only shows a job to the admin that triggered it.
+func (repository ManageAchievementUsecaseImpl) GetLevelJobUsecase(jobId uint, adminId string) (*archievement.LevelRecomputeJob, error) {
+	job, err := repository.repository.FindLevelJob(jobId)
+	if err != nil || job.TriggeredBy != adminId {
+		return nil, pkg.ErrLevelJobNotFound
+	}
+	return job, nil
+}
+
+func (repository ManageAchievementUsecaseImpl) GetLevelJobsUsecase(adminId string) ([]*archievement.LevelRecomputeJob, error) {
+	return repository.repository.FindLevelJobsByAdmin(adminId)
+}
+
+func (repository ManageAchievementUsecaseImpl) runLevelRecompute(jobId uint) {
+	startedAt := time.Now()
+	err := repository.repository.UpdateLevelJob(jobId, map[string]interface{}{
+		"status":     archievement.LevelJobRunning,
+		"started_at": startedAt,
+	})
+	if err == nil {
+		err = repository.recomputeLevels(jobId)
+	}
+
+	finishedAt := time.Now()
+	fields := map[string]interface{}{
+		"status":      archievement.LevelJobDone,
+		"finished_at": finishedAt,
+	}
+	if err != nil {
+		log.Printf("level recompute job %d failed: %v", jobId, err)
+		fields["status"] = archievement.LevelJobFailed
+		fields["error"] = err.Error()
+	}
+	if err := repository.repository.UpdateLevelJob(jobId, fields); err != nil {
+		log.Printf("finish level recompute job %d: %v", jobId, err)
+	}
+}
+
+func (repository ManageAchievementUsecaseImpl) recomputeLevels(jobId uint) error {
+	total, err := repository.repository.CountUsers()
+	if err != nil {
+		return err
+	}
+	if err := repository.repository.UpdateLevelJob(jobId, map[string]interface{}{"total_users": total}); err != nil {
+		return err
+	}
+
+	// ordered by target point, highest first
+	achievements, err := repository.repository.GetLevelAchievements()
+	if err != nil {
 		return err
 	}
-	return nil
+
+	processed, changed := 0, 0
+	lastId := ""
+	for {
+		users, err := repository.repository.FindUsersAfter(lastId, levelJobBatch)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		userIds := make([]string, len(users))
+		for i, user := range users {
+			userIds[i] = user.ID
+		}
+		// levels follow the points users earned, redeeming rewards does not
+		// lower them
+		earned, err := repository.repository.EarnedPoints(userIds)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			to, badge := 0, ""
+			for _, achievement := range achievements {
+				if earned[user.ID] >= achievement.TargetPoint {
+					to, badge = achievement.ID, achievement.BadgeUrlUser
+					break
+				}
+			}
+
+			if to != user.AchievementID {
+				applied, err := repository.repository.ApplyLevelChange(&archievement.UserLevelChange{
+					UserID:            user.ID,
+					FromAchievementID: user.AchievementID,
+					ToAchievementID:   to,
+					Point:             earned[user.ID],
+					Cause:             archievement.LevelChangeRecompute,
+					JobID:             &jobId,
+				}, badge, user.Point)
+				if err != nil {
+					return err
+				}
+				if applied {
+					changed++
+				}
+			}
+			processed++
+		}
+		lastId = users[len(users)-1].ID
+
+		if err := repository.repository.UpdateLevelJob(jobId, map[string]interface{}{
+			"processed_users": processed,
+			"changed_users":   changed,
+		}); err != nil {
+			return err
+		}
+	}
 }
@@ -2,11 +2,19 @@ package dto
 
 import "time"
 
+// DataAchievement shows how far the user is toward an achievement. Target
+// and Progress are points for levels and counts for the other kinds.
 type DataAchievement struct {
-	Id          int    `json:"id"`
-	Level       string `json:"level"`
-	TargetPoint int    `json:"target_point"`
-	BadgeUrl    string `json:"badge_url"`
+	Id          int        `json:"id"`
+	Level       string     `json:"level"`
+	Kind        string     `json:"kind"`
+	Description string     `json:"description"`
+	TargetPoint int        `json:"target_point"`
+	Target      int        `json:"target"`
+	Progress    int        `json:"progress"`
+	Unlocked    bool       `json:"unlocked"`
+	UnlockedAt  *time.Time `json:"unlocked_at"`
+	BadgeUrl    string     `json:"badge_url"`
 }
 
 type DataUser struct {
@@ -17,8 +25,11 @@ type DataUser struct {
 }
 
 type HistoryUserPoint struct {
-	Point      int       `json:"point"`
-	AcceptedAt time.Time `json:"accepted_at"`
+	Point        int       `json:"point"`
+	AcceptedAt   time.Time `json:"accepted_at"`
+	SourceType   string    `json:"source_type"`
+	Description  string    `json:"description"`
+	BalanceAfter int       `json:"balance_after"`
 }
 
 type GetAchievementByUserResponse struct {
@@ -1,13 +1,21 @@
 package repository
 
 import (
+	"time"
+
 	archievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
-	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"github.com/sawalreverr/recything/internal/point"
 	user "github.com/sawalreverr/recything/internal/user"
 )
 
 type UserAchievementRepository interface {
 	GetAvhievementsByUser() (*[]archievement.Achievement, error)
-	GetHistoryUserPoint(userId string) (*[]user_task.UserTaskChallenge, error)
+	GetHistoryUserPoint(userId string) (*[]point.Entry, error)
 	GetPoinUser(userId string) (*user.User, error)
+
+	GetUnlockedAchievements(userId string) ([]archievement.UserAchievement, error)
+	UnlockAchievements(unlocks []archievement.UserAchievement) error
+	GetApprovedReportDates(userId string, wasteMaterialId string) ([]time.Time, error)
+	GetAcceptedTaskDates(userId string) ([]time.Time, error)
+	GetPointReachedAt(userId string, target int) (*time.Time, error)
 }
@@ -1,10 +1,17 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	archievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/internal/report"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 	user "github.com/sawalreverr/recything/internal/user"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserAchievementRepositoryImpl struct {
@@ -17,22 +24,21 @@ func NewUserAchievementRepository(db database.Database) *UserAchievementReposito
 
 func (repository UserAchievementRepositoryImpl) GetAvhievementsByUser() (*[]archievement.Achievement, error) {
 	var achievements []archievement.Achievement
-	if err := repository.DB.GetDB().Find(&achievements).Error; err != nil {
+	if err := repository.DB.GetDB().Order("kind, target_point, target_count, id").Find(&achievements).Error; err != nil {
 		return nil, err
 	}
 	return &achievements, nil
 }
 
-func (repository UserAchievementRepositoryImpl) GetHistoryUserPoint(userId string) (*[]user_task.UserTaskChallenge, error) {
-	var userTasks []user_task.UserTaskChallenge
+func (repository UserAchievementRepositoryImpl) GetHistoryUserPoint(userId string) (*[]point.Entry, error) {
+	var entries []point.Entry
 	if err := repository.DB.GetDB().
 		Where("user_id = ?", userId).
-		Where("status_accept = ?", "accept").
-		Order("accepted_at desc").
-		Find(&userTasks).Error; err != nil {
+		Order("id desc").
+		Find(&entries).Error; err != nil {
 		return nil, err
 	}
-	return &userTasks, nil
+	return &entries, nil
 }
 
 func (repository UserAchievementRepositoryImpl) GetPoinUser(userId string) (*user.User, error) {
@@ -42,3 +48,69 @@ func (repository UserAchievementRepositoryImpl) GetPoinUser(userId string) (*use
 	}
 	return &user, nil
 }
+
+func (repository UserAchievementRepositoryImpl) GetUnlockedAchievements(userId string) ([]archievement.UserAchievement, error) {
+	var unlocks []archievement.UserAchievement
+	if err := repository.DB.GetDB().Where("user_id = ?", userId).Find(&unlocks).Error; err != nil {
+		return nil, err
+	}
+	return unlocks, nil
+}
+
+// UnlockAchievements stores new unlocks, ones already stored are kept as
+// they are.
+func (repository UserAchievementRepositoryImpl) UnlockAchievements(unlocks []archievement.UserAchievement) error {
+	if len(unlocks) == 0 {
+		return nil
+	}
+	if err := repository.DB.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&unlocks).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetApprovedReportDates returns when the user's approved or resolved reports
+// were made, oldest first, optionally only reports of one waste material.
+func (repository UserAchievementRepositoryImpl) GetApprovedReportDates(userId string, wasteMaterialId string) ([]time.Time, error) {
+	db := repository.DB.GetDB().Model(&report.Report{}).
+		Where("reports.author_id = ? AND reports.status IN ?", userId, []string{"approve", "resolve"})
+	if wasteMaterialId != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM report_waste_materials WHERE report_waste_materials.report_id = reports.id AND report_waste_materials.waste_material_id = ? AND report_waste_materials.deleted_at IS NULL)", wasteMaterialId)
+	}
+
+	var dates []time.Time
+	if err := db.Order("reports.created_at").Pluck("reports.created_at", &dates).Error; err != nil {
+		return nil, err
+	}
+	return dates, nil
+}
+
+// GetAcceptedTaskDates returns when the user's challenges were accepted,
+// oldest first.
+func (repository UserAchievementRepositoryImpl) GetAcceptedTaskDates(userId string) ([]time.Time, error) {
+	var dates []time.Time
+	if err := repository.DB.GetDB().Model(&user_task.UserTaskChallenge{}).
+		Where("user_id = ? AND status_accept = ?", userId, "accept").
+		Order("accepted_at").
+		Pluck("accepted_at", &dates).Error; err != nil {
+		return nil, err
+	}
+	return dates, nil
+}
+
+// GetPointReachedAt returns when the user's balance first reached target, or
+// nil when it never did.
+func (repository UserAchievementRepositoryImpl) GetPointReachedAt(userId string, target int) (*time.Time, error) {
+	var entry point.Entry
+	err := repository.DB.GetDB().
+		Where("user_id = ? AND balance_after >= ?", userId, target).
+		Order("id").
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry.CreatedAt, nil
+}
@@ -1,8 +1,13 @@
 package usecase
 
 import (
+	"sort"
+	"time"
+
+	archievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/achievements/user_achievements/dto"
 	"github.com/sawalreverr/recything/internal/achievements/user_achievements/repository"
+	"github.com/sawalreverr/recything/internal/user"
 	"github.com/sawalreverr/recything/pkg"
 	"gorm.io/gorm"
 )
@@ -49,18 +54,17 @@ func (usecase *UserAchievementUsecaseImpl) GetAvhievementsByUser(userId string)
 		HistoryUserPoint: dataHistoryUserPoint,
 	}
 
-	for _, v := range *achievements {
-		dataachievement = append(dataachievement, &dto.DataAchievement{
-			Id:          v.ID,
-			Level:       v.Level,
-			TargetPoint: v.TargetPoint,
-			BadgeUrl:    v.BadgeUrl,
-		})
+	dataachievement, err = usecase.evaluateAchievements(dataUser, *achievements, time.Now())
+	if err != nil {
+		return nil, err
 	}
 	for _, v := range *historyUserPoint {
 		dataHistoryUserPoint = append(dataHistoryUserPoint, &dto.HistoryUserPoint{
-			Point:      v.Point,
-			AcceptedAt: v.AcceptedAt,
+			Point:        v.Amount,
+			AcceptedAt:   v.CreatedAt,
+			SourceType:   v.SourceType,
+			Description:  v.Description,
+			BalanceAfter: v.BalanceAfter,
 		})
 	}
 	data.DataAchievement = dataachievement
@@ -68,3 +72,169 @@ func (usecase *UserAchievementUsecaseImpl) GetAvhievementsByUser(userId string)
 	return &data, nil
 
 }
+
+// evaluateAchievements works out the user's progress toward every
+// achievement and stores the ones reached for the first time. Unlock dates
+// come from the activity that reached the target, so they do not depend on
+// when the user happens to look.
+func (usecase *UserAchievementUsecaseImpl) evaluateAchievements(user *user.User, achievements []archievement.Achievement, now time.Time) ([]*dto.DataAchievement, error) {
+	unlocks, err := usecase.userAchievementRepository.GetUnlockedAchievements(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	unlockedAt := make(map[int]time.Time, len(unlocks))
+	for _, unlock := range unlocks {
+		unlockedAt[unlock.AchievementID] = unlock.UnlockedAt
+	}
+
+	// loaded once and shared by every achievement that needs them
+	var reports, tasks []time.Time
+	var loaded bool
+	activity := func() ([]time.Time, []time.Time, error) {
+		if loaded {
+			return reports, tasks, nil
+		}
+		var err error
+		if reports, err = usecase.userAchievementRepository.GetApprovedReportDates(user.ID, ""); err != nil {
+			return nil, nil, err
+		}
+		if tasks, err = usecase.userAchievementRepository.GetAcceptedTaskDates(user.ID); err != nil {
+			return nil, nil, err
+		}
+		loaded = true
+		return reports, tasks, nil
+	}
+
+	var data []*dto.DataAchievement
+	var newUnlocks []archievement.UserAchievement
+	for _, achievement := range achievements {
+		target := achievement.Target()
+		progress := 0
+		var reachedAt *time.Time
+
+		switch achievement.Kind {
+		case archievement.KindPoint:
+			progress = int(user.Point)
+			if _, ok := unlockedAt[achievement.ID]; !ok {
+				if reachedAt, err = usecase.userAchievementRepository.GetPointReachedAt(user.ID, target); err != nil {
+					return nil, err
+				}
+				if reachedAt == nil && progress >= target {
+					reachedAt = &now
+				}
+			}
+		case archievement.KindReportCount, archievement.KindChallengeCount:
+			reports, tasks, err := activity()
+			if err != nil {
+				return nil, err
+			}
+			dates := reports
+			if achievement.Kind == archievement.KindChallengeCount {
+				dates = tasks
+			}
+			progress = len(dates)
+			reachedAt = nthDate(dates, target)
+		case archievement.KindWasteMaterial:
+			dates, err := usecase.userAchievementRepository.GetApprovedReportDates(user.ID, achievement.WasteMaterialID)
+			if err != nil {
+				return nil, err
+			}
+			progress = len(dates)
+			reachedAt = nthDate(dates, target)
+		case archievement.KindWeeklyStreak:
+			reports, tasks, err := activity()
+			if err != nil {
+				return nil, err
+			}
+			progress, reachedAt = weeklyStreak(append(append([]time.Time{}, reports...), tasks...), target, now)
+		}
+
+		item := &dto.DataAchievement{
+			Id:          achievement.ID,
+			Level:       achievement.Level,
+			Kind:        achievement.Kind,
+			Description: achievement.Description,
+			TargetPoint: achievement.TargetPoint,
+			Target:      target,
+			Progress:    min(progress, target),
+			BadgeUrl:    achievement.BadgeUrl,
+		}
+		if at, ok := unlockedAt[achievement.ID]; ok {
+			item.Unlocked, item.UnlockedAt = true, &at
+		} else if reachedAt != nil {
+			item.Unlocked, item.UnlockedAt = true, reachedAt
+			newUnlocks = append(newUnlocks, archievement.UserAchievement{
+				UserID:        user.ID,
+				AchievementID: achievement.ID,
+				UnlockedAt:    *reachedAt,
+			})
+		}
+		data = append(data, item)
+	}
+
+	if err := usecase.userAchievementRepository.UnlockAchievements(newUnlocks); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// nthDate returns the date the count reached n, or nil when it has not.
+func nthDate(dates []time.Time, n int) *time.Time {
+	if n < 1 || len(dates) < n {
+		return nil
+	}
+	return &dates[n-1]
+}
+
+// weeklyStreak counts the weeks in a row, Monday to Sunday, with any activity
+// up to the current week. The current week still counts toward the streak
+// while it has no activity yet. It also returns when a run of target weeks
+// was first completed, or nil if none ever was.
+func weeklyStreak(dates []time.Time, target int, now time.Time) (int, *time.Time) {
+	first := make(map[time.Time]time.Time)
+	for _, date := range dates {
+		week := weekStart(date)
+		if earliest, ok := first[week]; !ok || date.Before(earliest) {
+			first[week] = date
+		}
+	}
+
+	weeks := make([]time.Time, 0, len(first))
+	for week := range first {
+		weeks = append(weeks, week)
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+
+	var reachedAt *time.Time
+	run := 0
+	for i, week := range weeks {
+		if i > 0 && weeks[i-1].AddDate(0, 0, 7).Equal(week) {
+			run++
+		} else {
+			run = 1
+		}
+		if run == target && reachedAt == nil {
+			at := first[week]
+			reachedAt = &at
+		}
+	}
+
+	current := 0
+	thisWeek := weekStart(now)
+	if len(weeks) > 0 {
+		last := weeks[len(weeks)-1]
+		if last.Equal(thisWeek) || last.AddDate(0, 0, 7).Equal(thisWeek) {
+			current = 1
+			for i := len(weeks) - 1; i > 0 && weeks[i-1].AddDate(0, 0, 7).Equal(weeks[i]); i-- {
+				current++
+			}
+		}
+	}
+	return current, reachedAt
+}
+
+func weekStart(t time.Time) time.Time {
+	t = t.In(time.Local)
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.Local)
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type Admin struct {
-	ID        string `gorm:"primaryKey"`
+	ID        string `gorm:"primaryKey;type:varchar(20)"`
 	Name      string
 	Email     string
 	Password  string
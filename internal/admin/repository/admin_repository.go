@@ -7,7 +7,8 @@ type AdminRepository interface {
 	UpdateDataAdmin(admin *entity.Admin, id string) (*entity.Admin, error)
 	FindAdminByEmail(email string) (*entity.Admin, error)
 	FindAdminByID(id string) (*entity.Admin, error)
+	FindAdminsByIDs(ids []string) ([]entity.Admin, error)
 	GetDataAllAdmin(limit int, offset int) ([]entity.Admin, int, error)
-	FindLastIdAdmin() (string, error)
+	NextIdAdmin() (string, error)
 	DeleteAdmin(id string) error
 }
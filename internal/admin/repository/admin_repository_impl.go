@@ -43,6 +43,18 @@ func (repository *AdminRepositoryImpl) FindAdminByID(id string) (*entity.Admin,
 	return &admin, nil
 }
 
+func (repository *AdminRepositoryImpl) FindAdminsByIDs(ids []string) ([]entity.Admin, error) {
+	var admins []entity.Admin
+	if len(ids) == 0 {
+		return admins, nil
+	}
+
+	if err := repository.DB.GetDB().Where("id IN ?", ids).Find(&admins).Error; err != nil {
+		return nil, err
+	}
+	return admins, nil
+}
+
 func (repository *AdminRepositoryImpl) GetDataAllAdmin(limit int, offset int) ([]entity.Admin, int, error) {
 	var admins []entity.Admin
 	var count int64
@@ -59,12 +71,8 @@ func (repository *AdminRepositoryImpl) GetDataAllAdmin(limit int, offset int) ([
 	return admins, int(count), nil
 }
 
-func (repository *AdminRepositoryImpl) FindLastIdAdmin() (string, error) {
-	var admin entity.Admin
-	if err := repository.DB.GetDB().Unscoped().Order("id desc").First(&admin).Error; err != nil {
-		return "AD0000", err
-	}
-	return admin.ID, nil
+func (repository *AdminRepositoryImpl) NextIdAdmin() (string, error) {
+	return database.NextID(repository.DB, "AD")
 }
 
 func (repository *AdminRepositoryImpl) DeleteAdmin(id string) error {
@@ -33,8 +33,10 @@ func (usecase *AdminUsecaseImpl) AddAdminUsecase(request dto.AdminRequestCreate,
 		return nil, pkg.ErrUploadCloudinary
 	}
 
-	findLastId, _ := usecase.Repository.FindLastIdAdmin()
-	id := helper.GenerateCustomID(findLastId, "AD")
+	id, err := usecase.Repository.NextIdAdmin()
+	if err != nil {
+		return nil, err
+	}
 
 	hashPassword, _ := helper.GenerateHash(request.Password)
 
@@ -13,6 +13,13 @@ type ArticleInput struct {
 	Sections          []ArticleSection `json:"sections"`
 }
 
+// ArticleStatusInput moves an article through the editorial workflow.
+// PublishAt (RFC3339) is required when scheduling.
+type ArticleStatusInput struct {
+	Status    string `json:"status" validate:"required,oneof=draft in_review scheduled published archived"`
+	PublishAt string `json:"publish_at"`
+}
+
 type ArticleSectionInput struct {
 	ArticleID   string `json:"article_id"`
 	Title       string `json:"title"`
@@ -38,6 +45,9 @@ type ArticleDetail struct {
 	Title        string      `json:"title"`
 	Description  string      `json:"description"`
 	ThumbnailURL string      `json:"thumbnail_url"`
+	Status       string      `json:"status"`
+	PublishAt    *time.Time  `json:"publish_at"`
+	PublishedAt  *time.Time  `json:"published_at"`
 	CreatedAt    time.Time   `json:"created_at"`
 
 	WasteCategories   []WasteCategory   `json:"waste_categories"`
@@ -7,12 +7,37 @@ import (
 	"gorm.io/gorm"
 )
 
+// article statuses, only published articles are shown to users
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// statusTransitions lists the statuses an article may move to from each
+// status.
+var statusTransitions = map[string][]string{
+	StatusDraft:     {StatusInReview, StatusScheduled, StatusPublished, StatusArchived},
+	StatusInReview:  {StatusDraft, StatusScheduled, StatusPublished, StatusArchived},
+	StatusScheduled: {StatusDraft, StatusInReview, StatusPublished, StatusArchived},
+	StatusPublished: {StatusArchived},
+	StatusArchived:  {StatusDraft, StatusPublished},
+}
+
 type Article struct {
-	ID           string `gorm:"primaryKey;type:varchar(7)"`
+	ID           string `gorm:"primaryKey;type:varchar(20)"`
 	Title        string `gorm:"type:varchar(255)"`
 	Description  string `gorm:"type:text"`
 	ThumbnailURL string `gorm:"type:varchar(255)"`
-	AuthorID     string
+	AuthorID     string `gorm:"type:varchar(20)"`
+
+	Status string `gorm:"type:enum('draft', 'in_review', 'scheduled', 'published', 'archived');default:'draft';index"`
+	// PublishAt is when the scheduler publishes a scheduled article,
+	// PublishedAt when the article actually went live.
+	PublishAt   *time.Time `gorm:"index"`
+	PublishedAt *time.Time
 
 	Categories []ArticleCategories
 	Sections   []ArticleSection
@@ -23,6 +48,16 @@ type Article struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// CanMoveTo reports whether the article may change to status.
+func (a *Article) CanMoveTo(status string) bool {
+	for _, next := range statusTransitions[a.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
 type WasteCategory struct {
 	ID   uint   `json:"id" gorm:"primaryKey"`
 	Name string `json:"name" gorm:"type:varchar(50);unique;not null"`
@@ -52,7 +87,7 @@ type ContentCategory struct {
 
 type ArticleCategories struct {
 	ID                uint   `gorm:"primaryKey"`
-	ArticleID         string `gorm:"type:varchar(7)"`
+	ArticleID         string `gorm:"type:varchar(20)"`
 	WasteCategoryID   uint
 	ContentCategoryID int
 
@@ -63,7 +98,7 @@ type ArticleCategories struct {
 
 type ArticleSection struct {
 	ID          uint   `json:"-" gorm:"primaryKey"`
-	ArticleID   string `json:"-" gorm:"type:varchar(7)"`
+	ArticleID   string `json:"-" gorm:"type:varchar(20)"`
 	Title       string `json:"title" gorm:"type:varchar(255)"`
 	Description string `json:"description" gorm:"type:text"`
 	ImageURL    string `json:"image_url" gorm:"type:varchar(255)"`
@@ -75,8 +110,8 @@ type ArticleSection struct {
 
 type ArticleComment struct {
 	ID        uint   `json:"-" gorm:"primaryKey"`
-	UserID    string `json:"-"`
-	ArticleID string `json:"-"`
+	UserID    string `json:"-" gorm:"type:varchar(20)"`
+	ArticleID string `json:"-" gorm:"type:varchar(20)"`
 	Comment   string `json:"comment" gorm:"type:text"`
 
 	CreatedAt time.Time      `json:"-"`
@@ -85,18 +120,23 @@ type ArticleComment struct {
 }
 
 type ArticleRepository interface {
+	Transaction(fn func(repo ArticleRepository) error) error
+
 	// Article Repository
 	Create(article Article) (*Article, error)
 	FindByID(articleID string) (*Article, error)
-	FindAll(page, limit uint, sortBy string, sortType string) (*[]Article, int64, error)
-	FindLastID() (string, error)
-	FindByKeyword(keyword string) (*[]Article, error)
-	FindByCategory(categoryName string, categoryType string) (*[]Article, error)
+	FindAll(page, limit uint, sortBy string, sortType string, status string) (*[]Article, int64, error)
+	NextID() (string, error)
+	FindByKeyword(keyword string, status string) (*[]Article, error)
+	FindByCategory(categoryName string, categoryType string, status string) (*[]Article, error)
 	Update(article Article) error
+	UpdateStatus(articleID string, status string, publishAt *time.Time, publishedAt *time.Time) error
+	PublishDue(now time.Time) (int64, error)
 	Delete(articleID string) error
 
 	// Category Repository
 	FindCategories(articleID string) (*[]WasteCategory, *[]ContentCategory, error)
+	FindCategoriesByArticleIDs(articleIDs []string) (map[string][]WasteCategory, map[string][]ContentCategory, error)
 	FindCategoryByName(categoryName, categoryType string) (uint, error)
 
 	// Article Section Repository
@@ -121,14 +161,17 @@ type ArticleRepository interface {
 type ArticleUsecase interface {
 	// Article Usecase
 	NewArticle(article ArticleInput, authorId string) (*ArticleDetail, error)
-	GetArticleByID(articleID string) (*ArticleDetail, error)
-	GetAllArticle(page, limit int, sortBy string, sortType string) (*ArticleResponsePagination, error)
-	GetArticleByKeyword(keyword string) (*[]ArticleDetail, error)
-	GetArticleByCategory(categoryName string, categoryType string) (*[]ArticleDetail, error)
+	GetArticleByID(articleID string, status string) (*ArticleDetail, error)
+	GetAllArticle(page, limit int, sortBy string, sortType string, status string) (*ArticleResponsePagination, error)
+	GetArticleByKeyword(keyword string, status string) (*[]ArticleDetail, error)
+	GetArticleByCategory(categoryName string, categoryType string, status string) (*[]ArticleDetail, error)
 	Update(articleID string, article ArticleInput) error
+	ChangeStatus(articleID string, input ArticleStatusInput) (*ArticleDetail, error)
+	PublishScheduled(now time.Time) (int64, error)
 	Delete(articleID string) error
 
 	GetArticleDetail(article Article) *ArticleDetail
+	GetArticleDetails(articles []Article) []ArticleDetail
 	GetDetailAuthor(authorID string) (*AdminDetail, error)
 
 	// Article Comment Usecase
@@ -144,6 +187,7 @@ type ArticleUsecase interface {
 type ArticleHandler interface {
 	NewArticle(c echo.Context) error
 	UpdateArticle(c echo.Context) error
+	ChangeArticleStatus(c echo.Context) error
 	DeleteArticle(c echo.Context) error
 	GetAllArticle(c echo.Context) error
 	GetArticleByKeyword(c echo.Context) error
@@ -69,6 +69,34 @@ func (h *articleHandler) UpdateArticle(c echo.Context) error {
 	return helper.ResponseHandler(c, http.StatusOK, "article updated!", nil)
 }
 
+func (h *articleHandler) ChangeArticleStatus(c echo.Context) error {
+	var request art.ArticleStatusInput
+	articleID := c.Param("articleId")
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	response, err := h.usecase.ChangeStatus(articleID, request)
+	if err != nil {
+		if errors.Is(err, pkg.ErrArticleNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, pkg.ErrArticleStatus) {
+			return helper.ErrorHandler(c, http.StatusConflict, err.Error())
+		} else if errors.Is(err, pkg.ErrArticlePublishAt) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "article status updated!", response)
+}
+
 func (h *articleHandler) DeleteArticle(c echo.Context) error {
 	articleID := c.Param("articleId")
 
@@ -106,7 +134,7 @@ func (h *articleHandler) GetAllArticle(c echo.Context) error {
 		sortType = "asc"
 	}
 
-	response, err := h.usecase.GetAllArticle(page, limit, sortBy, sortType)
+	response, err := h.usecase.GetAllArticle(page, limit, sortBy, sortType, visibleStatus(c))
 	if err != nil {
 		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
 	}
@@ -117,7 +145,7 @@ func (h *articleHandler) GetAllArticle(c echo.Context) error {
 func (h *articleHandler) GetArticleByKeyword(c echo.Context) error {
 	keyword := c.QueryParam("keyword")
 
-	response, err := h.usecase.GetArticleByKeyword(keyword)
+	response, err := h.usecase.GetArticleByKeyword(keyword, visibleStatus(c))
 	if err != nil {
 		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
 	}
@@ -129,7 +157,7 @@ func (h *articleHandler) GetArticleByCategory(c echo.Context) error {
 	categoryType := c.QueryParam("type")
 	categoryName := c.QueryParam("name")
 
-	response, err := h.usecase.GetArticleByCategory(categoryName, categoryType)
+	response, err := h.usecase.GetArticleByCategory(categoryName, categoryType, visibleStatus(c))
 	if err != nil {
 		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
 	}
@@ -140,7 +168,7 @@ func (h *articleHandler) GetArticleByCategory(c echo.Context) error {
 func (h *articleHandler) GetArticleByID(c echo.Context) error {
 	articleId := c.Param("articleId")
 
-	articleFound, err := h.usecase.GetArticleByID(articleId)
+	articleFound, err := h.usecase.GetArticleByID(articleId, visibleStatus(c))
 	if err != nil {
 		if errors.Is(pkg.ErrArticleNotFound, err) {
 			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
@@ -218,3 +246,13 @@ func (h *articleHandler) GetAllCategories(c echo.Context) error {
 
 	return helper.ResponseHandler(c, http.StatusOK, "ok", response)
 }
+
+// visibleStatus limits users to published articles. Admins see every status,
+// or only the one asked for with the status query param.
+func visibleStatus(c echo.Context) string {
+	if c.Get("user").(*helper.JwtCustomClaims).Role == "user" {
+		return art.StatusPublished
+	}
+
+	return c.QueryParam("status")
+}
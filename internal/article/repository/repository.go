@@ -3,6 +3,7 @@ package article
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	art "github.com/sawalreverr/recything/internal/article"
 	"github.com/sawalreverr/recything/internal/database"
@@ -18,6 +19,12 @@ func NewArticleRepository(db database.Database) art.ArticleRepository {
 	return &articleRepository{DB: db}
 }
 
+func (r *articleRepository) Transaction(fn func(repo art.ArticleRepository) error) error {
+	return r.DB.Transaction(func(tx database.Database) error {
+		return fn(&articleRepository{DB: tx})
+	})
+}
+
 func (r *articleRepository) Create(article art.Article) (*art.Article, error) {
 	if err := r.DB.GetDB().Create(&article).Error; err != nil {
 		return nil, err
@@ -37,11 +44,13 @@ func (r *articleRepository) FindByID(articleID string) (*art.Article, error) {
 	return &article, nil
 }
 
-func (r *articleRepository) FindAll(page, limit uint, sortBy string, sortType string) (*[]art.Article, int64, error) {
+// FindAll lists articles of one status, or of every status when status is
+// empty. The same holds for FindByKeyword and FindByCategory.
+func (r *articleRepository) FindAll(page, limit uint, sortBy string, sortType string, status string) (*[]art.Article, int64, error) {
 	var articles []art.Article
 	var total int64
 
-	db := r.DB.GetDB().Model(&art.Article{})
+	db := r.DB.GetDB().Model(&art.Article{}).Scopes(withStatus(status))
 
 	offset := (page - 1) * limit
 
@@ -59,20 +68,16 @@ func (r *articleRepository) FindAll(page, limit uint, sortBy string, sortType st
 	return &articles, total, nil
 }
 
-func (r *articleRepository) FindLastID() (string, error) {
-	var article art.Article
-	if err := r.DB.GetDB().Unscoped().Order("id DESC").First(&article).Error; err != nil {
-		return "ART0000", err
-	}
-
-	return article.ID, nil
+func (r *articleRepository) NextID() (string, error) {
+	return database.NextID(r.DB, "ART")
 }
 
-func (r *articleRepository) FindByKeyword(keyword string) (*[]art.Article, error) {
+func (r *articleRepository) FindByKeyword(keyword string, status string) (*[]art.Article, error) {
 	var articles []art.Article
 	query := "%" + keyword + "%"
 
 	if err := r.DB.GetDB().
+		Scopes(withStatus(status)).
 		Preload("Categories").
 		Joins("LEFT JOIN article_categories ON articles.id = article_categories.article_id").
 		Joins("LEFT JOIN waste_categories ON article_categories.waste_category_id = waste_categories.id").
@@ -85,11 +90,11 @@ func (r *articleRepository) FindByKeyword(keyword string) (*[]art.Article, error
 	return &articles, nil
 }
 
-func (r *articleRepository) FindByCategory(categoryName string, categoryType string) (*[]art.Article, error) {
+func (r *articleRepository) FindByCategory(categoryName string, categoryType string, status string) (*[]art.Article, error) {
 	var articles []art.Article
 
 	if categoryType == "waste" {
-		if err := r.DB.GetDB().Preload("Categories").
+		if err := r.DB.GetDB().Scopes(withStatus(status)).Preload("Categories").
 			Joins("JOIN article_categories ON articles.id = article_categories.article_id").
 			Joins("JOIN waste_categories ON article_categories.waste_category_id = waste_categories.id").
 			Where("waste_categories.name = ?", categoryName).
@@ -97,7 +102,7 @@ func (r *articleRepository) FindByCategory(categoryName string, categoryType str
 			return nil, err
 		}
 	} else if categoryType == "content" {
-		if err := r.DB.GetDB().Preload("Categories").
+		if err := r.DB.GetDB().Scopes(withStatus(status)).Preload("Categories").
 			Joins("JOIN article_categories ON articles.id = article_categories.article_id").
 			Joins("JOIN content_categories ON article_categories.content_category_id = content_categories.id").
 			Where("content_categories.name = ?", categoryName).
@@ -118,6 +123,32 @@ func (r *articleRepository) Update(article art.Article) error {
 	return nil
 }
 
+func (r *articleRepository) UpdateStatus(articleID string, status string, publishAt *time.Time, publishedAt *time.Time) error {
+	if err := r.DB.GetDB().Model(&art.Article{}).Where("id = ?", articleID).Updates(map[string]interface{}{
+		"status":       status,
+		"publish_at":   publishAt,
+		"published_at": publishedAt,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// PublishDue publishes every scheduled article whose publish time has come and
+// returns how many it published.
+func (r *articleRepository) PublishDue(now time.Time) (int64, error) {
+	result := r.DB.GetDB().Model(&art.Article{}).
+		Where("status = ? AND publish_at <= ?", art.StatusScheduled, now).
+		Updates(map[string]interface{}{
+			"status":       art.StatusPublished,
+			"published_at": gorm.Expr("publish_at"),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
 func (r *articleRepository) Delete(articleID string) error {
 	var article art.Article
 	if err := r.DB.GetDB().Delete(&article, "id = ?", articleID).Error; err != nil {
@@ -127,14 +158,29 @@ func (r *articleRepository) Delete(articleID string) error {
 }
 
 func (r *articleRepository) FindCategories(articleID string) (*[]art.WasteCategory, *[]art.ContentCategory, error) {
+	wasteByArticle, contentByArticle, err := r.FindCategoriesByArticleIDs([]string{articleID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wasteCategories := wasteByArticle[articleID]
+	contentCategories := contentByArticle[articleID]
+	return &wasteCategories, &contentCategories, nil
+}
+
+func (r *articleRepository) FindCategoriesByArticleIDs(articleIDs []string) (map[string][]art.WasteCategory, map[string][]art.ContentCategory, error) {
 	var articleCategories []art.ArticleCategories
 	var wasteCategories []art.WasteCategory
 	var contentCategories []art.ContentCategory
 
-	if err := r.DB.GetDB().Where("article_id = ?", articleID).Find(&articleCategories).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil, pkg.ErrArticleNotFound
-		}
+	wasteByArticle := make(map[string][]art.WasteCategory)
+	contentByArticle := make(map[string][]art.ContentCategory)
+
+	if len(articleIDs) == 0 {
+		return wasteByArticle, contentByArticle, nil
+	}
+
+	if err := r.DB.GetDB().Where("article_id IN ?", articleIDs).Find(&articleCategories).Error; err != nil {
 		return nil, nil, err
 	}
 
@@ -161,7 +207,26 @@ func (r *articleRepository) FindCategories(articleID string) (*[]art.WasteCatego
 		}
 	}
 
-	return &wasteCategories, &contentCategories, nil
+	wasteByID := make(map[uint]art.WasteCategory, len(wasteCategories))
+	for _, category := range wasteCategories {
+		wasteByID[category.ID] = category
+	}
+
+	contentByID := make(map[uint]art.ContentCategory, len(contentCategories))
+	for _, category := range contentCategories {
+		contentByID[category.ID] = category
+	}
+
+	for _, ac := range articleCategories {
+		if category, ok := wasteByID[ac.WasteCategoryID]; ok {
+			wasteByArticle[ac.ArticleID] = append(wasteByArticle[ac.ArticleID], category)
+		}
+		if category, ok := contentByID[uint(ac.ContentCategoryID)]; ok {
+			contentByArticle[ac.ArticleID] = append(contentByArticle[ac.ArticleID], category)
+		}
+	}
+
+	return wasteByArticle, contentByArticle, nil
 }
 
 func (r *articleRepository) CreateSection(section art.ArticleSection) error {
@@ -266,3 +331,12 @@ func (r *articleRepository) FindAllCategories() (*[]art.WasteCategory, *[]art.Co
 
 	return &wasteCategories, &contentCategories, nil
 }
+
+func withStatus(status string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if status == "" {
+			return db
+		}
+		return db.Where("articles.status = ?", status)
+	}
+}
@@ -5,7 +5,6 @@ import (
 
 	admin "github.com/sawalreverr/recything/internal/admin/repository"
 	art "github.com/sawalreverr/recything/internal/article"
-	"github.com/sawalreverr/recything/internal/helper"
 	user "github.com/sawalreverr/recything/internal/user"
 	"github.com/sawalreverr/recything/pkg"
 )
@@ -21,82 +20,104 @@ func NewArticleUsecase(articleRepo art.ArticleRepository, adminRepo admin.AdminR
 }
 
 func (u *articleUsecase) NewArticle(article art.ArticleInput, authorId string) (*art.ArticleDetail, error) {
-	lastID, _ := u.articleRepo.FindLastID()
-	newID := helper.GenerateCustomID(lastID, "ART")
-
-	newArticle := art.Article{
-		ID:           newID,
-		Title:        article.Title,
-		Description:  article.Description,
-		ThumbnailURL: article.ThumbnailURL,
-		AuthorID:     authorId,
-	}
-
 	wasteIDs, contentIDs, err := u.CategoryValidation(article.WasteCategories, article.ContentCategories)
 	if err != nil {
 		return nil, err
 	}
 
-	createdArticle, err := u.articleRepo.Create(newArticle)
+	var createdArticle *art.Article
+	err = u.articleRepo.Transaction(func(repo art.ArticleRepository) error {
+		newID, err := repo.NextID()
+		if err != nil {
+			return err
+		}
+
+		newArticle := art.Article{
+			ID:           newID,
+			Title:        article.Title,
+			Description:  article.Description,
+			ThumbnailURL: article.ThumbnailURL,
+			AuthorID:     authorId,
+			Status:       art.StatusDraft,
+		}
+
+		created, err := repo.Create(newArticle)
+		if err != nil {
+			return err
+		}
+
+		if err := saveArticleRelations(repo, created.ID, article.Sections, wasteIDs, contentIDs); err != nil {
+			return err
+		}
+
+		createdArticle = created
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	for i := range article.WasteCategories {
+	articleFound, _ := u.articleRepo.FindByID(createdArticle.ID)
+	return u.GetArticleDetail(*articleFound), nil
+}
+
+// saveArticleRelations inserts the category links and sections of an article
+// using repo, which is expected to be bound to the caller's transaction.
+func saveArticleRelations(repo art.ArticleRepository, articleID string, sections []art.ArticleSection, wasteIDs []uint, contentIDs []uint) error {
+	for _, wasteID := range wasteIDs {
 		articleCategory := art.ArticleCategories{
-			ArticleID:       createdArticle.ID,
-			WasteCategoryID: wasteIDs[i],
+			ArticleID:       articleID,
+			WasteCategoryID: wasteID,
 		}
 
-		if err := u.articleRepo.CreateArticleCategory(articleCategory); err != nil {
-			_ = u.articleRepo.Delete(createdArticle.ID)
-			return nil, err
+		if err := repo.CreateArticleCategory(articleCategory); err != nil {
+			return err
 		}
 	}
 
-	for i := range article.ContentCategories {
+	for _, contentID := range contentIDs {
 		articleCategory := art.ArticleCategories{
-			ArticleID:         createdArticle.ID,
-			ContentCategoryID: int(contentIDs[i]),
+			ArticleID:         articleID,
+			ContentCategoryID: int(contentID),
 		}
 
-		if err := u.articleRepo.CreateArticleCategory(articleCategory); err != nil {
-			_ = u.articleRepo.Delete(createdArticle.ID)
-			return nil, err
+		if err := repo.CreateArticleCategory(articleCategory); err != nil {
+			return err
 		}
 	}
 
-	for _, section := range article.Sections {
-		section.ArticleID = createdArticle.ID
-		if err := u.articleRepo.CreateSection(section); err != nil {
-			_ = u.articleRepo.Delete(createdArticle.ID)
-			return nil, err
+	for _, section := range sections {
+		section.ArticleID = articleID
+		if err := repo.CreateSection(section); err != nil {
+			return err
 		}
 	}
 
-	articleFound, _ := u.articleRepo.FindByID(createdArticle.ID)
-	return u.GetArticleDetail(*articleFound), nil
+	return nil
 }
 
-func (uc *articleUsecase) GetArticleByID(articleID string) (*art.ArticleDetail, error) {
+// GetArticleByID finds an article in status, or in any status when status is
+// empty. The list and search usecases filter the same way.
+func (uc *articleUsecase) GetArticleByID(articleID string, status string) (*art.ArticleDetail, error) {
 	articleFound, err := uc.articleRepo.FindByID(articleID)
 	if err != nil {
 		return nil, err
 	}
 
+	if status != "" && articleFound.Status != status {
+		return nil, pkg.ErrArticleNotFound
+	}
+
 	return uc.GetArticleDetail(*articleFound), nil
 }
 
-func (u *articleUsecase) GetAllArticle(page, limit int, sortBy string, sortType string) (*art.ArticleResponsePagination, error) {
-	articles, total, err := u.articleRepo.FindAll(uint(page), uint(limit), sortBy, sortType)
+func (u *articleUsecase) GetAllArticle(page, limit int, sortBy string, sortType string, status string) (*art.ArticleResponsePagination, error) {
+	articles, total, err := u.articleRepo.FindAll(uint(page), uint(limit), sortBy, sortType, status)
 	if err != nil {
 		return nil, err
 	}
 
-	articleDetails := make([]art.ArticleDetail, len(*articles))
-	for i, article := range *articles {
-		articleDetails[i] = *u.GetArticleDetail(article)
-	}
+	articleDetails := u.GetArticleDetails(*articles)
 
 	return &art.ArticleResponsePagination{
 		Total:    total,
@@ -106,30 +127,24 @@ func (u *articleUsecase) GetAllArticle(page, limit int, sortBy string, sortType
 	}, nil
 }
 
-func (u *articleUsecase) GetArticleByKeyword(keyword string) (*[]art.ArticleDetail, error) {
-	articles, err := u.articleRepo.FindByKeyword(keyword)
+func (u *articleUsecase) GetArticleByKeyword(keyword string, status string) (*[]art.ArticleDetail, error) {
+	articles, err := u.articleRepo.FindByKeyword(keyword, status)
 	if err != nil {
 		return nil, err
 	}
 
-	articleDetails := make([]art.ArticleDetail, len(*articles))
-	for i, article := range *articles {
-		articleDetails[i] = *u.GetArticleDetail(article)
-	}
+	articleDetails := u.GetArticleDetails(*articles)
 
 	return &articleDetails, nil
 }
 
-func (u *articleUsecase) GetArticleByCategory(categoryName string, categoryType string) (*[]art.ArticleDetail, error) {
-	articles, err := u.articleRepo.FindByCategory(categoryName, categoryType)
+func (u *articleUsecase) GetArticleByCategory(categoryName string, categoryType string, status string) (*[]art.ArticleDetail, error) {
+	articles, err := u.articleRepo.FindByCategory(categoryName, categoryType, status)
 	if err != nil {
 		return nil, err
 	}
 
-	articleDetails := make([]art.ArticleDetail, len(*articles))
-	for i, article := range *articles {
-		articleDetails[i] = *u.GetArticleDetail(article)
-	}
+	articleDetails := u.GetArticleDetails(*articles)
 
 	return &articleDetails, nil
 }
@@ -151,48 +166,68 @@ func (u *articleUsecase) Update(articleID string, article art.ArticleInput) erro
 		Description:  article.Description,
 		ThumbnailURL: article.ThumbnailURL,
 		AuthorID:     articleFound.AuthorID,
+		Status:       articleFound.Status,
+		PublishAt:    articleFound.PublishAt,
+		PublishedAt:  articleFound.PublishedAt,
 		CreatedAt:    articleFound.CreatedAt,
 		UpdatedAt:    time.Now(),
 	}
 
-	if err := u.articleRepo.DeleteAllArticleCategory(articleID); err != nil {
-		return err
-	}
-
-	for i := range article.WasteCategories {
-		articleCategory := art.ArticleCategories{
-			ArticleID:       articleID,
-			WasteCategoryID: wasteIDs[i],
-		}
-
-		if err := u.articleRepo.CreateArticleCategory(articleCategory); err != nil {
+	return u.articleRepo.Transaction(func(repo art.ArticleRepository) error {
+		if err := repo.DeleteAllArticleCategory(articleID); err != nil {
 			return err
 		}
-	}
 
-	for i := range article.ContentCategories {
-		articleCategory := art.ArticleCategories{
-			ArticleID:         articleID,
-			ContentCategoryID: int(contentIDs[i]),
+		if err := repo.DeleteAllSection(articleID); err != nil {
+			return err
 		}
 
-		if err := u.articleRepo.CreateArticleCategory(articleCategory); err != nil {
+		if err := saveArticleRelations(repo, articleID, article.Sections, wasteIDs, contentIDs); err != nil {
 			return err
 		}
+
+		return repo.Update(articleToUpdate)
+	})
+}
+
+// ChangeStatus moves an article along the editorial workflow. Publishing sets
+// the publish time to now, scheduling leaves publishing to PublishScheduled.
+func (uc *articleUsecase) ChangeStatus(articleID string, input art.ArticleStatusInput) (*art.ArticleDetail, error) {
+	articleFound, err := uc.articleRepo.FindByID(articleID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := u.articleRepo.DeleteAllSection(articleID); err != nil {
-		return err
+	if !articleFound.CanMoveTo(input.Status) {
+		return nil, pkg.ErrArticleStatus
 	}
 
-	for _, section := range article.Sections {
-		section.ArticleID = articleID
-		if err := u.articleRepo.CreateSection(section); err != nil {
-			return err
+	now := time.Now()
+	var publishAt *time.Time
+	publishedAt := articleFound.PublishedAt
+
+	switch input.Status {
+	case art.StatusScheduled:
+		parsed, err := time.Parse(time.RFC3339, input.PublishAt)
+		if err != nil || !parsed.After(now) {
+			return nil, pkg.ErrArticlePublishAt
 		}
+		publishAt = &parsed
+	case art.StatusPublished:
+		publishedAt = &now
+	}
+
+	if err := uc.articleRepo.UpdateStatus(articleFound.ID, input.Status, publishAt, publishedAt); err != nil {
+		return nil, err
 	}
 
-	return u.articleRepo.Update(articleToUpdate)
+	return uc.GetArticleByID(articleFound.ID, "")
+}
+
+// PublishScheduled publishes the scheduled articles that are due and returns
+// how many it published.
+func (uc *articleUsecase) PublishScheduled(now time.Time) (int64, error) {
+	return uc.articleRepo.PublishDue(now)
 }
 
 func (uc *articleUsecase) Delete(articleID string) error {
@@ -221,24 +256,57 @@ func (uc *articleUsecase) Delete(articleID string) error {
 }
 
 func (uc *articleUsecase) GetArticleDetail(article art.Article) *art.ArticleDetail {
-	adminDetail, _ := uc.GetDetailAuthor(article.AuthorID)
-	wasteCategories, contentCategories, _ := uc.articleRepo.FindCategories(article.ID)
-	comments, _ := uc.GetDetailComments(article.Comments)
-
-	articleDetail := art.ArticleDetail{
-		ID:                article.ID,
-		Author:            *adminDetail,
-		Title:             article.Title,
-		Description:       article.Description,
-		ThumbnailURL:      article.ThumbnailURL,
-		CreatedAt:         article.CreatedAt,
-		WasteCategories:   *wasteCategories,
-		ContentCategories: *contentCategories,
-		Sections:          article.Sections,
-		Comments:          *comments,
+	return &uc.GetArticleDetails([]art.Article{article})[0]
+}
+
+// GetArticleDetails resolves authors, categories and commenters for a page of
+// articles with a fixed number of queries.
+func (uc *articleUsecase) GetArticleDetails(articles []art.Article) []art.ArticleDetail {
+	articleIDs := make([]string, 0, len(articles))
+	authorIDs := make([]string, 0, len(articles))
+	var comments []art.ArticleComment
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article.ID)
+		authorIDs = append(authorIDs, article.AuthorID)
+		comments = append(comments, article.Comments...)
+	}
+
+	wasteCategories, contentCategories, _ := uc.articleRepo.FindCategoriesByArticleIDs(articleIDs)
+	users := uc.findCommentUsers(comments)
+
+	authors := make(map[string]art.AdminDetail)
+	if len(articles) > 0 {
+		admins, _ := uc.adminRepo.FindAdminsByIDs(authorIDs)
+		for _, adminFound := range admins {
+			authors[adminFound.ID] = art.AdminDetail{
+				ID:       adminFound.ID,
+				Name:     adminFound.Name,
+				ImageURL: adminFound.ImageUrl,
+			}
+		}
 	}
 
-	return &articleDetail
+	// lists are never nil, so empty ones serialize as [] rather than null
+	articleDetails := make([]art.ArticleDetail, len(articles))
+	for i, article := range articles {
+		articleDetails[i] = art.ArticleDetail{
+			ID:                article.ID,
+			Author:            authors[article.AuthorID],
+			Title:             article.Title,
+			Description:       article.Description,
+			ThumbnailURL:      article.ThumbnailURL,
+			Status:            article.Status,
+			PublishAt:         article.PublishAt,
+			PublishedAt:       article.PublishedAt,
+			CreatedAt:         article.CreatedAt,
+			WasteCategories:   append([]art.WasteCategory{}, wasteCategories[article.ID]...),
+			ContentCategories: append([]art.ContentCategory{}, contentCategories[article.ID]...),
+			Sections:          append([]art.ArticleSection{}, article.Sections...),
+			Comments:          buildCommentDetails(article.Comments, users),
+		}
+	}
+
+	return articleDetails
 }
 
 func (uc *articleUsecase) GetDetailAuthor(authorID string) (*art.AdminDetail, error) {
@@ -257,7 +325,7 @@ func (uc *articleUsecase) GetDetailAuthor(authorID string) (*art.AdminDetail, er
 }
 
 func (uc *articleUsecase) NewArticleComment(comment art.CommentInput) error {
-	articleFound, err := uc.GetArticleByID(comment.ArticleID)
+	articleFound, err := uc.GetArticleByID(comment.ArticleID, art.StatusPublished)
 	if err != nil {
 		return pkg.ErrArticleNotFound
 	}
@@ -296,21 +364,55 @@ func (uc *articleUsecase) GetDetailUser(userID string) (*art.UserDetail, error)
 }
 
 func (uc *articleUsecase) GetDetailComments(comments []art.ArticleComment) (*[]art.CommentDetail, error) {
+	commentDetails := buildCommentDetails(comments, uc.findCommentUsers(comments))
+	return &commentDetails, nil
+}
+
+// findCommentUsers loads every distinct commenter in a single query.
+func (uc *articleUsecase) findCommentUsers(comments []art.ArticleComment) map[string]art.UserDetail {
+	users := make(map[string]art.UserDetail)
+
+	var userIDs []string
+	for _, comment := range comments {
+		if _, ok := users[comment.UserID]; !ok {
+			users[comment.UserID] = art.UserDetail{}
+			userIDs = append(userIDs, comment.UserID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return users
+	}
+
+	usersFound, err := uc.userRepo.FindByIDs(userIDs)
+	if err != nil {
+		return users
+	}
+
+	for _, userFound := range *usersFound {
+		users[userFound.ID] = art.UserDetail{
+			ID:       userFound.ID,
+			Name:     userFound.Name,
+			ImageURL: userFound.PictureURL,
+		}
+	}
+
+	return users
+}
+
+func buildCommentDetails(comments []art.ArticleComment, users map[string]art.UserDetail) []art.CommentDetail {
 	commentDetails := make([]art.CommentDetail, len(comments))
 	for i, comment := range comments {
-		user, _ := uc.GetDetailUser(comment.UserID)
-		detail := art.CommentDetail{
+		commentDetails[i] = art.CommentDetail{
 			ID:        comment.ID,
-			User:      *user,
+			User:      users[comment.UserID],
 			ArticleID: comment.ArticleID,
 			Comment:   comment.Comment,
 			CreatedAt: comment.CreatedAt,
 		}
-
-		commentDetails[i] = detail
 	}
 
-	return &commentDetails, nil
+	return commentDetails
 }
 
 func (uc *articleUsecase) GetAllCategories() (*art.CategoriesResponse, error) {
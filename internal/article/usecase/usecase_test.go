@@ -0,0 +1,113 @@
+package article
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	admin "github.com/sawalreverr/recything/internal/admin/entity"
+	adminRepo "github.com/sawalreverr/recything/internal/admin/repository"
+	art "github.com/sawalreverr/recything/internal/article"
+	articleRepo "github.com/sawalreverr/recything/internal/article/repository"
+	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+	user "github.com/sawalreverr/recything/internal/user"
+	userRepo "github.com/sawalreverr/recything/internal/user/repository"
+)
+
+// seedArticles creates count articles, each by its own author, in a waste and
+// a content category, with a section and a comment by its own user. The
+// articles are returned with their sections and comments loaded.
+func seedArticles(t *testing.T, db database.Database, count int) []art.Article {
+	t.Helper()
+
+	// article ids are at most 20 characters
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	wasteCategory := art.WasteCategory{Name: "TWC" + suffix}
+	contentCategory := art.ContentCategory{Name: "TCC" + suffix}
+	for _, record := range []interface{}{&wasteCategory, &contentCategory} {
+		if err := db.GetDB().Create(record).Error; err != nil {
+			t.Fatalf("seed %T: %v", record, err)
+		}
+	}
+
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("TAR%s%02d", suffix, i)
+		author := admin.Admin{ID: fmt.Sprintf("TAD%s%02d", suffix, i), Name: "author", Role: "admin"}
+		reader := user.User{ID: fmt.Sprintf("TUS%s%02d", suffix, i), Name: "reader", Gender: "-", BirthDate: time.Now()}
+
+		records := []interface{}{
+			&author,
+			&reader,
+			&art.Article{ID: ids[i], Title: "article", AuthorID: author.ID, Status: art.StatusPublished},
+			&art.ArticleCategories{ArticleID: ids[i], WasteCategoryID: wasteCategory.ID},
+			&art.ArticleCategories{ArticleID: ids[i], ContentCategoryID: int(contentCategory.ID)},
+			&art.ArticleSection{ArticleID: ids[i], Title: "section"},
+			&art.ArticleComment{ArticleID: ids[i], UserID: reader.ID, Comment: "nice"},
+		}
+		for _, record := range records {
+			if err := db.GetDB().Create(record).Error; err != nil {
+				t.Fatalf("seed %T: %v", record, err)
+			}
+		}
+	}
+
+	var articles []art.Article
+	if err := db.GetDB().Preload("Sections").Preload("Comments").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+		t.Fatalf("load articles: %v", err)
+	}
+	return articles
+}
+
+func newTestUsecase(db database.Database) art.ArticleUsecase {
+	return NewArticleUsecase(articleRepo.NewArticleRepository(db), adminRepo.NewAdminRepository(db), userRepo.NewUserRepository(db))
+}
+
+func TestGetArticleDetailsQueryCount(t *testing.T) {
+	db := databasetest.Open(t)
+	uc := newTestUsecase(db)
+
+	queries := func(articles []art.Article) int {
+		stop := databasetest.CountQueries(t, db.GetDB())
+		details := uc.GetArticleDetails(articles)
+		count := stop()
+
+		for _, detail := range details {
+			if len(detail.WasteCategories) != 1 || len(detail.ContentCategories) != 1 || detail.Author.ID == "" ||
+				len(detail.Comments) != 1 || detail.Comments[0].User.ID == "" {
+				t.Fatalf("article %s is missing its categories, author or commenter", detail.ID)
+			}
+		}
+		return count
+	}
+
+	single := queries(seedArticles(t, db, 1))
+	page := queries(seedArticles(t, db, 50))
+	if single == 0 {
+		t.Fatal("no queries were counted")
+	}
+	if page != single {
+		t.Errorf("a page of 50 articles ran %d queries, a single article %d", page, single)
+	}
+}
+
+func TestGetArticleDetailsEmptyLists(t *testing.T) {
+	db := databasetest.Open(t)
+	uc := newTestUsecase(db)
+
+	details := uc.GetArticleDetails([]art.Article{{ID: fmt.Sprintf("TAR%x", time.Now().UnixNano())}})
+
+	body, err := json.Marshal(details)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"waste_categories":[]`, `"content_categories":[]`, `"sections":[]`, `"comments":[]`} {
+		if !strings.Contains(string(body), field) {
+			t.Errorf("%s is missing from %s", field, body)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package auth
 
 import (
+	"errors"
+
 	adm "github.com/sawalreverr/recything/internal/admin/repository"
 	a "github.com/sawalreverr/recything/internal/auth"
 	"github.com/sawalreverr/recything/internal/helper"
 	u "github.com/sawalreverr/recything/internal/user"
 	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
 )
 
 type authUsecase struct {
@@ -28,8 +31,10 @@ func (uc *authUsecase) RegisterUser(user a.Register) (*u.User, error) {
 	// 	return nil, pkg.ErrPhoneNumberAlreadyExists
 	// }
 
-	lastID, _ := uc.userRepository.FindLastID()
-	newID := helper.GenerateCustomID(lastID, "USR")
+	newID, err := uc.userRepository.NextID()
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
 
 	hashedPass, _ := helper.GenerateHash(user.Password)
 
@@ -43,6 +48,16 @@ func (uc *authUsecase) RegisterUser(user a.Register) (*u.User, error) {
 		IsVerified: false,
 	}
 
+	// new users start at the lowest level, when there is one
+	level, err := uc.userRepository.FindEntryLevel()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, pkg.ErrStatusInternalError
+	}
+	if level != nil {
+		newUser.AchievementID = level.ID
+		newUser.Badge = level.BadgeUrlUser
+	}
+
 	createdUser, err := uc.userRepository.Create(newUser)
 	if err != nil {
 		return nil, pkg.ErrStatusInternalError
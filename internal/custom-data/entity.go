@@ -8,7 +8,7 @@ import (
 )
 
 type CustomData struct {
-	ID          string `json:"id" gorm:"primaryKey"`
+	ID          string `json:"id" gorm:"primaryKey;type:varchar(20)"`
 	Topic       string `json:"topic"`
 	Description string `json:"description"`
 
@@ -21,7 +21,7 @@ type CustomDataRepository interface {
 	Create(data CustomData) (*CustomData, error)
 	FindByID(dataID string) (*CustomData, error)
 	FindAll(page int, limit int, sortBy string, sortType string) (*[]CustomData, int64, error)
-	FindLastID() (string, error)
+	NextID() (string, error)
 	Update(data CustomData) error
 	Delete(dataID string) error
 }
@@ -53,13 +53,8 @@ func (r *customDataRepository) FindAll(page int, limit int, sortBy string, sortT
 	return &customDatas, total, nil
 }
 
-func (r *customDataRepository) FindLastID() (string, error) {
-	var customData cdt.CustomData
-	if err := r.DB.GetDB().Unscoped().Order("id DESC").First(&customData).Error; err != nil {
-		return "CDT0000", err
-	}
-
-	return customData.ID, nil
+func (r *customDataRepository) NextID() (string, error) {
+	return database.NextID(r.DB, "CDT")
 }
 
 func (r *customDataRepository) Update(data cdt.CustomData) error {
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	cdt "github.com/sawalreverr/recything/internal/custom-data"
-	"github.com/sawalreverr/recything/internal/helper"
 	"github.com/sawalreverr/recything/pkg"
 )
 
@@ -17,8 +16,10 @@ func NewCustomDataUsecase(repo cdt.CustomDataRepository) cdt.CustomDataUsecase {
 }
 
 func (uc *customDataUsecase) NewCustomData(data cdt.CustomDataInput) (*cdt.CustomDataResponse, error) {
-	lastID, _ := uc.customDataRepository.FindLastID()
-	newID := helper.GenerateCustomID(lastID, "CDT")
+	newID, err := uc.customDataRepository.NextID()
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
 
 	newCustomData := cdt.CustomData{
 		ID:          newID,
@@ -21,11 +21,14 @@ type TotalUser struct {
 }
 
 type UserAchievement struct {
-	TotalUser int `json:"total_user"`
-	Classic   int `json:"classic"`
-	Silver    int `json:"silver"`
-	Gold      int `json:"gold"`
-	Platinum  int `json:"platinum"`
+	TotalUser int               `json:"total_user"`
+	Levels    []DataUserByLevel `json:"levels"`
+}
+
+type DataUserByLevel struct {
+	AchievementID int    `json:"achievement_id"`
+	Level         string `json:"level"`
+	TotalUser     int    `json:"total_user"`
 }
 
 type TotalReport struct {
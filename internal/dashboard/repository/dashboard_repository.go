@@ -8,10 +8,7 @@ type DashboardRepository interface {
 	GetTotalChallenge() (int, int, error)
 	GetTotalVideo() (int, int, error)
 	GetTotalArticle() (int, int, error)
-	GetUserClassic() (int, error)
-	GetUserSilver() (int, error)
-	GetUserGold() (int, error)
-	GetUserPlatinum() (int, error)
+	GetUserByLevel() ([]dto.DataUserByLevel, error)
 	GetReportLittering() (int, error)
 	GetReportRubbish() (int, error)
 	GetMonthlyReport(year int, reportType string) ([]dto.MonthlyReportStats, error)
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	art "github.com/sawalreverr/recything/internal/article"
 	"github.com/sawalreverr/recything/internal/dashboard/dto"
 	"github.com/sawalreverr/recything/internal/database"
+	pnt "github.com/sawalreverr/recything/internal/point"
 	rep "github.com/sawalreverr/recything/internal/report"
 	ch "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	usr "github.com/sawalreverr/recything/internal/user"
@@ -122,47 +124,70 @@ func (d *DashboardRepositoryImpl) GetTotalUser() (int, int, error) {
 	return int(totalUser), int(additionUserSinceYesterday), nil
 }
 
-// GetUserClassic implements DashboardRepository.
-func (d *DashboardRepositoryImpl) GetUserClassic() (int, error) {
-	classic := "https://res.cloudinary.com/dymhvau8n/image/upload/v1718189121/user_badge/htaemsjtlhfof7ww01ss.png"
-
-	var totalUser int64
-	if err := d.DB.GetDB().Model(&usr.User{}).Where("badge = ?", classic).Count(&totalUser).Error; err != nil {
-		return 0, err
+// GetUserByLevel counts the users at each level, the lowest level first. The
+// count goes by the achievement users hold, not its name or badge image, and
+// users that were never given one count towards the level their points reach.
+func (d *DashboardRepositoryImpl) GetUserByLevel() ([]dto.DataUserByLevel, error) {
+	var levels []achievement.Achievement
+	if err := d.DB.GetDB().Scopes(achievement.PointLevels).Order("target_point asc").Find(&levels).Error; err != nil {
+		return nil, err
 	}
-	return int(totalUser), nil
-}
-
-// GetUserGold implements DashboardRepository.
-func (d *DashboardRepositoryImpl) GetUserGold() (int, error) {
-	gold := "https://res.cloudinary.com/dymhvau8n/image/upload/v1718189184/user_badge/jshs1s2fwevahgtvjkgj.png"
 
-	var totalUser int64
-	if err := d.DB.GetDB().Model(&usr.User{}).Where("badge = ?", gold).Count(&totalUser).Error; err != nil {
-		return 0, err
+	var held []struct {
+		AchievementID int
+		TotalUser     int
+	}
+	if err := d.DB.GetDB().Model(&usr.User{}).
+		Select("achievement_id, COUNT(*) AS total_user").
+		Where("achievement_id <> 0").
+		Group("achievement_id").
+		Scan(&held).Error; err != nil {
+		return nil, err
 	}
-	return int(totalUser), nil
-}
 
-func (d *DashboardRepositoryImpl) GetUserPlatinum() (int, error) {
-	platinum := "https://res.cloudinary.com/dymhvau8n/image/upload/v1718188250/user_badge/icureiapdvtzyu5b99zu.png"
+	counts := make(map[int]int)
+	for _, row := range held {
+		counts[row.AchievementID] = row.TotalUser
+	}
 
-	var totalUser int64
-	if err := d.DB.GetDB().Model(&usr.User{}).Where("badge = ?", platinum).Count(&totalUser).Error; err != nil {
-		return 0, err
+	var unassigned []string
+	if err := d.DB.GetDB().Model(&usr.User{}).Where("achievement_id = 0").Pluck("id", &unassigned).Error; err != nil {
+		return nil, err
 	}
-	return int(totalUser), nil
-}
+	if len(unassigned) > 0 {
+		var sums []struct {
+			UserID string
+			Earned int
+		}
+		if err := d.DB.GetDB().Model(&pnt.Entry{}).
+			Scopes(pnt.Earned).
+			Select("user_id, COALESCE(SUM(amount), 0) AS earned").
+			Where("user_id IN ?", unassigned).
+			Group("user_id").
+			Scan(&sums).Error; err != nil {
+			return nil, err
+		}
 
-// GetUserSilver implements DashboardRepository.
-func (d *DashboardRepositoryImpl) GetUserSilver() (int, error) {
-	silver := "https://res.cloudinary.com/dymhvau8n/image/upload/v1718189221/user_badge/oespnjdgoynkairlutbk.png"
+		earned := make(map[string]int)
+		for _, sum := range sums {
+			earned[sum.UserID] = sum.Earned
+		}
+		for _, userID := range unassigned {
+			if reached := achievement.LevelReachedBy(levels, earned[userID]); reached != nil {
+				counts[reached.ID]++
+			}
+		}
+	}
 
-	var totalUser int64
-	if err := d.DB.GetDB().Model(&usr.User{}).Where("badge = ?", silver).Count(&totalUser).Error; err != nil {
-		return 0, err
+	stats := make([]dto.DataUserByLevel, 0, len(levels))
+	for _, level := range levels {
+		stats = append(stats, dto.DataUserByLevel{
+			AchievementID: level.ID,
+			Level:         level.Level,
+			TotalUser:     counts[level.ID],
+		})
 	}
-	return int(totalUser), nil
+	return stats, nil
 }
 
 func (d *DashboardRepositoryImpl) GetMonthlyReport(year int, reportType string) ([]dto.MonthlyReportStats, error) {
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	usr "github.com/sawalreverr/recything/internal/user"
+)
+
+func TestGetUserByLevel(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewDashboardRepository(db)
+
+	now := time.Now()
+	// ids are at most 20 characters
+	suffix := strconv.FormatInt(now.UnixNano(), 36)
+	base := 1000 + int(now.UnixNano()%900000)
+
+	// levels are named freely by admins, none of these is a classic name
+	levels := []achievement.Achievement{
+		{Level: "bronze " + suffix, Kind: achievement.KindPoint, TargetPoint: base},
+		{Level: "jade " + suffix, Kind: achievement.KindPoint, TargetPoint: base + 100},
+	}
+	for i := range levels {
+		if err := db.GetDB().Create(&levels[i]).Error; err != nil {
+			t.Fatalf("seed level: %v", err)
+		}
+	}
+
+	// two users hold the lower level, one the upper, and one was never given
+	// a level but earned enough for the upper one
+	users := []usr.User{
+		{ID: "DLA" + suffix, AchievementID: levels[0].ID},
+		{ID: "DLB" + suffix, AchievementID: levels[0].ID},
+		{ID: "DLC" + suffix, AchievementID: levels[1].ID},
+		{ID: "DLD" + suffix},
+	}
+	for i := range users {
+		users[i].Name = fmt.Sprintf("test user %d", i)
+		users[i].Gender = "-"
+		users[i].BirthDate = now
+		if err := db.GetDB().Create(&users[i]).Error; err != nil {
+			t.Fatalf("seed user: %v", err)
+		}
+	}
+	if err := db.GetDB().Create(&pnt.Entry{
+		UserID:     users[3].ID,
+		Amount:     base + 150,
+		SourceType: pnt.SourceAdjustment,
+		SourceID:   users[3].ID,
+	}).Error; err != nil {
+		t.Fatalf("seed ledger: %v", err)
+	}
+
+	stats, err := repository.GetUserByLevel()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]int{levels[0].ID: 2, levels[1].ID: 2}
+	found := 0
+	for _, stat := range stats {
+		total, ok := want[stat.AchievementID]
+		if !ok {
+			continue
+		}
+		found++
+		if stat.TotalUser != total {
+			t.Errorf("level %q has %d users, want %d", stat.Level, stat.TotalUser, total)
+		}
+	}
+	if found != len(want) {
+		t.Errorf("got %d of the %d seeded levels", found, len(want))
+	}
+}
@@ -35,19 +35,7 @@ func (usecase *DashboardUsecaseImpl) GetDashboardUsecase() (*dto.DashboardRespon
 		return nil, err
 	}
 
-	totalUserClassic, err := usecase.dashboardRepository.GetUserClassic()
-	if err != nil {
-		return nil, err
-	}
-	totalUserSilver, err := usecase.dashboardRepository.GetUserSilver()
-	if err != nil {
-		return nil, err
-	}
-	totalUserGold, err := usecase.dashboardRepository.GetUserGold()
-	if err != nil {
-		return nil, err
-	}
-	totalUserPlatinum, err := usecase.dashboardRepository.GetUserPlatinum()
+	userByLevel, err := usecase.dashboardRepository.GetUserByLevel()
 	if err != nil {
 		return nil, err
 	}
@@ -96,10 +84,7 @@ func (usecase *DashboardUsecaseImpl) GetDashboardUsecase() (*dto.DashboardRespon
 
 	userAchievement := dto.UserAchievement{
 		TotalUser: totalUser,
-		Classic:   totalUserClassic,
-		Silver:    totalUserSilver,
-		Gold:      totalUserGold,
-		Platinum:  totalUserPlatinum,
+		Levels:    userByLevel,
 	}
 
 	report := dto.TotalReport{
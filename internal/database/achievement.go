@@ -0,0 +1,89 @@
+package database
+
+import (
+	"log"
+
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	"github.com/sawalreverr/recything/internal/point"
+	user "github.com/sawalreverr/recything/internal/user"
+	"gorm.io/gorm"
+)
+
+// legacyBonusPercent is the bonus each user badge image used to earn before
+// the bonus was stored on achievements.
+var legacyBonusPercent = map[string]int{
+	"https://res.cloudinary.com/dymhvau8n/image/upload/v1718189121/user_badge/htaemsjtlhfof7ww01ss.png": 10,
+	"https://res.cloudinary.com/dymhvau8n/image/upload/v1718189221/user_badge/oespnjdgoynkairlutbk.png": 15,
+	"https://res.cloudinary.com/dymhvau8n/image/upload/v1718189184/user_badge/jshs1s2fwevahgtvjkgj.png": 20,
+	"https://res.cloudinary.com/dymhvau8n/image/upload/v1718188250/user_badge/icureiapdvtzyu5b99zu.png": 25,
+}
+
+// MigrateAchievementBonus adds the bonus column to existing achievements and
+// fills it from the old hard-coded bonuses. It must run before AutoMigrate,
+// as it only acts while the column is missing.
+func MigrateAchievementBonus(db Database) {
+	migrator := db.GetDB().Migrator()
+	if !migrator.HasTable(&achievement.Achievement{}) || migrator.HasColumn(&achievement.Achievement{}, "BonusPercent") {
+		return
+	}
+
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&achievement.Achievement{}, "BonusPercent"); err != nil {
+			return err
+		}
+
+		for badge, bonus := range legacyBonusPercent {
+			if err := tx.Model(&achievement.Achievement{}).
+				Where("badge_url_user = ?", badge).
+				Update("bonus_percent", bonus).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Migrating achievement bonus failed: %v", err)
+	}
+
+	log.Println("Achievement bonus migrated!")
+}
+
+// MigrateUserAchievements links users without an achievement to the one their
+// badge image belongs to, or else to the highest one their earned points
+// reach.
+func MigrateUserAchievements(db Database) {
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE users JOIN achievements ON achievements.badge_url_user = users.badge AND achievements.kind = 'point' AND achievements.deleted_at IS NULL
+			SET users.achievement_id = achievements.id
+			WHERE users.achievement_id = 0`).Error; err != nil {
+			return err
+		}
+
+		var achievements []achievement.Achievement
+		if err := tx.Scopes(achievement.PointLevels).Order("target_point desc").Find(&achievements).Error; err != nil {
+			return err
+		}
+
+		earned := tx.Model(&point.Entry{}).
+			Scopes(point.Earned).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("point_ledger.user_id = users.id")
+
+		for _, ach := range achievements {
+			if err := tx.Model(&user.User{}).
+				Where("achievement_id = 0 AND (?) >= ?", earned, ach.TargetPoint).
+				Updates(map[string]interface{}{
+					"achievement_id": ach.ID,
+					"badge":          ach.BadgeUrlUser,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Migrating user achievements failed: %v", err)
+	}
+
+	log.Println("User achievements migrated!")
+}
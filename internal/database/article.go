@@ -0,0 +1,38 @@
+package database
+
+import (
+	"log"
+
+	art "github.com/sawalreverr/recything/internal/article"
+	"gorm.io/gorm"
+)
+
+// MigrateArticleStatus adds the workflow columns to existing articles and
+// keeps them visible by publishing them as of their creation. It must run
+// before AutoMigrate, as it only acts while the status column is missing.
+func MigrateArticleStatus(db Database) {
+	migrator := db.GetDB().Migrator()
+	if !migrator.HasTable(&art.Article{}) || migrator.HasColumn(&art.Article{}, "Status") {
+		return
+	}
+
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		for _, column := range []string{"Status", "PublishAt", "PublishedAt"} {
+			if err := tx.Migrator().AddColumn(&art.Article{}, column); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&art.Article{}).
+			Where("1 = 1").
+			Updates(map[string]interface{}{
+				"status":       art.StatusPublished,
+				"published_at": gorm.Expr("created_at"),
+			}).Error
+	})
+	if err != nil {
+		log.Fatalf("Migrating article status failed: %v", err)
+	}
+
+	log.Println("Article status migrated!")
+}
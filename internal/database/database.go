@@ -4,6 +4,8 @@ import "gorm.io/gorm"
 
 type Database interface {
 	GetDB() *gorm.DB
+	Transaction(fn func(tx Database) error) error
+
 	InitSuperAdmin()
 	InitUser()
 
@@ -0,0 +1,85 @@
+// Package databasetest opens the MySQL database used by tests that need real
+// row locks and MySQL specific SQL.
+package databasetest
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sawalreverr/recything/internal/database"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DSNEnv names the variable holding the DSN of a disposable MySQL database,
+// e.g. "root:secret@tcp(localhost:3306)/recything_test?parseTime=True&loc=Local".
+const DSNEnv = "TEST_MYSQL_DSN"
+
+// Open connects to the test database and migrates every table. The test is
+// skipped when no DSN is configured. The database is shared between tests,
+// so tests must create their own rows with unique ids.
+func Open(t *testing.T) database.Database {
+	t.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", DSNEnv)
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		// tests create only the rows they need, without their parents
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+
+	conn := database.NewGormDatabase(db)
+	database.AutoMigrate(conn)
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return conn
+}
+
+// counters numbers the callbacks registered by CountQueries, gorm refuses a
+// callback name that is already taken.
+var counters atomic.Int64
+
+// CountQueries counts the statements run on db until the returned stop
+// function is called, which reports the count.
+func CountQueries(t *testing.T, db *gorm.DB) (stop func() int) {
+	t.Helper()
+
+	count := 0
+	counting := true
+	name := fmt.Sprintf("databasetest:count_%d", counters.Add(1))
+	callback := func(*gorm.DB) {
+		if counting {
+			count++
+		}
+	}
+
+	if err := db.Callback().Query().After("gorm:query").Register(name, callback); err != nil {
+		t.Fatalf("register query counter: %v", err)
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register(name, callback); err != nil {
+		t.Fatalf("register raw counter: %v", err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register(name, callback); err != nil {
+		t.Fatalf("register row counter: %v", err)
+	}
+
+	return func() int {
+		counting = false
+		return count
+	}
+}
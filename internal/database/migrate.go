@@ -9,11 +9,17 @@ import (
 	"github.com/sawalreverr/recything/internal/article"
 	customdata "github.com/sawalreverr/recything/internal/custom-data"
 	"github.com/sawalreverr/recything/internal/faq"
+	leaderboard "github.com/sawalreverr/recything/internal/leaderboard/entity"
+	"github.com/sawalreverr/recything/internal/point"
 	"github.com/sawalreverr/recything/internal/report"
+	"github.com/sawalreverr/recything/internal/reward"
+	"github.com/sawalreverr/recything/internal/streak"
 	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	task_template "github.com/sawalreverr/recything/internal/task/task_template/entity"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 	user "github.com/sawalreverr/recything/internal/user"
 	video "github.com/sawalreverr/recything/internal/video/manage_video/entity"
+	"github.com/sawalreverr/recything/internal/webhook"
 )
 
 func AutoMigrate(db Database) {
@@ -31,16 +37,24 @@ func AutoMigrate(db Database) {
 
 		&faq.FAQ{},
 		&achievement.Achievement{},
+		&achievement.LevelRecomputeJob{},
+		&achievement.UserLevelChange{},
+		&achievement.UserAchievement{},
 		&customdata.CustomData{},
 		&aboutus.AboutUs{},
 		&aboutus.AboutUsImage{},
 
 		&task.TaskChallenge{},
 		&task.TaskStep{},
+		&task_template.TaskTemplate{},
+		&task_template.TaskTemplateStep{},
 
 		&user_task.UserTaskChallenge{},
 		&user_task.UserTaskImage{},
 		&user_task.UserTaskStep{},
+		&user_task.UserTaskStepImage{},
+		&user_task.UserTaskSubmission{},
+		&user_task.UserTaskSubmissionImage{},
 
 		&video.Video{},
 		&video.VideoCategory{},
@@ -50,6 +64,25 @@ func AutoMigrate(db Database) {
 		&article.ArticleSection{},
 		&article.ArticleCategories{},
 		&article.ArticleComment{},
+
+		&point.Entry{},
+
+		&reward.Reward{},
+		&reward.Redemption{},
+
+		&leaderboard.Season{},
+		&leaderboard.SeasonReward{},
+		&leaderboard.SeasonStanding{},
+
+		&streak.Streak{},
+		&streak.Activity{},
+		&streak.Rule{},
+		&streak.Milestone{},
+
+		&webhook.Subscription{},
+		&webhook.Delivery{},
+
+		&Sequence{},
 	); err != nil {
 		log.Fatal("Database Migration Failed!")
 	}
@@ -31,6 +31,21 @@ func NewMySQLDatabase(conf *config.Config) Database {
 	return dbInstance
 }
 
+// NewGormDatabase wraps an already opened connection, for callers such as
+// tests that manage the connection themselves.
+func NewGormDatabase(db *gorm.DB) Database {
+	return &mysqlDatabase{DB: db}
+}
+
 func (m *mysqlDatabase) GetDB() *gorm.DB {
-	return dbInstance.DB
+	return m.DB
+}
+
+// Transaction runs fn inside a single database transaction. Repositories built
+// on top of tx share the same connection, so every write made through them is
+// committed together or rolled back when fn returns an error.
+func (m *mysqlDatabase) Transaction(fn func(tx Database) error) error {
+	return m.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(&mysqlDatabase{DB: tx})
+	})
 }
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"log"
+
+	"github.com/sawalreverr/recything/internal/point"
+	user "github.com/sawalreverr/recything/internal/user"
+	"gorm.io/gorm"
+)
+
+// MigratePointLedger records the points users held before the ledger existed
+// as an opening balance entry, so their ledger sums match the cached total.
+func MigratePointLedger(db Database) {
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		var users []user.User
+		if err := tx.Where("point > 0").
+			Where("NOT EXISTS (SELECT 1 FROM point_ledger WHERE point_ledger.user_id = users.id)").
+			Find(&users).Error; err != nil {
+			return err
+		}
+
+		for _, u := range users {
+			entry := point.Entry{
+				UserID:       u.ID,
+				Amount:       int(u.Point),
+				BasePoint:    int(u.Point),
+				BalanceAfter: int(u.Point),
+				SourceType:   point.SourceOpeningBalance,
+				SourceID:     u.ID,
+				Description:  "Opening balance",
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Migrating point ledger failed: %v", err)
+	}
+
+	log.Println("Point ledger migrated!")
+}
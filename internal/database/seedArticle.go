@@ -96,8 +96,10 @@ func generateArticle() ([]art.Article, []art.ArticleSection, []art.ArticleCatego
 			Description:  gofakeit.Paragraph(1, 2, 3, ""),
 			ThumbnailURL: gofakeit.ImageURL(640, 480),
 			AuthorID:     "AD0001",
+			Status:       art.StatusPublished,
 			CreatedAt:    randomDate(startDate, endDate),
 		}
+		article.PublishedAt = &article.CreatedAt
 
 		sectionCount := rand.Intn(4) + 2
 		for j := 0; j < sectionCount; j++ {
@@ -71,6 +71,7 @@ func generateTask() ([]taskEntity.TaskChallenge, []taskEntity.TaskStep) {
 			taskStep := taskEntity.TaskStep{
 				ID:              taskStepID,
 				TaskChallengeId: challengeID,
+				Position:        j + 1,
 				Title:           fmt.Sprintf("Step %d", j+1),
 				Description:     gofakeit.Paragraph(1, 2, 3, ""),
 				CreatedAt:       randomDate(startDateRange, startDate),
@@ -7,7 +7,12 @@ import (
 	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/helper"
+	leaderboard "github.com/sawalreverr/recything/internal/leaderboard/entity"
+	"github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/internal/reward"
+	"github.com/sawalreverr/recything/internal/streak"
 	userEntity "github.com/sawalreverr/recything/internal/user"
 )
 
@@ -86,11 +91,19 @@ func generateUser(password string) []userEntity.User {
 }
 
 func (m *mysqlDatabase) InitUser() {
-	if err := m.GetDB().Migrator().DropTable(&userEntity.User{}); err != nil {
+	// the ledger, redemptions, level changes, unlocks, streaks and season
+	// standings belong to the seeded users, so they are rebuilt with them
+	if err := m.GetDB().Migrator().DropTable(&leaderboard.SeasonStanding{}, &streak.Activity{}, &streak.Streak{}, &achievement.UserAchievement{}, &achievement.UserLevelChange{}, &reward.Redemption{}, &point.Entry{}, &userEntity.User{}); err != nil {
 		return
 	}
 
-	if err := m.GetDB().AutoMigrate(&userEntity.User{}); err != nil {
+	if err := m.GetDB().AutoMigrate(&userEntity.User{}, &point.Entry{}, &reward.Redemption{}, &achievement.UserLevelChange{}, &achievement.UserAchievement{}, &streak.Streak{}, &streak.Activity{}, &leaderboard.SeasonStanding{}); err != nil {
+		return
+	}
+
+	// the standings of closed seasons went with them, so those seasons are
+	// reopened and the scheduler closes them again against the new ledger
+	if err := m.GetDB().Model(&leaderboard.Season{}).Where("closed_at IS NOT NULL").Update("closed_at", nil).Error; err != nil {
 		return
 	}
 
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	"github.com/sawalreverr/recything/internal/admin/entity"
+	"github.com/sawalreverr/recything/internal/article"
+	customdata "github.com/sawalreverr/recything/internal/custom-data"
+	leaderboard "github.com/sawalreverr/recything/internal/leaderboard/entity"
+	"github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/internal/report"
+	"github.com/sawalreverr/recything/internal/reward"
+	"github.com/sawalreverr/recything/internal/streak"
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	task_template "github.com/sawalreverr/recything/internal/task/task_template/entity"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	user "github.com/sawalreverr/recything/internal/user"
+	video "github.com/sawalreverr/recything/internal/video/manage_video/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sequence keeps the last number handed out for a custom ID prefix such as
+// "USR" or "RPT". Rows are locked while a number is allocated so concurrent
+// requests never receive the same ID.
+type Sequence struct {
+	Prefix    string `gorm:"primaryKey;type:varchar(10)"`
+	LastValue int64  `gorm:"not null;default:0"`
+	UpdatedAt time.Time
+}
+
+// sequenceModels maps every custom ID prefix to the table that owns it. It is
+// used to seed a sequence from the IDs already stored in that table.
+var sequenceModels = map[string]interface{}{
+	"USR": &user.User{},
+	"AD":  &entity.Admin{},
+	"RPT": &report.Report{},
+	"ART": &article.Article{},
+	"CDT": &customdata.CustomData{},
+	"TM":  &task.TaskChallenge{},
+	"UT":  &user_task.UserTaskChallenge{},
+}
+
+// NextID allocates the next ID for prefix, e.g. "USR0043". When db is bound to
+// a transaction the sequence row stays locked until that transaction ends.
+func NextID(db Database, prefix string) (string, error) {
+	var next int64
+
+	err := db.Transaction(func(tx Database) error {
+		seq, err := lockSequence(tx, prefix)
+		if err != nil {
+			return err
+		}
+
+		next = seq.LastValue + 1
+		return tx.GetDB().Model(&Sequence{}).
+			Where("prefix = ?", prefix).
+			Update("last_value", next).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return FormatID(prefix, next), nil
+}
+
+// FormatID renders a sequence number with its prefix. Numbers are padded to
+// four digits and simply grow wider past 9999.
+func FormatID(prefix string, number int64) string {
+	return fmt.Sprintf("%s%04d", prefix, number)
+}
+
+func lockSequence(tx Database, prefix string) (*Sequence, error) {
+	var seq Sequence
+	err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).Where("prefix = ?", prefix).First(&seq).Error
+	if err == nil {
+		return &seq, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := seedSequence(tx.GetDB(), prefix); err != nil {
+		return nil, err
+	}
+
+	if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).Where("prefix = ?", prefix).First(&seq).Error; err != nil {
+		return nil, err
+	}
+	return &seq, nil
+}
+
+// seedSequence makes sure the sequence for prefix is at least as high as the
+// largest ID already stored, including soft-deleted rows.
+func seedSequence(db *gorm.DB, prefix string) error {
+	var highest int64
+
+	if model, ok := sequenceModels[prefix]; ok {
+		if err := db.Unscoped().Model(model).
+			Select("COALESCE(MAX(CAST(SUBSTRING(id, ?) AS UNSIGNED)), 0)", len(prefix)+1).
+			Where("id LIKE ?", prefix+"%").
+			Scan(&highest).Error; err != nil {
+			return err
+		}
+	}
+
+	return db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_value": gorm.Expr("GREATEST(last_value, VALUES(last_value))"),
+		}),
+	}).Create(&Sequence{Prefix: prefix, LastValue: highest}).Error
+}
+
+// idColumnWidth is the width every custom ID column, and every column
+// referencing one, is declared with.
+const idColumnWidth = 20
+
+// MigrateIDColumns resizes the custom ID columns, and the columns referencing
+// them, to the varchar(20) the entities declare. Columns created before were
+// varchar(191) or longtext, so most of them are narrowed; when a stored value
+// is longer than that nothing is altered and startup stops, rather than
+// truncating it. AutoMigrate never resizes primary keys and would alter a
+// referencing column before its key, so this runs first. Tables and columns
+// that do not exist yet are left to AutoMigrate.
+func MigrateIDColumns(db Database) {
+	columns := []struct {
+		model  interface{}
+		column string
+	}{
+		// USR
+		{&user.User{}, "id"},
+		{&report.Report{}, "author_id"},
+		{&article.ArticleComment{}, "user_id"},
+		{&user_task.UserTaskChallenge{}, "user_id"},
+		{&point.Entry{}, "user_id"},
+		{&reward.Redemption{}, "user_id"},
+		{&streak.Activity{}, "user_id"},
+		{&achievement.UserLevelChange{}, "user_id"},
+		{&achievement.UserAchievement{}, "user_id"},
+		{&video.Comment{}, "user_id"},
+
+		// AD
+		{&entity.Admin{}, "id"},
+		{&article.Article{}, "author_id"},
+		{&task.TaskChallenge{}, "admin_id"},
+		{&task_template.TaskTemplate{}, "admin_id"},
+		{&leaderboard.Season{}, "admin_id"},
+		{&user_task.UserTaskChallenge{}, "claimed_by"},
+		{&user_task.UserTaskSubmission{}, "reviewed_by"},
+
+		// RPT
+		{&report.Report{}, "id"},
+		{&report.ReportWasteMaterial{}, "report_id"},
+		{&report.ReportImage{}, "report_id"},
+
+		// ART
+		{&article.Article{}, "id"},
+		{&article.ArticleCategories{}, "article_id"},
+		{&article.ArticleSection{}, "article_id"},
+		{&article.ArticleComment{}, "article_id"},
+
+		// CDT
+		{&customdata.CustomData{}, "id"},
+
+		// TM
+		{&task.TaskChallenge{}, "id"},
+		{&task.TaskStep{}, "task_challenge_id"},
+		{&user_task.UserTaskChallenge{}, "task_challenge_id"},
+
+		// UT
+		{&user_task.UserTaskChallenge{}, "id"},
+		{&user_task.UserTaskImage{}, "user_task_challenge_id"},
+		{&user_task.UserTaskStep{}, "user_task_challenge_id"},
+		{&user_task.UserTaskSubmission{}, "user_task_challenge_id"},
+	}
+
+	// a key and the columns referencing it are altered one at a time, so the
+	// foreign key checks are paused on the connection doing it
+	err := db.GetDB().Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		migrator := conn.Migrator()
+		existing := columns[:0]
+		for _, c := range columns {
+			if !migrator.HasTable(c.model) || !migrator.HasColumn(c.model, c.column) {
+				continue
+			}
+
+			var tooLong int64
+			if err := conn.Unscoped().Model(c.model).
+				Where("CHAR_LENGTH(?) > ?", clause.Column{Name: c.column}, idColumnWidth).
+				Count(&tooLong).Error; err != nil {
+				return fmt.Errorf("column %s: %w", c.column, err)
+			}
+			if tooLong > 0 {
+				return fmt.Errorf("column %s: %d values are longer than %d characters", c.column, tooLong, idColumnWidth)
+			}
+			existing = append(existing, c)
+		}
+
+		for _, c := range existing {
+			if err := migrator.AlterColumn(c.model, c.column); err != nil {
+				return fmt.Errorf("column %s: %w", c.column, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Resizing ID columns failed: %v", err)
+	}
+
+	log.Println("ID columns resized!")
+}
+
+// MigrateSequences brings every sequence in line with the data that is
+// already stored.
+func MigrateSequences(db Database) {
+	for prefix := range sequenceModels {
+		if err := seedSequence(db.GetDB(), prefix); err != nil {
+			log.Fatalf("Seeding sequence %s failed: %v", prefix, err)
+		}
+	}
+
+	log.Println("Sequences synchronized!")
+}
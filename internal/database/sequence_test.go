@@ -0,0 +1,93 @@
+package database_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	customdata "github.com/sawalreverr/recything/internal/custom-data"
+	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+)
+
+func TestNextIDSeedsFromStoredIDs(t *testing.T) {
+	db := databasetest.Open(t)
+
+	// the highest stored id is soft-deleted, it still may not be handed out
+	// again
+	highest := 900000 + time.Now().UnixNano()%90000
+	stored := []customdata.CustomData{
+		{ID: database.FormatID("CDT", highest-1), Topic: "test"},
+		{ID: database.FormatID("CDT", highest), Topic: "test"},
+	}
+	for i := range stored {
+		if err := db.GetDB().Create(&stored[i]).Error; err != nil {
+			t.Fatalf("seed custom data: %v", err)
+		}
+	}
+	if err := db.GetDB().Delete(&stored[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// without a sequence row the next id is seeded from the stored ones
+	if err := db.GetDB().Where("prefix = ?", "CDT").Delete(&database.Sequence{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := database.NextID(db, "CDT")
+	if err != nil {
+		t.Fatalf("next id: %v", err)
+	}
+	if want := database.FormatID("CDT", highest+1); id != want {
+		t.Errorf("next id is %s, want %s", id, want)
+	}
+
+	// seeding again never moves an existing sequence back
+	database.MigrateSequences(db)
+	id, err = database.NextID(db, "CDT")
+	if err != nil {
+		t.Fatalf("next id: %v", err)
+	}
+	if want := database.FormatID("CDT", highest+2); id != want {
+		t.Errorf("next id after seeding is %s, want %s", id, want)
+	}
+}
+
+func TestNextIDConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+
+	// a prefix of its own, so the sequence starts from nothing
+	prefix := "Q" + strconv.FormatInt(time.Now().UnixNano()%2176782336, 36)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	ids := make(chan string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := database.NextID(db, prefix)
+			if err != nil {
+				t.Errorf("next id: %v", err)
+				return
+			}
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("id %s was handed out twice", id)
+		}
+		seen[id] = true
+	}
+	for i := int64(1); i <= callers; i++ {
+		if id := database.FormatID(prefix, i); !seen[id] {
+			t.Errorf("id %s was not handed out", id)
+		}
+	}
+}
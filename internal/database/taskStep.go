@@ -0,0 +1,113 @@
+package database
+
+import (
+	"log"
+	"sort"
+
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"gorm.io/gorm"
+)
+
+// MigrateTaskSteps gives steps created before positions existed an explicit
+// order and moves the progress of participants that still point at steps
+// replaced by an earlier challenge update onto the current steps.
+func MigrateTaskSteps(db Database) {
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := numberTaskSteps(tx); err != nil {
+			return err
+		}
+		return remapStepProgress(tx)
+	})
+	if err != nil {
+		log.Fatalf("Migrating task steps failed: %v", err)
+	}
+
+	log.Println("Task steps migrated!")
+}
+
+func numberTaskSteps(tx *gorm.DB) error {
+	var challengeIds []string
+	if err := tx.Model(&task.TaskStep{}).Where("position = ?", 0).Distinct().Pluck("task_challenge_id", &challengeIds).Error; err != nil {
+		return err
+	}
+
+	for _, challengeId := range challengeIds {
+		var steps []task.TaskStep
+		if err := task.OrderSteps(tx.Where("task_challenge_id = ?", challengeId)).Find(&steps).Error; err != nil {
+			return err
+		}
+
+		// unnumbered steps keep their creation order after any numbered ones
+		sort.SliceStable(steps, func(i, j int) bool {
+			return steps[i].Position != 0 && steps[j].Position == 0
+		})
+		for i, step := range steps {
+			if err := tx.Model(&task.TaskStep{}).Where("id = ?", step.ID).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func remapStepProgress(tx *gorm.DB) error {
+	var userTasks []user_task.UserTaskChallenge
+	if err := tx.Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps").
+		Where("status_progress = ?", "in_progress").
+		Find(&userTasks).Error; err != nil {
+		return err
+	}
+
+	for _, userTask := range userTasks {
+		current := make(map[int]bool, len(userTask.TaskChallenge.TaskSteps))
+		for _, step := range userTask.TaskChallenge.TaskSteps {
+			current[step.ID] = true
+		}
+
+		covered := make(map[int]bool, len(userTask.UserTaskSteps))
+		var stale []user_task.UserTaskStep
+		for _, progress := range userTask.UserTaskSteps {
+			if current[progress.TaskStepID] && !covered[progress.TaskStepID] {
+				covered[progress.TaskStepID] = true
+				continue
+			}
+			stale = append(stale, progress)
+		}
+
+		// stale entries were created in the order of the steps they pointed
+		// at, so they are handed to the uncovered steps in position order
+		sort.SliceStable(stale, func(i, j int) bool {
+			return stale[i].TaskStepID < stale[j].TaskStepID
+		})
+		for _, step := range userTask.TaskChallenge.TaskSteps {
+			if covered[step.ID] {
+				continue
+			}
+
+			if len(stale) > 0 {
+				progress := stale[0]
+				stale = stale[1:]
+				if err := tx.Model(&user_task.UserTaskStep{}).Where("id = ?", progress.ID).Update("task_step_id", step.ID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Create(&user_task.UserTaskStep{
+				UserTaskChallengeID: userTask.ID,
+				TaskStepID:          step.ID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, progress := range stale {
+			if err := tx.Delete(&user_task.UserTaskStep{}, progress.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"log"
+
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"gorm.io/gorm"
+)
+
+// MigrateSubmissions fills the submission time of user tasks sent for review
+// before it was recorded, using their last update as the closest estimate, and
+// keeps their current state as the first entry of their submission history.
+func MigrateSubmissions(db Database) {
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user_task.UserTaskChallenge{}).
+			Where("status_progress = ? AND submitted_at IS NULL", "done").
+			Update("submitted_at", gorm.Expr("updated_at")).Error; err != nil {
+			return err
+		}
+		return backfillSubmissions(tx)
+	})
+	if err != nil {
+		log.Fatalf("Migrating submissions failed: %v", err)
+	}
+
+	log.Println("Submissions migrated!")
+}
+
+func backfillSubmissions(tx *gorm.DB) error {
+	var userTasks []user_task.UserTaskChallenge
+	if err := tx.Preload("ImageTask").
+		Where("status_progress = ?", "done").
+		Where("NOT EXISTS (SELECT 1 FROM user_task_submissions WHERE user_task_submissions.user_task_challenge_id = user_task_challenges.id)").
+		Find(&userTasks).Error; err != nil {
+		return err
+	}
+
+	for _, userTask := range userTasks {
+		submission := user_task.UserTaskSubmission{
+			UserTaskChallengeID: userTask.ID,
+			Attempt:             1,
+			DescriptionImage:    userTask.DescriptionImage,
+			SubmittedAt:         *userTask.SubmittedAt,
+			Decision:            userTask.StatusAccept,
+			Reason:              userTask.Reason,
+		}
+		if userTask.StatusAccept == "accept" {
+			acceptedAt := userTask.AcceptedAt
+			submission.ReviewedAt = &acceptedAt
+		}
+		for _, image := range userTask.ImageTask {
+			submission.Images = append(submission.Images, user_task.UserTaskSubmissionImage{ImageUrl: image.ImageUrl})
+		}
+		if err := tx.Create(&submission).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
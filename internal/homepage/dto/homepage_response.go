@@ -1,10 +1,20 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/sawalreverr/recything/internal/streak"
+)
+
 type HomepageResponse struct {
 	User        *DataUser          `json:"user"`
 	Articles    []*DataArtcicle    `json:"articles"`
 	Videos      []*DataVideo       `json:"videos"`
 	Leaderboard []*DataLeaderboard `json:"leaderboard"`
+
+	// Season is the running season the leaderboard is ranked by, the
+	// leaderboard shows lifetime points when it is nil.
+	Season *DataSeason `json:"season"`
 }
 
 type DataUser struct {
@@ -13,6 +23,8 @@ type DataUser struct {
 	PictureURL string `json:"picture_url"`
 	Point      int    `json:"point"`
 	Badge      string `json:"badge"`
+
+	Streak *streak.Summary `json:"streak"`
 }
 
 type DataArtcicle struct {
@@ -41,3 +53,9 @@ type DataLeaderboard struct {
 	Point      int    `json:"point"`
 	Badge      string `json:"badge"`
 }
+
+type DataSeason struct {
+	Id    uint      `json:"id"`
+	Name  string    `json:"name"`
+	EndAt time.Time `json:"end_at"`
+}
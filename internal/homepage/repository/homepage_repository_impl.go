@@ -19,7 +19,8 @@ func NewHomepageRepository(db database.Database) HomepageRepository {
 func (repository *HomepageRepositoryImpl) GetArcticle() (*[]article.Article, error) {
 	var articles []article.Article
 	if err := repository.DB.GetDB().
-		Order("created_at desc").
+		Where("status = ?", article.StatusPublished).
+		Order("published_at desc").
 		Limit(2).
 		Find(&articles).Error; err != nil {
 		return nil, err
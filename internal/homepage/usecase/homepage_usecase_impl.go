@@ -1,17 +1,23 @@
 package usecase
 
 import (
+	"errors"
+
 	"github.com/sawalreverr/recything/internal/homepage/dto"
 	"github.com/sawalreverr/recything/internal/homepage/repository"
+	leaderboardUc "github.com/sawalreverr/recything/internal/leaderboard/usecase"
+	"github.com/sawalreverr/recything/internal/streak"
 	"github.com/sawalreverr/recything/pkg"
 )
 
 type HomepageUsecaseImpl struct {
 	HomepageRepository repository.HomepageRepository
+	Streak             streak.Reader
+	Leaderboard        leaderboardUc.LeaderboardUsecase
 }
 
-func NewHomepageUsecase(homepageRepository repository.HomepageRepository) HomepageUsecase {
-	return &HomepageUsecaseImpl{HomepageRepository: homepageRepository}
+func NewHomepageUsecase(homepageRepository repository.HomepageRepository, reader streak.Reader, leaderboardUsecase leaderboardUc.LeaderboardUsecase) HomepageUsecase {
+	return &HomepageUsecaseImpl{HomepageRepository: homepageRepository, Streak: reader, Leaderboard: leaderboardUsecase}
 }
 
 func (usecase *HomepageUsecaseImpl) GetHomepageUsecase(userId string) (*dto.HomepageResponse, error) {
@@ -31,20 +37,10 @@ func (usecase *HomepageUsecaseImpl) GetHomepageUsecase(userId string) (*dto.Home
 		})
 	}
 
-	leaderboard, err := usecase.HomepageRepository.GetLeaderboard()
+	dataLeaderboard, dataSeason, err := usecase.getLeaderboard()
 	if err != nil {
 		return nil, err
 	}
-	var dataLeaderboard []*dto.DataLeaderboard
-	for _, user := range *leaderboard {
-		dataLeaderboard = append(dataLeaderboard, &dto.DataLeaderboard{
-			Id:         user.ID,
-			Name:       user.Name,
-			PictureURL: user.PictureURL,
-			Point:      int(user.Point),
-			Badge:      user.Badge,
-		})
-	}
 	user, err := usecase.HomepageRepository.GetUserData(userId)
 	if err != nil {
 		return nil, pkg.ErrUserNotFound
@@ -69,10 +65,53 @@ func (usecase *HomepageUsecaseImpl) GetHomepageUsecase(userId string) (*dto.Home
 			Author_Profile: admin.ImageUrl,
 		})
 	}
+	streakSummary, err := usecase.Streak.Summary(userId)
+	if err != nil {
+		return nil, err
+	}
 	return &dto.HomepageResponse{
-		User:        &dto.DataUser{Id: user.ID, Name: user.Name, Point: int(user.Point), Badge: user.Badge, PictureURL: user.PictureURL},
+		User:        &dto.DataUser{Id: user.ID, Name: user.Name, Point: int(user.Point), Badge: user.Badge, PictureURL: user.PictureURL, Streak: streakSummary},
 		Articles:    dataArticle,
 		Videos:      dataVideo,
 		Leaderboard: dataLeaderboard,
+		Season:      dataSeason,
 	}, nil
 }
+
+// getLeaderboard ranks the top users of the running season, or by lifetime
+// points when no season is running.
+func (usecase *HomepageUsecaseImpl) getLeaderboard() ([]*dto.DataLeaderboard, *dto.DataSeason, error) {
+	var dataLeaderboard []*dto.DataLeaderboard
+
+	season, err := usecase.Leaderboard.GetActiveSeasonStandingsUsecase(1, 10, "")
+	if err == nil {
+		for _, standing := range season.Standings {
+			dataLeaderboard = append(dataLeaderboard, &dto.DataLeaderboard{
+				Id:         standing.Id,
+				Name:       standing.Name,
+				PictureURL: standing.PictureURL,
+				Point:      standing.Point,
+				Badge:      standing.Badge,
+			})
+		}
+		return dataLeaderboard, &dto.DataSeason{Id: season.Season.Id, Name: season.Season.Name, EndAt: season.Season.EndAt}, nil
+	}
+	if !errors.Is(err, pkg.ErrSeasonNotFound) {
+		return nil, nil, err
+	}
+
+	leaderboard, err := usecase.HomepageRepository.GetLeaderboard()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, user := range *leaderboard {
+		dataLeaderboard = append(dataLeaderboard, &dto.DataLeaderboard{
+			Id:         user.ID,
+			Name:       user.Name,
+			PictureURL: user.PictureURL,
+			Point:      int(user.Point),
+			Badge:      user.Badge,
+		})
+	}
+	return dataLeaderboard, nil, nil
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type LeaderboardRequest struct {
+	Period   string
+	Province string
+	City     string
+	Page     int
+	Limit    int
+}
+
+// LeaderboardFilter selects the ledger entries and users a board is built
+// from. A nil Since or Until leaves that side of the window open.
+type LeaderboardFilter struct {
+	Since    *time.Time
+	Until    *time.Time
+	Province string
+	City     string
+}
+
+// RankedUser is one row of a board.
+type RankedUser struct {
+	Ranking    int
+	ID         string
+	Name       string
+	PictureURL string
+	Badge      string
+	Address    string
+	Province   string
+	City       string
+	Point      int
+	ReachedAt  time.Time
+}
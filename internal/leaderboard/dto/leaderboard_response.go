@@ -1,14 +1,33 @@
 package dto
 
+import "time"
+
 type DataLeaderboard struct {
+	Rank       int    `json:"rank"`
 	Id         string `json:"id"`
 	Name       string `json:"name"`
 	PictureURL string `json:"picture_url"`
 	Point      int    `json:"point"`
 	Badge      string `json:"badge"`
 	Address    string `json:"address"`
+	Province   string `json:"province"`
+	City       string `json:"city"`
 }
 
 type LeaderboardResponse struct {
+	Period   string     `json:"period"`
+	Since    *time.Time `json:"since"`
+	Province string     `json:"province,omitempty"`
+	City     string     `json:"city,omitempty"`
+	Total    int64      `json:"total"`
+	Page     int        `json:"page"`
+	Limit    int        `json:"limit"`
+
 	DataLeaderboard []*DataLeaderboard `json:"data_leaderboard"`
+
+	// CurrentUser and Neighbours show where the caller stands, also when they
+	// are not on the requested page. Both are empty for admins and for users
+	// without points in the window.
+	CurrentUser *DataLeaderboard   `json:"current_user"`
+	Neighbours  []*DataLeaderboard `json:"neighbours"`
 }
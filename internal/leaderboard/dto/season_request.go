@@ -0,0 +1,16 @@
+package dto
+
+// SeasonRequest creates or replaces a season. Dates are days in WIB, the
+// season runs from the start of StartsOn to the end of EndsOn.
+type SeasonRequest struct {
+	Name        string                `json:"name" validate:"required,max=100"`
+	Description string                `json:"description"`
+	StartsOn    string                `json:"starts_on" validate:"required"`
+	EndsOn      string                `json:"ends_on" validate:"required"`
+	Rewards     []SeasonRewardRequest `json:"rewards" validate:"dive"`
+}
+
+type SeasonRewardRequest struct {
+	Rank       int `json:"rank" validate:"required,min=1"`
+	BonusPoint int `json:"bonus_point" validate:"required,min=1"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+type SeasonReward struct {
+	Rank       int `json:"rank"`
+	BonusPoint int `json:"bonus_point"`
+}
+
+type SeasonResponse struct {
+	Id          uint           `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	StartAt     time.Time      `json:"start_at"`
+	EndAt       time.Time      `json:"end_at"`
+	Status      string         `json:"status"`
+	ClosedAt    *time.Time     `json:"closed_at"`
+	Rewards     []SeasonReward `json:"rewards"`
+}
+
+type SeasonStanding struct {
+	DataLeaderboard
+	BonusPoint int `json:"bonus_point"`
+}
+
+// SeasonStandingsResponse shows the live board of a running season and the
+// frozen final standings of a closed one.
+type SeasonStandingsResponse struct {
+	Season    SeasonResponse    `json:"season"`
+	Final     bool              `json:"final"`
+	Total     int64             `json:"total"`
+	Page      int               `json:"page"`
+	Limit     int               `json:"limit"`
+	Standings []*SeasonStanding `json:"standings"`
+
+	CurrentUser *SeasonStanding `json:"current_user"`
+}
+
+type SeasonListResponse struct {
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	Limit   int              `json:"limit"`
+	Seasons []SeasonResponse `json:"seasons"`
+}
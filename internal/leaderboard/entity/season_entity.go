@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// season states, derived from the clock and ClosedAt
+const (
+	SeasonScheduled = "scheduled"
+	SeasonActive    = "active"
+	SeasonEnded     = "ended"
+	SeasonClosed    = "closed"
+)
+
+// Season is a competition over the points users earn between StartAt and
+// EndAt. When it closes the standings are copied into SeasonStanding and do
+// not change afterwards.
+type Season struct {
+	ID          uint `gorm:"primaryKey"`
+	Name        string
+	Description string `gorm:"type:text"`
+	StartAt     time.Time
+	// EndAt is exclusive, the season covers points earned before it.
+	EndAt     time.Time      `gorm:"index"`
+	Rewards   []SeasonReward `gorm:"foreignKey:SeasonId"`
+	ClosedAt  *time.Time
+	AdminId   string         `gorm:"index;type:varchar(20)"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// SeasonReward is the bonus paid to the user finishing at Rank.
+type SeasonReward struct {
+	ID         uint `gorm:"primaryKey"`
+	SeasonId   uint `gorm:"uniqueIndex:idx_season_reward_rank"`
+	Rank       int  `gorm:"uniqueIndex:idx_season_reward_rank"`
+	BonusPoint int
+}
+
+// SeasonStanding is a user's final place in a closed season. Name, picture
+// and badge are copied so the results read the same later on.
+type SeasonStanding struct {
+	ID           uint   `gorm:"primaryKey"`
+	SeasonId     uint   `gorm:"uniqueIndex:idx_season_standing_rank;uniqueIndex:idx_season_standing_user"`
+	Rank         int    `gorm:"uniqueIndex:idx_season_standing_rank"`
+	UserId       string `gorm:"type:varchar(20);uniqueIndex:idx_season_standing_user"`
+	Name         string
+	PictureURL   string
+	Badge        string
+	Province     string
+	City         string
+	Point        int
+	ReachedAt    time.Time
+	BonusPoint   int
+	PointEntryId *uint
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// Status tells where the season stands at now.
+func (s *Season) Status(now time.Time) string {
+	switch {
+	case s.ClosedAt != nil:
+		return SeasonClosed
+	case now.Before(s.StartAt):
+		return SeasonScheduled
+	case now.Before(s.EndAt):
+		return SeasonActive
+	default:
+		return SeasonEnded
+	}
+}
+
+// BonusFor returns the bonus for a final rank, zero when the rank earns none.
+func (s *Season) BonusFor(rank int) int {
+	for _, reward := range s.Rewards {
+		if reward.Rank == rank {
+			return reward.BonusPoint
+		}
+	}
+	return 0
+}
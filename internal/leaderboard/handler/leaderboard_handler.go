@@ -4,4 +4,11 @@ import "github.com/labstack/echo/v4"
 
 type LeaderboardHandler interface {
 	GetLeaderboardHandler(c echo.Context) error
+
+	CreateSeasonHandler(c echo.Context) error
+	UpdateSeasonHandler(c echo.Context) error
+	DeleteSeasonHandler(c echo.Context) error
+	GetSeasonsHandler(c echo.Context) error
+	GetSeasonStandingsHandler(c echo.Context) error
+	GetActiveSeasonStandingsHandler(c echo.Context) error
 }
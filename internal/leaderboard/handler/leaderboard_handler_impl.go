@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sawalreverr/recything/internal/helper"
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
 	"github.com/sawalreverr/recything/internal/leaderboard/usecase"
+	"github.com/sawalreverr/recything/pkg"
 )
 
 type LeaderboardHandlerImpl struct {
@@ -17,11 +21,53 @@ func NewLeaderboardHandler(leaderboardUsecase usecase.LeaderboardUsecase) Leader
 }
 
 func (handler LeaderboardHandlerImpl) GetLeaderboardHandler(c echo.Context) error {
-	leaderboard, err := handler.LeaderboardUsecase.GetLeaderboardUsecase()
+	page, limit, err := pagination(c)
 	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	request := dto.LeaderboardRequest{
+		Period:   c.QueryParam("period"),
+		Province: c.QueryParam("province"),
+		City:     c.QueryParam("city"),
+		Page:     page,
+		Limit:    limit,
+	}
+
+	leaderboard, err := handler.LeaderboardUsecase.GetLeaderboardUsecase(request, rankedUserId(c))
+	if err != nil {
+		if errors.Is(err, pkg.ErrLeaderboardPeriod) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
 	}
 
-	responseData := helper.ResponseData(http.StatusOK, "data successfully retrieved", leaderboard.DataLeaderboard)
+	responseData := helper.ResponseData(http.StatusOK, "data successfully retrieved", leaderboard)
 	return c.JSON(http.StatusOK, responseData)
 }
+
+// MaxLimit is the most rows a single page of a board can ask for.
+const MaxLimit = 100
+
+func pagination(c echo.Context) (int, int, error) {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > MaxLimit {
+		return 0, 0, pkg.ErrLeaderboardLimit
+	}
+	return page, limit, nil
+}
+
+// rankedUserId is the caller when they can appear on a board, only users do.
+func rankedUserId(c echo.Context) string {
+	if claims := c.Get("user").(*helper.JwtCustomClaims); claims.Role == "user" {
+		return claims.UserID
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sawalreverr/recything/internal/helper"
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+func (handler LeaderboardHandlerImpl) CreateSeasonHandler(c echo.Context) error {
+	var request dto.SeasonRequest
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	season, err := handler.LeaderboardUsecase.CreateSeasonUsecase(&request, adminId)
+	if err != nil {
+		return seasonError(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusCreated, "season created", season)
+	return c.JSON(http.StatusCreated, responseData)
+}
+
+func (handler LeaderboardHandlerImpl) UpdateSeasonHandler(c echo.Context) error {
+	seasonId, err := strconv.Atoi(c.Param("seasonId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrSeasonNotFound.Error())
+	}
+
+	var request dto.SeasonRequest
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	season, err := handler.LeaderboardUsecase.UpdateSeasonUsecase(uint(seasonId), &request)
+	if err != nil {
+		return seasonError(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "season updated", season)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler LeaderboardHandlerImpl) DeleteSeasonHandler(c echo.Context) error {
+	seasonId, err := strconv.Atoi(c.Param("seasonId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrSeasonNotFound.Error())
+	}
+
+	if err := handler.LeaderboardUsecase.DeleteSeasonUsecase(uint(seasonId)); err != nil {
+		return seasonError(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "season deleted", nil)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler LeaderboardHandlerImpl) GetSeasonsHandler(c echo.Context) error {
+	page, limit, err := pagination(c)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	seasons, err := handler.LeaderboardUsecase.GetSeasonsUsecase(page, limit)
+	if err != nil {
+		return seasonError(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "data successfully retrieved", seasons)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler LeaderboardHandlerImpl) GetSeasonStandingsHandler(c echo.Context) error {
+	seasonId, err := strconv.Atoi(c.Param("seasonId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrSeasonNotFound.Error())
+	}
+	page, limit, err := pagination(c)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	standings, err := handler.LeaderboardUsecase.GetSeasonStandingsUsecase(uint(seasonId), page, limit, rankedUserId(c))
+	if err != nil {
+		return seasonError(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "data successfully retrieved", standings)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler LeaderboardHandlerImpl) GetActiveSeasonStandingsHandler(c echo.Context) error {
+	page, limit, err := pagination(c)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	standings, err := handler.LeaderboardUsecase.GetActiveSeasonStandingsUsecase(page, limit, rankedUserId(c))
+	if err != nil {
+		return seasonError(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "data successfully retrieved", standings)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func seasonError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, pkg.ErrSeasonNotFound):
+		return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, pkg.ErrSeasonInvalidDate), errors.Is(err, pkg.ErrSeasonRewardRank):
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, pkg.ErrSeasonOverlap), errors.Is(err, pkg.ErrSeasonEnded):
+		return helper.ErrorHandler(c, http.StatusConflict, err.Error())
+	default:
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+	}
+}
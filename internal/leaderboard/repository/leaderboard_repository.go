@@ -1,7 +1,28 @@
 package repository
 
-import user "github.com/sawalreverr/recything/internal/user"
+import (
+	"time"
+
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
+	"github.com/sawalreverr/recything/internal/leaderboard/entity"
+)
 
 type LeaderboardRepository interface {
-	GetLeaderboard() (*[]user.User, error)
+	GetBoard(filter dto.LeaderboardFilter, from int, to int) (*[]dto.RankedUser, error)
+	CountBoard(filter dto.LeaderboardFilter) (int64, error)
+	GetUserRank(filter dto.LeaderboardFilter, userId string) (*dto.RankedUser, error)
+
+	CreateSeason(season *entity.Season) error
+	FindSeasonById(seasonId uint) (*entity.Season, error)
+	FindSeasons(offset int, limit int) ([]entity.Season, int64, error)
+	FindOverlappingSeason(startAt time.Time, endAt time.Time, excludeId uint) (*entity.Season, error)
+	FindActiveSeason(now time.Time) (*entity.Season, error)
+	UpdateSeason(season *entity.Season) error
+	DeleteSeason(seasonId uint) error
+
+	FindEndedSeasonIds(now time.Time) ([]uint, error)
+	CloseSeason(seasonId uint, now time.Time) (bool, error)
+	GetStandings(seasonId uint, from int, to int) ([]entity.SeasonStanding, error)
+	CountStandings(seasonId uint) (int64, error)
+	GetUserStanding(seasonId uint, userId string) (*entity.SeasonStanding, error)
 }
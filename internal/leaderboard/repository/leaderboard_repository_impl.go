@@ -2,7 +2,9 @@ package repository
 
 import (
 	"github.com/sawalreverr/recything/internal/database"
-	user "github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
+	"github.com/sawalreverr/recything/internal/point"
+	"gorm.io/gorm"
 )
 
 type LeaderboardRepositoryImpl struct {
@@ -13,13 +15,77 @@ func NewLeaderboardRepository(db database.Database) *LeaderboardRepositoryImpl {
 	return &LeaderboardRepositoryImpl{DB: db}
 }
 
-func (repository *LeaderboardRepositoryImpl) GetLeaderboard() (*[]user.User, error) {
-	var users []user.User
+// GetBoard returns the users ranked from..to, both inclusive and starting at 1.
+func (repository *LeaderboardRepositoryImpl) GetBoard(filter dto.LeaderboardFilter, from int, to int) (*[]dto.RankedUser, error) {
+	var users []dto.RankedUser
 	if err := repository.DB.GetDB().
-		Order("point desc").
-		Limit(10).
-		Find(&users).Error; err != nil {
+		Table("(?) AS ranked", repository.rankedUsers(filter)).
+		Where("ranking BETWEEN ? AND ?", from, to).
+		Order("ranking asc").
+		Scan(&users).Error; err != nil {
 		return nil, err
 	}
 	return &users, nil
 }
+
+func (repository *LeaderboardRepositoryImpl) CountBoard(filter dto.LeaderboardFilter) (int64, error) {
+	var total int64
+	if err := repository.DB.GetDB().
+		Table("(?) AS ranked", repository.rankedUsers(filter)).
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (repository *LeaderboardRepositoryImpl) GetUserRank(filter dto.LeaderboardFilter, userId string) (*dto.RankedUser, error) {
+	var user dto.RankedUser
+	if err := repository.DB.GetDB().
+		Table("(?) AS ranked", repository.rankedUsers(filter)).
+		Where("id = ?", userId).
+		Take(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// rankedUsers sums the points every user earned in the window and numbers
+// them. Spending points on rewards, and getting them back when a redemption is
+// cancelled, does not count as earning, and neither do season prizes so
+// winning one season gives no head start in the next. Opening balances are
+// only counted on the all-time board, they are dated when the ledger was
+// backfilled rather than when the points were earned. Equal totals are ordered by who reached
+// the total first and then by user id, so a rank never depends on row order.
+func (repository *LeaderboardRepositoryImpl) rankedUsers(filter dto.LeaderboardFilter) *gorm.DB {
+	db := repository.DB.GetDB()
+
+	redemptions := db.Model(&point.Entry{}).Select("id").Where("source_type = ?", point.SourceRedemption)
+
+	query := db.Table("users").
+		Select(`users.id, users.name, users.picture_url, users.badge, users.address, users.province, users.city,
+			SUM(point_ledger.amount) AS point,
+			MAX(point_ledger.created_at) AS reached_at,
+			ROW_NUMBER() OVER (ORDER BY SUM(point_ledger.amount) DESC, MAX(point_ledger.created_at) ASC, users.id ASC) AS ranking`).
+		Joins("JOIN point_ledger ON point_ledger.user_id = users.id").
+		Where("users.deleted_at IS NULL").
+		Where("point_ledger.source_type NOT IN ?", []string{point.SourceRedemption, point.SourceSeasonBonus}).
+		Where("(point_ledger.reversal_of IS NULL OR point_ledger.reversal_of NOT IN (?))", redemptions)
+
+	if filter.Since != nil {
+		query = query.Where("point_ledger.created_at >= ?", *filter.Since).
+			Where("point_ledger.source_type <> ?", point.SourceOpeningBalance)
+	}
+	if filter.Until != nil {
+		query = query.Where("point_ledger.created_at < ?", *filter.Until)
+	}
+	if filter.Province != "" {
+		query = query.Where("users.province = ?", filter.Province)
+	}
+	if filter.City != "" {
+		query = query.Where("users.city = ?", filter.City)
+	}
+
+	return query.
+		Group("users.id, users.name, users.picture_url, users.badge, users.address, users.province, users.city").
+		Having("SUM(point_ledger.amount) > 0")
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
+	"github.com/sawalreverr/recything/internal/point"
+	user "github.com/sawalreverr/recything/internal/user"
+	"gorm.io/gorm"
+)
+
+func TestOpeningBalanceOnlyRanksAllTime(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewLeaderboardRepository(db)
+
+	now := time.Now()
+	// ids are at most 20 characters
+	userId := "LBU" + strconv.FormatInt(now.UnixNano(), 36)
+	if err := db.GetDB().Create(&user.User{ID: userId, Name: "test user", Gender: "-", BirthDate: now}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	// the ledger was backfilled just now, from points earned long before
+	if err := db.GetDB().Create(&point.Entry{
+		UserID:     userId,
+		Amount:     500,
+		SourceType: point.SourceOpeningBalance,
+		SourceID:   userId,
+	}).Error; err != nil {
+		t.Fatalf("seed ledger: %v", err)
+	}
+
+	if _, err := repository.GetUserRank(dto.LeaderboardFilter{}, userId); err != nil {
+		t.Errorf("all-time board: user not ranked: %v", err)
+	}
+
+	since := now.Add(-time.Hour)
+	ranked, err := repository.GetUserRank(dto.LeaderboardFilter{Since: &since}, userId)
+	if err == nil {
+		t.Errorf("windowed board ranks the opening balance as %d points", ranked.Point)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("windowed board: %v", err)
+	}
+}
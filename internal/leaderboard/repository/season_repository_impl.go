@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
+	"github.com/sawalreverr/recything/internal/leaderboard/entity"
+	"github.com/sawalreverr/recything/internal/point"
+	pointRepo "github.com/sawalreverr/recything/internal/point/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// standingBatch is how many standings are written per insert when a season
+// closes.
+const standingBatch = 500
+
+func (repository *LeaderboardRepositoryImpl) CreateSeason(season *entity.Season) error {
+	if err := repository.DB.GetDB().Create(season).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *LeaderboardRepositoryImpl) FindSeasonById(seasonId uint) (*entity.Season, error) {
+	var season entity.Season
+	if err := repository.DB.GetDB().
+		Preload("Rewards", func(db *gorm.DB) *gorm.DB { return db.Order("`rank` asc") }).
+		Where("id = ?", seasonId).
+		First(&season).Error; err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+func (repository *LeaderboardRepositoryImpl) FindSeasons(offset int, limit int) ([]entity.Season, int64, error) {
+	var seasons []entity.Season
+	var total int64
+
+	db := repository.DB.GetDB().Model(&entity.Season{})
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := db.
+		Preload("Rewards", func(db *gorm.DB) *gorm.DB { return db.Order("`rank` asc") }).
+		Order("start_at desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&seasons).Error; err != nil {
+		return nil, 0, err
+	}
+	return seasons, total, nil
+}
+
+// FindOverlappingSeason finds a season other than excludeId sharing any moment
+// with [startAt, endAt).
+func (repository *LeaderboardRepositoryImpl) FindOverlappingSeason(startAt time.Time, endAt time.Time, excludeId uint) (*entity.Season, error) {
+	var season entity.Season
+	if err := repository.DB.GetDB().
+		Where("id <> ? AND start_at < ? AND end_at > ?", excludeId, endAt, startAt).
+		First(&season).Error; err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+func (repository *LeaderboardRepositoryImpl) FindActiveSeason(now time.Time) (*entity.Season, error) {
+	var season entity.Season
+	if err := repository.DB.GetDB().
+		Preload("Rewards", func(db *gorm.DB) *gorm.DB { return db.Order("`rank` asc") }).
+		Where("start_at <= ? AND end_at > ? AND closed_at IS NULL", now, now).
+		First(&season).Error; err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// UpdateSeason saves the season and replaces its rewards.
+func (repository *LeaderboardRepositoryImpl) UpdateSeason(season *entity.Season) error {
+	return repository.DB.Transaction(func(tx database.Database) error {
+		if err := tx.GetDB().Where("season_id = ?", season.ID).Delete(&entity.SeasonReward{}).Error; err != nil {
+			return err
+		}
+		for i := range season.Rewards {
+			season.Rewards[i].ID = 0
+			season.Rewards[i].SeasonId = season.ID
+		}
+		return tx.GetDB().Session(&gorm.Session{FullSaveAssociations: true}).Save(season).Error
+	})
+}
+
+func (repository *LeaderboardRepositoryImpl) DeleteSeason(seasonId uint) error {
+	return repository.DB.Transaction(func(tx database.Database) error {
+		if err := tx.GetDB().Where("season_id = ?", seasonId).Delete(&entity.SeasonReward{}).Error; err != nil {
+			return err
+		}
+		return tx.GetDB().Delete(&entity.Season{}, seasonId).Error
+	})
+}
+
+func (repository *LeaderboardRepositoryImpl) FindEndedSeasonIds(now time.Time) ([]uint, error) {
+	var ids []uint
+	if err := repository.DB.GetDB().Model(&entity.Season{}).
+		Where("end_at <= ? AND closed_at IS NULL", now).
+		Order("end_at asc").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CloseSeason freezes the standings of an ended season and pays its rewards in
+// one transaction. The season row stays locked meanwhile, so a season is
+// closed once even when several schedulers run; it reports false when the
+// season was closed already.
+func (repository *LeaderboardRepositoryImpl) CloseSeason(seasonId uint, now time.Time) (bool, error) {
+	closed := false
+
+	err := repository.DB.Transaction(func(tx database.Database) error {
+		var season entity.Season
+		if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Rewards").
+			Where("id = ?", seasonId).
+			First(&season).Error; err != nil {
+			return err
+		}
+		if season.ClosedAt != nil {
+			return nil
+		}
+
+		txRepository := &LeaderboardRepositoryImpl{DB: tx}
+		users, err := txRepository.GetBoard(dto.LeaderboardFilter{Since: &season.StartAt, Until: &season.EndAt}, 1, math.MaxInt32)
+		if err != nil {
+			return err
+		}
+
+		ledger := pointRepo.NewPointRepository(tx)
+		standings := make([]entity.SeasonStanding, 0, len(*users))
+		for _, user := range *users {
+			standing := entity.SeasonStanding{
+				SeasonId:   season.ID,
+				Rank:       user.Ranking,
+				UserId:     user.ID,
+				Name:       user.Name,
+				PictureURL: user.PictureURL,
+				Badge:      user.Badge,
+				Province:   user.Province,
+				City:       user.City,
+				Point:      user.Point,
+				ReachedAt:  user.ReachedAt,
+				BonusPoint: season.BonusFor(user.Ranking),
+			}
+
+			if standing.BonusPoint > 0 {
+				entry, err := ledger.Append(point.Entry{
+					UserID:      user.ID,
+					Amount:      standing.BonusPoint,
+					BasePoint:   standing.BonusPoint,
+					SourceType:  point.SourceSeasonBonus,
+					SourceID:    fmt.Sprintf("%d:%s", season.ID, user.ID),
+					Description: fmt.Sprintf("Rank %d in %s", user.Ranking, season.Name),
+				})
+				if err != nil {
+					return err
+				}
+				standing.PointEntryId = &entry.ID
+			}
+
+			standings = append(standings, standing)
+		}
+
+		if len(standings) > 0 {
+			if err := tx.GetDB().CreateInBatches(&standings, standingBatch).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.GetDB().Model(&entity.Season{}).Where("id = ?", season.ID).Update("closed_at", now).Error; err != nil {
+			return err
+		}
+
+		closed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return closed, nil
+}
+
+func (repository *LeaderboardRepositoryImpl) GetStandings(seasonId uint, from int, to int) ([]entity.SeasonStanding, error) {
+	var standings []entity.SeasonStanding
+	if err := repository.DB.GetDB().
+		Where("season_id = ? AND `rank` BETWEEN ? AND ?", seasonId, from, to).
+		Order("`rank` asc").
+		Find(&standings).Error; err != nil {
+		return nil, err
+	}
+	return standings, nil
+}
+
+func (repository *LeaderboardRepositoryImpl) CountStandings(seasonId uint) (int64, error) {
+	var total int64
+	if err := repository.DB.GetDB().Model(&entity.SeasonStanding{}).Where("season_id = ?", seasonId).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (repository *LeaderboardRepositoryImpl) GetUserStanding(seasonId uint, userId string) (*entity.SeasonStanding, error) {
+	var standing entity.SeasonStanding
+	if err := repository.DB.GetDB().Where("season_id = ? AND user_id = ?", seasonId, userId).First(&standing).Error; err != nil {
+		return nil, err
+	}
+	return &standing, nil
+}
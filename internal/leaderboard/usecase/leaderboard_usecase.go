@@ -1,7 +1,19 @@
 package usecase
 
-import "github.com/sawalreverr/recything/internal/leaderboard/dto"
+import (
+	"time"
+
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
+)
 
 type LeaderboardUsecase interface {
-	GetLeaderboardUsecase() (*dto.LeaderboardResponse, error)
+	GetLeaderboardUsecase(request dto.LeaderboardRequest, userId string) (*dto.LeaderboardResponse, error)
+
+	CreateSeasonUsecase(request *dto.SeasonRequest, adminId string) (*dto.SeasonResponse, error)
+	UpdateSeasonUsecase(seasonId uint, request *dto.SeasonRequest) (*dto.SeasonResponse, error)
+	DeleteSeasonUsecase(seasonId uint) error
+	GetSeasonsUsecase(page int, limit int) (*dto.SeasonListResponse, error)
+	GetSeasonStandingsUsecase(seasonId uint, page int, limit int, userId string) (*dto.SeasonStandingsResponse, error)
+	GetActiveSeasonStandingsUsecase(page int, limit int, userId string) (*dto.SeasonStandingsResponse, error)
+	CloseEndedSeasonsUsecase(now time.Time) (int, error)
 }
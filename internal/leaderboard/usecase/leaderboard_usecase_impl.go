@@ -1,10 +1,26 @@
 package usecase
 
 import (
+	"errors"
+	"time"
+
 	"github.com/sawalreverr/recything/internal/leaderboard/dto"
 	"github.com/sawalreverr/recything/internal/leaderboard/repository"
+	"github.com/sawalreverr/recything/internal/streak"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+)
+
+// leaderboard windows
+const (
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodAllTime = "all_time"
 )
 
+// NeighbourRange is how many ranks above and below the caller are shown.
+const NeighbourRange = 2
+
 type LeaderboardUsecaseImpl struct {
 	LeaderboardRepository repository.LeaderboardRepository
 }
@@ -13,21 +29,102 @@ func NewLeaderboardUsecase(leaderboardRepository repository.LeaderboardRepositor
 	return &LeaderboardUsecaseImpl{LeaderboardRepository: leaderboardRepository}
 }
 
-func (usecase *LeaderboardUsecaseImpl) GetLeaderboardUsecase() (*dto.LeaderboardResponse, error) {
-	users, err := usecase.LeaderboardRepository.GetLeaderboard()
+// GetLeaderboardUsecase ranks users by the points they earned in the window.
+// The caller's own rank is added when userId is not empty.
+func (usecase *LeaderboardUsecaseImpl) GetLeaderboardUsecase(request dto.LeaderboardRequest, userId string) (*dto.LeaderboardResponse, error) {
+	if request.Period == "" {
+		request.Period = PeriodAllTime
+	}
+	since, err := windowStart(request.Period, request.Province, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := dto.LeaderboardFilter{Since: since, Province: request.Province, City: request.City}
+
+	total, err := usecase.LeaderboardRepository.CountBoard(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	from := (request.Page-1)*request.Limit + 1
+	users, err := usecase.LeaderboardRepository.GetBoard(filter, from, from+request.Limit-1)
 	if err != nil {
 		return nil, err
 	}
-	var dataLeaderboard []*dto.DataLeaderboard
-	for _, user := range *users {
-		dataLeaderboard = append(dataLeaderboard, &dto.DataLeaderboard{
+
+	response := dto.LeaderboardResponse{
+		Period:          request.Period,
+		Since:           since,
+		Province:        request.Province,
+		City:            request.City,
+		Total:           total,
+		Page:            request.Page,
+		Limit:           request.Limit,
+		DataLeaderboard: dataLeaderboard(*users),
+		Neighbours:      []*dto.DataLeaderboard{},
+	}
+
+	if userId == "" {
+		return &response, nil
+	}
+
+	currentUser, err := usecase.LeaderboardRepository.GetUserRank(filter, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &response, nil
+		}
+		return nil, err
+	}
+
+	neighbours, err := usecase.LeaderboardRepository.GetBoard(filter, max(currentUser.Ranking-NeighbourRange, 1), currentUser.Ranking+NeighbourRange)
+	if err != nil {
+		return nil, err
+	}
+
+	response.CurrentUser = dataLeaderboard([]dto.RankedUser{*currentUser})[0]
+	response.Neighbours = dataLeaderboard(*neighbours)
+
+	return &response, nil
+}
+
+// windowStart is the first moment of the running week or month. Weeks start
+// on Monday. Regional boards follow the time zone of their province, the
+// national board follows WIB.
+func windowStart(period string, province string, now time.Time) (*time.Time, error) {
+	local := now.In(streak.Zone(province))
+	year, month, day := local.Date()
+
+	var since time.Time
+	switch period {
+	case PeriodAllTime:
+		return nil, nil
+	case PeriodMonthly:
+		since = time.Date(year, month, 1, 0, 0, 0, 0, local.Location())
+	case PeriodWeekly:
+		sinceMonday := (int(local.Weekday()) + 6) % 7
+		since = time.Date(year, month, day-sinceMonday, 0, 0, 0, 0, local.Location())
+	default:
+		return nil, pkg.ErrLeaderboardPeriod
+	}
+
+	return &since, nil
+}
+
+func dataLeaderboard(users []dto.RankedUser) []*dto.DataLeaderboard {
+	data := []*dto.DataLeaderboard{}
+	for _, user := range users {
+		data = append(data, &dto.DataLeaderboard{
+			Rank:       user.Ranking,
 			Id:         user.ID,
 			Name:       user.Name,
 			PictureURL: user.PictureURL,
-			Point:      int(user.Point),
+			Point:      user.Point,
 			Badge:      user.Badge,
 			Address:    user.Address,
+			Province:   user.Province,
+			City:       user.City,
 		})
 	}
-	return &dto.LeaderboardResponse{DataLeaderboard: dataLeaderboard}, nil
+	return data
 }
@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/leaderboard/dto"
+	"github.com/sawalreverr/recything/internal/leaderboard/entity"
+	"github.com/sawalreverr/recything/internal/streak"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+)
+
+func (usecase *LeaderboardUsecaseImpl) CreateSeasonUsecase(request *dto.SeasonRequest, adminId string) (*dto.SeasonResponse, error) {
+	season := entity.Season{AdminId: adminId}
+	if err := usecase.applySeasonRequest(&season, request); err != nil {
+		return nil, err
+	}
+
+	if err := usecase.LeaderboardRepository.CreateSeason(&season); err != nil {
+		return nil, err
+	}
+
+	response := seasonResponse(season, time.Now())
+	return &response, nil
+}
+
+// UpdateSeasonUsecase changes a season until it ends. Afterwards its results
+// are final.
+func (usecase *LeaderboardUsecaseImpl) UpdateSeasonUsecase(seasonId uint, request *dto.SeasonRequest) (*dto.SeasonResponse, error) {
+	season, err := usecase.findSeason(seasonId)
+	if err != nil {
+		return nil, err
+	}
+
+	if status := season.Status(time.Now()); status == entity.SeasonEnded || status == entity.SeasonClosed {
+		return nil, pkg.ErrSeasonEnded
+	}
+
+	if err := usecase.applySeasonRequest(season, request); err != nil {
+		return nil, err
+	}
+
+	if err := usecase.LeaderboardRepository.UpdateSeason(season); err != nil {
+		return nil, err
+	}
+
+	response := seasonResponse(*season, time.Now())
+	return &response, nil
+}
+
+func (usecase *LeaderboardUsecaseImpl) DeleteSeasonUsecase(seasonId uint) error {
+	season, err := usecase.findSeason(seasonId)
+	if err != nil {
+		return err
+	}
+
+	if status := season.Status(time.Now()); status == entity.SeasonEnded || status == entity.SeasonClosed {
+		return pkg.ErrSeasonEnded
+	}
+
+	return usecase.LeaderboardRepository.DeleteSeason(seasonId)
+}
+
+func (usecase *LeaderboardUsecaseImpl) GetSeasonsUsecase(page int, limit int) (*dto.SeasonListResponse, error) {
+	seasons, total, err := usecase.LeaderboardRepository.FindSeasons((page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := dto.SeasonListResponse{Total: total, Page: page, Limit: limit, Seasons: []dto.SeasonResponse{}}
+	for _, season := range seasons {
+		response.Seasons = append(response.Seasons, seasonResponse(season, now))
+	}
+	return &response, nil
+}
+
+// GetSeasonStandingsUsecase reads the frozen standings of a closed season.
+// Before that the standings are computed live and may still change.
+func (usecase *LeaderboardUsecaseImpl) GetSeasonStandingsUsecase(seasonId uint, page int, limit int, userId string) (*dto.SeasonStandingsResponse, error) {
+	season, err := usecase.findSeason(seasonId)
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.seasonStandings(season, page, limit, userId)
+}
+
+func (usecase *LeaderboardUsecaseImpl) GetActiveSeasonStandingsUsecase(page int, limit int, userId string) (*dto.SeasonStandingsResponse, error) {
+	season, err := usecase.LeaderboardRepository.FindActiveSeason(time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrSeasonNotFound
+		}
+		return nil, err
+	}
+
+	return usecase.seasonStandings(season, page, limit, userId)
+}
+
+// CloseEndedSeasonsUsecase closes every season that ended by now and returns
+// how many it closed.
+func (usecase *LeaderboardUsecaseImpl) CloseEndedSeasonsUsecase(now time.Time) (int, error) {
+	seasonIds, err := usecase.LeaderboardRepository.FindEndedSeasonIds(now)
+	if err != nil {
+		return 0, err
+	}
+
+	closedSeasons := 0
+	for _, seasonId := range seasonIds {
+		closed, err := usecase.LeaderboardRepository.CloseSeason(seasonId, now)
+		if err != nil {
+			return closedSeasons, err
+		}
+		if closed {
+			closedSeasons++
+		}
+	}
+	return closedSeasons, nil
+}
+
+func (usecase *LeaderboardUsecaseImpl) findSeason(seasonId uint) (*entity.Season, error) {
+	season, err := usecase.LeaderboardRepository.FindSeasonById(seasonId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrSeasonNotFound
+		}
+		return nil, err
+	}
+	return season, nil
+}
+
+// applySeasonRequest validates the request and copies it onto the season.
+// Seasons may not overlap, so at most one of them is active at a time.
+func (usecase *LeaderboardUsecaseImpl) applySeasonRequest(season *entity.Season, request *dto.SeasonRequest) error {
+	startsOn, err := time.ParseInLocation("2006-01-02", request.StartsOn, streak.WIB)
+	if err != nil {
+		return pkg.ErrSeasonInvalidDate
+	}
+	endsOn, err := time.ParseInLocation("2006-01-02", request.EndsOn, streak.WIB)
+	if err != nil || endsOn.Before(startsOn) {
+		return pkg.ErrSeasonInvalidDate
+	}
+	endAt := endsOn.AddDate(0, 0, 1)
+
+	ranks := make(map[int]bool, len(request.Rewards))
+	rewards := make([]entity.SeasonReward, 0, len(request.Rewards))
+	for _, reward := range request.Rewards {
+		if ranks[reward.Rank] {
+			return pkg.ErrSeasonRewardRank
+		}
+		ranks[reward.Rank] = true
+		rewards = append(rewards, entity.SeasonReward{Rank: reward.Rank, BonusPoint: reward.BonusPoint})
+	}
+
+	_, err = usecase.LeaderboardRepository.FindOverlappingSeason(startsOn, endAt, season.ID)
+	if err == nil {
+		return pkg.ErrSeasonOverlap
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	season.Name = request.Name
+	season.Description = request.Description
+	season.StartAt = startsOn
+	season.EndAt = endAt
+	season.Rewards = rewards
+	return nil
+}
+
+func (usecase *LeaderboardUsecaseImpl) seasonStandings(season *entity.Season, page int, limit int, userId string) (*dto.SeasonStandingsResponse, error) {
+	from, to := (page-1)*limit+1, page*limit
+
+	response := dto.SeasonStandingsResponse{
+		Season:    seasonResponse(*season, time.Now()),
+		Final:     season.ClosedAt != nil,
+		Page:      page,
+		Limit:     limit,
+		Standings: []*dto.SeasonStanding{},
+	}
+
+	if response.Final {
+		total, err := usecase.LeaderboardRepository.CountStandings(season.ID)
+		if err != nil {
+			return nil, err
+		}
+		standings, err := usecase.LeaderboardRepository.GetStandings(season.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Total = total
+		for _, standing := range standings {
+			response.Standings = append(response.Standings, finalStanding(standing))
+		}
+
+		if userId != "" {
+			standing, err := usecase.LeaderboardRepository.GetUserStanding(season.ID, userId)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if standing != nil {
+				response.CurrentUser = finalStanding(*standing)
+			}
+		}
+		return &response, nil
+	}
+
+	filter := dto.LeaderboardFilter{Since: &season.StartAt, Until: &season.EndAt}
+	total, err := usecase.LeaderboardRepository.CountBoard(filter)
+	if err != nil {
+		return nil, err
+	}
+	users, err := usecase.LeaderboardRepository.GetBoard(filter, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Total = total
+	for _, user := range *users {
+		response.Standings = append(response.Standings, liveStanding(season, user))
+	}
+
+	if userId != "" {
+		user, err := usecase.LeaderboardRepository.GetUserRank(filter, userId)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if user != nil {
+			response.CurrentUser = liveStanding(season, *user)
+		}
+	}
+	return &response, nil
+}
+
+func liveStanding(season *entity.Season, user dto.RankedUser) *dto.SeasonStanding {
+	return &dto.SeasonStanding{
+		DataLeaderboard: *dataLeaderboard([]dto.RankedUser{user})[0],
+		BonusPoint:      season.BonusFor(user.Ranking),
+	}
+}
+
+func finalStanding(standing entity.SeasonStanding) *dto.SeasonStanding {
+	return &dto.SeasonStanding{
+		DataLeaderboard: dto.DataLeaderboard{
+			Rank:       standing.Rank,
+			Id:         standing.UserId,
+			Name:       standing.Name,
+			PictureURL: standing.PictureURL,
+			Point:      standing.Point,
+			Badge:      standing.Badge,
+			Province:   standing.Province,
+			City:       standing.City,
+		},
+		BonusPoint: standing.BonusPoint,
+	}
+}
+
+func seasonResponse(season entity.Season, now time.Time) dto.SeasonResponse {
+	response := dto.SeasonResponse{
+		Id:          season.ID,
+		Name:        season.Name,
+		Description: season.Description,
+		StartAt:     season.StartAt,
+		EndAt:       season.EndAt,
+		Status:      season.Status(now),
+		ClosedAt:    season.ClosedAt,
+		Rewards:     []dto.SeasonReward{},
+	}
+	for _, reward := range season.Rewards {
+		response.Rewards = append(response.Rewards, dto.SeasonReward{Rank: reward.Rank, BonusPoint: reward.BonusPoint})
+	}
+	return response
+}
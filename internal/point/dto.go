@@ -0,0 +1,56 @@
+package point
+
+import "time"
+
+type ReverseInput struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// AdjustInput credits a user when Amount is positive and debits them when it
+// is negative.
+type AdjustInput struct {
+	Amount int    `json:"amount" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type EntryResponse struct {
+	ID           uint      `json:"id"`
+	UserID       string    `json:"user_id"`
+	Amount       int       `json:"amount"`
+	BasePoint    int       `json:"base_point"`
+	BonusPercent int       `json:"bonus_percent"`
+	BonusPoint   int       `json:"bonus_point"`
+	BalanceAfter int       `json:"balance_after"`
+	SourceType   string    `json:"source_type"`
+	SourceID     string    `json:"source_id"`
+	Description  string    `json:"description"`
+	ReversalOf   *uint     `json:"reversal_of"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type HistoryResponse struct {
+	UserID  string          `json:"user_id"`
+	Balance int             `json:"balance"`
+	Total   int64           `json:"total"`
+	Page    int             `json:"page"`
+	Limit   int             `json:"limit"`
+	Entries []EntryResponse `json:"entries"`
+}
+
+type AdjustmentResponse struct {
+	EntryResponse
+	Badge string `json:"badge"`
+}
+
+type AdjustmentListResponse struct {
+	Total   int64           `json:"total"`
+	Page    int             `json:"page"`
+	Limit   int             `json:"limit"`
+	Entries []EntryResponse `json:"entries"`
+}
+
+type ReconcileReport struct {
+	Drifts []Drift `json:"drifts"`
+	Fixed  int     `json:"fixed"`
+}
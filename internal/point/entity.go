@@ -0,0 +1,100 @@
+package point
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// sources of ledger entries
+const (
+	SourceOpeningBalance = "opening_balance"
+	SourceTaskApproval   = "task_approval"
+	SourceAdjustment     = "admin_adjustment"
+	SourceReversal       = "reversal"
+	SourceRedemption     = "reward_redemption"
+	SourceStreakBonus    = "streak_bonus"
+	SourceSeasonBonus    = "season_bonus"
+)
+
+// struct
+
+// Entry is one change to a user's points. Entries are only ever appended, a
+// mistake is undone by a reversal entry instead of editing the original. The
+// point column on users caches the sum of a user's entries.
+type Entry struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID string `json:"user_id" gorm:"index;type:varchar(20)"`
+	Amount int    `json:"amount"`
+
+	// BasePoint, BonusPercent and BonusPoint break a task approval down into
+	// the task reward and the badge bonus added on top of it.
+	BasePoint    int `json:"base_point"`
+	BonusPercent int `json:"bonus_percent"`
+	BonusPoint   int `json:"bonus_point"`
+
+	BalanceAfter int    `json:"balance_after"`
+	SourceType   string `json:"source_type" gorm:"type:varchar(30);uniqueIndex:idx_point_source"`
+	SourceID     string `json:"source_id" gorm:"type:varchar(50);uniqueIndex:idx_point_source"`
+	Description  string `json:"description"`
+	ReversalOf   *uint  `json:"reversal_of"`
+	CreatedBy    string `json:"created_by"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+func (Entry) TableName() string {
+	return "point_ledger"
+}
+
+// Earned limits a ledger query to the entries that earn points, which levels
+// are based on. Spending points on rewards, and getting them back when a
+// redemption is cancelled, is not earning.
+func Earned(db *gorm.DB) *gorm.DB {
+	redemptions := db.Session(&gorm.Session{NewDB: true}).
+		Model(&Entry{}).Select("id").Where("source_type = ?", SourceRedemption)
+
+	return db.Where("point_ledger.source_type <> ?", SourceRedemption).
+		Where("(point_ledger.reversal_of IS NULL OR point_ledger.reversal_of NOT IN (?))", redemptions)
+}
+
+// Drift is a user whose cached point balance no longer matches the ledger.
+type Drift struct {
+	UserID string `json:"user_id"`
+	Cached int    `json:"cached"`
+	Ledger int    `json:"ledger"`
+}
+
+// interface
+type PointRepository interface {
+	Transaction(fn func(repo PointRepository) error) error
+
+	Append(entry Entry) (*Entry, error)
+	FindByID(entryID uint) (*Entry, error)
+	FindBySource(sourceType, sourceID string) (*Entry, error)
+	FindByUser(userID string, page, limit int) (*[]Entry, int64, error)
+	FindBySourceType(sourceType, userID string, page, limit int) (*[]Entry, int64, error)
+	Balance(userID string) (int, error)
+	Earned(userID string) (int, error)
+	Badge(userID string) (string, error)
+
+	FindDrifts() (*[]Drift, error)
+	SetCachedBalance(userID string, balance int) error
+}
+
+type PointUsecase interface {
+	GetHistory(userID string, page, limit int) (*HistoryResponse, error)
+	Reverse(entryID uint, input ReverseInput, adminID string) (*EntryResponse, error)
+	Adjust(userID string, input AdjustInput, adminID string) (*AdjustmentResponse, error)
+	GetAdjustments(userID string, page, limit int) (*AdjustmentListResponse, error)
+	Reconcile(fix bool) (*ReconcileReport, error)
+}
+
+type PointHandler interface {
+	GetUserHistory(c echo.Context) error
+	ReverseEntry(c echo.Context) error
+	AdjustUserPoint(c echo.Context) error
+	GetUserAdjustments(c echo.Context) error
+	GetAllAdjustments(c echo.Context) error
+}
@@ -0,0 +1,128 @@
+package point
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sawalreverr/recything/internal/helper"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+type pointHandler struct {
+	pointUsecase pnt.PointUsecase
+}
+
+func NewPointHandler(uc pnt.PointUsecase) pnt.PointHandler {
+	return &pointHandler{pointUsecase: uc}
+}
+
+func (h *pointHandler) GetUserHistory(c echo.Context) error {
+	userID := c.Param("userId")
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	history, err := h.pointUsecase.GetHistory(userID, page, limit)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", history)
+}
+
+func (h *pointHandler) ReverseEntry(c echo.Context) error {
+	var request pnt.ReverseInput
+
+	adminID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	entryID, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrPointEntryNotFound.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	reversal, err := h.pointUsecase.Reverse(uint(entryID), request, adminID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrPointEntryNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, pkg.ErrPointEntryReversed) || errors.Is(err, pkg.ErrPointEntryNotReversible) || errors.Is(err, pkg.ErrPointBalanceNegative) {
+			return helper.ErrorHandler(c, http.StatusConflict, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusCreated, "point entry reversed!", reversal)
+}
+
+func (h *pointHandler) AdjustUserPoint(c echo.Context) error {
+	var request pnt.AdjustInput
+
+	adminID := c.Get("user").(*helper.JwtCustomClaims).UserID
+	userID := c.Param("userId")
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	adjustment, err := h.pointUsecase.Adjust(userID, request, adminID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrUserNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, pkg.ErrPointBalanceNegative) {
+			return helper.ErrorHandler(c, http.StatusConflict, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusCreated, "point adjusted!", adjustment)
+}
+
+func (h *pointHandler) GetUserAdjustments(c echo.Context) error {
+	return h.adjustments(c, c.Param("userId"))
+}
+
+func (h *pointHandler) GetAllAdjustments(c echo.Context) error {
+	return h.adjustments(c, "")
+}
+
+func (h *pointHandler) adjustments(c echo.Context, userID string) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	adjustments, err := h.pointUsecase.GetAdjustments(userID, page, limit)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", adjustments)
+}
@@ -0,0 +1,246 @@
+package point
+
+import (
+	"errors"
+
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	"github.com/sawalreverr/recything/internal/database"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pointRepository struct {
+	DB database.Database
+}
+
+func NewPointRepository(db database.Database) pnt.PointRepository {
+	return &pointRepository{DB: db}
+}
+
+func (r *pointRepository) Transaction(fn func(repo pnt.PointRepository) error) error {
+	return r.DB.Transaction(func(tx database.Database) error {
+		return fn(&pointRepository{DB: tx})
+	})
+}
+
+// Append adds an entry to the ledger and refreshes the cached balance and
+// badge of its user, the badge from the points they earned so redeeming a
+// reward never costs a level. The user row stays locked until the surrounding
+// transaction ends, so entries of one user are written one at a time.
+func (r *pointRepository) Append(entry pnt.Entry) (*pnt.Entry, error) {
+	err := r.DB.Transaction(func(tx database.Database) error {
+		var owner user.User
+		if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "achievement_id").
+			Where("id = ?", entry.UserID).
+			First(&owner).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ErrUserNotFound
+			}
+			return err
+		}
+
+		var existing int64
+		if err := tx.GetDB().Model(&pnt.Entry{}).
+			Where("source_type = ? AND source_id = ?", entry.SourceType, entry.SourceID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return pkg.ErrPointEntryExists
+		}
+
+		balance, err := sumBalance(tx.GetDB(), entry.UserID)
+		if err != nil {
+			return err
+		}
+
+		entry.BalanceAfter = balance + entry.Amount
+		if entry.BalanceAfter < 0 {
+			return pkg.ErrPointBalanceNegative
+		}
+
+		if err := tx.GetDB().Create(&entry).Error; err != nil {
+			return err
+		}
+
+		if err := tx.GetDB().Model(&user.User{}).
+			Where("id = ?", entry.UserID).
+			Update("point", entry.BalanceAfter).Error; err != nil {
+			return err
+		}
+
+		earned, err := sumEarned(tx.GetDB(), entry.UserID)
+		if err != nil {
+			return err
+		}
+
+		return refreshBadge(tx.GetDB(), owner, earned)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *pointRepository) FindByID(entryID uint) (*pnt.Entry, error) {
+	var entry pnt.Entry
+	if err := r.DB.GetDB().Where("id = ?", entryID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *pointRepository) FindBySource(sourceType, sourceID string) (*pnt.Entry, error) {
+	var entry pnt.Entry
+	if err := r.DB.GetDB().Where("source_type = ? AND source_id = ?", sourceType, sourceID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *pointRepository) FindByUser(userID string, page, limit int) (*[]pnt.Entry, int64, error) {
+	var entries []pnt.Entry
+	var total int64
+
+	db := r.DB.GetDB().Model(&pnt.Entry{}).Where("user_id = ?", userID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := db.Order("id desc").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &entries, total, nil
+}
+
+// FindBySourceType lists entries of one source type, newest first, across
+// all users when userID is empty.
+func (r *pointRepository) FindBySourceType(sourceType, userID string, page, limit int) (*[]pnt.Entry, int64, error) {
+	var entries []pnt.Entry
+	var total int64
+
+	db := r.DB.GetDB().Model(&pnt.Entry{}).Where("source_type = ?", sourceType)
+	if userID != "" {
+		db = db.Where("user_id = ?", userID)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := db.Order("id desc").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &entries, total, nil
+}
+
+func (r *pointRepository) Balance(userID string) (int, error) {
+	return sumBalance(r.DB.GetDB(), userID)
+}
+
+// Earned sums the entries of the user that earn points, the basis of their
+// level.
+func (r *pointRepository) Earned(userID string) (int, error) {
+	return sumEarned(r.DB.GetDB(), userID)
+}
+
+func (r *pointRepository) Badge(userID string) (string, error) {
+	var found user.User
+	if err := r.DB.GetDB().Select("badge").Where("id = ?", userID).First(&found).Error; err != nil {
+		return "", err
+	}
+
+	return found.Badge, nil
+}
+
+// FindDrifts lists users whose cached point differs from the sum of their
+// ledger entries.
+func (r *pointRepository) FindDrifts() (*[]pnt.Drift, error) {
+	var drifts []pnt.Drift
+	if err := r.DB.GetDB().Table("users").
+		Select("users.id AS user_id, users.point AS cached, COALESCE(SUM(point_ledger.amount), 0) AS ledger").
+		Joins("LEFT JOIN point_ledger ON point_ledger.user_id = users.id").
+		Where("users.deleted_at IS NULL").
+		Group("users.id, users.point").
+		Having("users.point <> COALESCE(SUM(point_ledger.amount), 0)").
+		Scan(&drifts).Error; err != nil {
+		return nil, err
+	}
+
+	return &drifts, nil
+}
+
+func (r *pointRepository) SetCachedBalance(userID string, balance int) error {
+	if err := r.DB.GetDB().Model(&user.User{}).Where("id = ?", userID).Update("point", balance).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// refreshBadge gives the user the highest achievement their earned points
+// reach and records the change when it differs from the one they held. The
+// achievement is left alone when none is reached.
+func refreshBadge(db *gorm.DB, owner user.User, earned int) error {
+	var reached achievement.Achievement
+	err := db.Scopes(achievement.ReachedBy(earned)).Order("target_point desc").First(&reached).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := db.Model(&user.User{}).Where("id = ?", owner.ID).Updates(map[string]interface{}{
+		"achievement_id": reached.ID,
+		"badge":          reached.BadgeUrlUser,
+	}).Error; err != nil {
+		return err
+	}
+
+	if reached.ID == owner.AchievementID {
+		return nil
+	}
+	return db.Create(&achievement.UserLevelChange{
+		UserID:            owner.ID,
+		FromAchievementID: owner.AchievementID,
+		ToAchievementID:   reached.ID,
+		Point:             earned,
+		Cause:             achievement.LevelChangePoint,
+	}).Error
+}
+
+func sumEarned(db *gorm.DB, userID string) (int, error) {
+	var earned int
+	if err := db.Model(&pnt.Entry{}).
+		Scopes(pnt.Earned).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).
+		Scan(&earned).Error; err != nil {
+		return 0, err
+	}
+
+	return earned, nil
+}
+
+func sumBalance(db *gorm.DB, userID string) (int, error) {
+	var balance int
+	if err := db.Model(&pnt.Entry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,175 @@
+package point
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/google/uuid"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+)
+
+type pointUsecase struct {
+	pointRepository pnt.PointRepository
+}
+
+func NewPointUsecase(repo pnt.PointRepository) pnt.PointUsecase {
+	return &pointUsecase{pointRepository: repo}
+}
+
+func (uc *pointUsecase) GetHistory(userID string, page, limit int) (*pnt.HistoryResponse, error) {
+	entries, total, err := uc.pointRepository.FindByUser(userID, page, limit)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	balance, err := uc.pointRepository.Balance(userID)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := pnt.HistoryResponse{
+		UserID:  userID,
+		Balance: balance,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Entries: []pnt.EntryResponse{},
+	}
+	for _, entry := range *entries {
+		response.Entries = append(response.Entries, entryResponse(entry))
+	}
+
+	return &response, nil
+}
+
+// Reverse cancels an entry by appending the opposite amount. Every entry can
+// be reversed once and reversals themselves cannot be reversed.
+func (uc *pointUsecase) Reverse(entryID uint, input pnt.ReverseInput, adminID string) (*pnt.EntryResponse, error) {
+	original, err := uc.pointRepository.FindByID(entryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrPointEntryNotFound
+		}
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	if original.SourceType == pnt.SourceReversal {
+		return nil, pkg.ErrPointEntryNotReversible
+	}
+
+	reversal, err := uc.pointRepository.Append(pnt.Entry{
+		UserID:      original.UserID,
+		Amount:      -original.Amount,
+		SourceType:  pnt.SourceReversal,
+		SourceID:    strconv.FormatUint(uint64(original.ID), 10),
+		Description: input.Reason,
+		ReversalOf:  &original.ID,
+		CreatedBy:   adminID,
+	})
+	if err != nil {
+		if errors.Is(err, pkg.ErrPointEntryExists) {
+			return nil, pkg.ErrPointEntryReversed
+		}
+		if errors.Is(err, pkg.ErrPointBalanceNegative) {
+			return nil, err
+		}
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := entryResponse(*reversal)
+	return &response, nil
+}
+
+// Adjust credits or debits a user by hand. The reason and the admin are kept
+// on the ledger entry, and the badge follows the new balance.
+func (uc *pointUsecase) Adjust(userID string, input pnt.AdjustInput, adminID string) (*pnt.AdjustmentResponse, error) {
+	entry, err := uc.pointRepository.Append(pnt.Entry{
+		UserID:      userID,
+		Amount:      input.Amount,
+		BasePoint:   input.Amount,
+		SourceType:  pnt.SourceAdjustment,
+		SourceID:    uuid.NewString(),
+		Description: input.Reason,
+		CreatedBy:   adminID,
+	})
+	if err != nil {
+		if errors.Is(err, pkg.ErrUserNotFound) || errors.Is(err, pkg.ErrPointBalanceNegative) {
+			return nil, err
+		}
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	badge, err := uc.pointRepository.Badge(userID)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	return &pnt.AdjustmentResponse{EntryResponse: entryResponse(*entry), Badge: badge}, nil
+}
+
+// GetAdjustments lists manual adjustments of one user, or of every user when
+// userID is empty.
+func (uc *pointUsecase) GetAdjustments(userID string, page, limit int) (*pnt.AdjustmentListResponse, error) {
+	entries, total, err := uc.pointRepository.FindBySourceType(pnt.SourceAdjustment, userID, page, limit)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := pnt.AdjustmentListResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Entries: []pnt.EntryResponse{},
+	}
+	for _, entry := range *entries {
+		response.Entries = append(response.Entries, entryResponse(entry))
+	}
+
+	return &response, nil
+}
+
+// Reconcile reports users whose cached balance drifted from their ledger and,
+// when fix is set, resets the cache to the ledger sum.
+func (uc *pointUsecase) Reconcile(fix bool) (*pnt.ReconcileReport, error) {
+	drifts, err := uc.pointRepository.FindDrifts()
+	if err != nil {
+		return nil, err
+	}
+
+	report := pnt.ReconcileReport{Drifts: *drifts}
+	if !fix {
+		return &report, nil
+	}
+
+	for _, drift := range *drifts {
+		if drift.Ledger < 0 {
+			continue
+		}
+		if err := uc.pointRepository.SetCachedBalance(drift.UserID, drift.Ledger); err != nil {
+			return &report, err
+		}
+		report.Fixed++
+	}
+
+	return &report, nil
+}
+
+func entryResponse(entry pnt.Entry) pnt.EntryResponse {
+	return pnt.EntryResponse{
+		ID:           entry.ID,
+		UserID:       entry.UserID,
+		Amount:       entry.Amount,
+		BasePoint:    entry.BasePoint,
+		BonusPercent: entry.BonusPercent,
+		BonusPoint:   entry.BonusPoint,
+		BalanceAfter: entry.BalanceAfter,
+		SourceType:   entry.SourceType,
+		SourceID:     entry.SourceID,
+		Description:  entry.Description,
+		ReversalOf:   entry.ReversalOf,
+		CreatedBy:    entry.CreatedBy,
+		CreatedAt:    entry.CreatedAt,
+	}
+}
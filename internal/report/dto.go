@@ -16,11 +16,12 @@ type ReportInput struct {
 	Address        string                  `json:"address" validate:"required"`
 	City           string                  `json:"city" validate:"required"`
 	Province       string                  `json:"province" validate:"required"`
+	IsAnonymous    bool                    `json:"is_anonymous"`
 	ReportImages   []*multipart.FileHeader `json:"-"`
 }
 
 type UpdateStatus struct {
-	Status string `json:"status" validate:"required,oneof='approve' 'reject'"`
+	Status string `json:"status" validate:"required,oneof='approve' 'reject' 'resolve'"`
 	Reason string `json:"reason"`
 }
 
@@ -44,6 +45,7 @@ type ReportDetail struct {
 	Province    string     `json:"province"`
 	Status      string     `json:"status"`
 	Reason      string     `json:"reason"`
+	IsAnonymous bool       `json:"is_anonymous"`
 
 	WasteMaterials []WasteMaterial `json:"waste_materials"`
 	ReportImages   []string        `json:"report_images"`
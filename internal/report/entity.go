@@ -10,8 +10,8 @@ import (
 
 // struct
 type Report struct {
-	ID          string  `json:"id" gorm:"primaryKey"`
-	AuthorID    string  `json:"author_id"`
+	ID          string  `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	AuthorID    string  `json:"author_id" gorm:"type:varchar(20)"`
 	ReportType  string  `json:"report_type" gorm:"type:enum('littering', 'rubbish');"`
 	Title       string  `json:"title"`
 	Description string  `json:"description"`
@@ -21,8 +21,9 @@ type Report struct {
 	Address     string  `json:"address"`
 	City        string  `json:"city"`
 	Province    string  `json:"province"`
-	Status      string  `json:"status" gorm:"type:enum('need review', 'approve', 'reject');default:'need review'"`
+	Status      string  `json:"status" gorm:"type:enum('need review', 'approve', 'reject', 'resolve');default:'need review'"`
 	Reason      string  `json:"reason"`
+	IsAnonymous bool    `json:"is_anonymous" gorm:"default:false"`
 
 	CreatedAt time.Time      `json:"-"`
 	UpdatedAt time.Time      `json:"-"`
@@ -40,7 +41,7 @@ type WasteMaterial struct {
 
 type ReportWasteMaterial struct {
 	ID              uuid.UUID `json:"id" gorm:"primaryKey"`
-	ReportID        string    `json:"report_id"`
+	ReportID        string    `json:"report_id" gorm:"type:varchar(20)"`
 	WasteMaterialID string    `json:"waste_material_id"`
 
 	CreatedAt time.Time      `json:"-"`
@@ -50,7 +51,7 @@ type ReportWasteMaterial struct {
 
 type ReportImage struct {
 	ID       uuid.UUID `json:"id" gorm:"primaryKey"`
-	ReportID string    `json:"report_id"`
+	ReportID string    `json:"report_id" gorm:"type:varchar(20)"`
 	ImageURL string    `json:"image_url"`
 
 	CreatedAt time.Time      `json:"-"`
@@ -60,11 +61,14 @@ type ReportImage struct {
 
 // interface
 type ReportRepository interface {
+	Transaction(fn func(repo ReportRepository) error) error
+
 	Create(report Report) (*Report, error)
 	FindByID(reportID string) (*Report, error)
 	FindAll(page, limit int, reportType, status string, date time.Time) (*[]Report, int64, error)
+	FindAllByStatuses(page, limit int, statuses []string) (*[]Report, int64, error)
 	FindAllReportsByUser(userID string, limit int) (*[]Report, error)
-	FindLastID() (string, error)
+	NextID() (string, error)
 	Update(report Report) error
 	Delete(reportID string) error
 
@@ -72,10 +76,12 @@ type ReportRepository interface {
 	DeleteImage(imageID string, reportID string) error
 	DeleteAllImage(reportID string) error
 	FindAllImage(reportID string) (*[]string, error)
+	FindImagesByReportIDs(reportIDs []string) (map[string][]string, error)
 
 	AddReportMaterial(material ReportWasteMaterial) (*ReportWasteMaterial, error)
 	DeleteAllReportMaterial(reportID string) error
 	FindAllReportMaterial(reportID string) (*[]WasteMaterial, error)
+	FindMaterialsByReportIDs(reportIDs []string) (map[string][]WasteMaterial, error)
 
 	FindWasteMaterialByID(materialID string) (*WasteMaterial, error)
 	FindWasteMaterialByType(materialType string) (*WasteMaterial, error)
@@ -87,11 +93,13 @@ type ReportUsecase interface {
 
 	UpdateStatusReport(report UpdateStatus, reportID string) error
 	FindAllReports(page, limit int, reportType, status string, date time.Time) (*[]ReportDetail, int64, error)
+	FindPublicReports(page, limit int) (*[]ReportDetail, int64, error)
 }
 
 type ReportHandler interface {
 	NewReport(c echo.Context) error
 	GetHistoryUserReports(c echo.Context) error
+	GetPublicReports(c echo.Context) error
 
 	UpdateStatus(c echo.Context) error
 	GetAllReports(c echo.Context) error
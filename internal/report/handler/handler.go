@@ -72,6 +72,31 @@ func (h *reportHandler) GetHistoryUserReports(c echo.Context) error {
 	return helper.ResponseHandler(c, http.StatusOK, "ok", reports)
 }
 
+func (h *reportHandler) GetPublicReports(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	reportDetails, total, err := h.reportUsecase.FindPublicReports(page, limit)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	response := rpt.ReportResponsePagination{
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+		Report: *reportDetails,
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", response)
+}
+
 // for admin
 func (h *reportHandler) UpdateStatus(c echo.Context) error {
 	var request rpt.UpdateStatus
@@ -91,6 +116,10 @@ func (h *reportHandler) UpdateStatus(c echo.Context) error {
 			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
 		}
 
+		if errors.Is(err, pkg.ErrReportNotApproved) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+
 		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
 	}
 
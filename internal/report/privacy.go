@@ -0,0 +1,41 @@
+package report
+
+import "math"
+
+// DefaultLocationGrid is the grid size in degrees (roughly 1.1 km) used to
+// blur report locations when no size is configured.
+const DefaultLocationGrid = 0.01
+
+const AnonymousAuthorName = "Anonymous"
+
+// PublicView returns the report as other users are allowed to see it. The
+// author of an anonymous report is hidden, the street address is dropped and
+// the coordinates are snapped to the centre of a grid cell of gridSize degrees.
+func (d ReportDetail) PublicView(gridSize float64) ReportDetail {
+	d = d.HideAnonymousAuthor()
+	d.Address = ""
+	d.Latitude = FuzzCoordinate(d.Latitude, gridSize)
+	d.Longitude = FuzzCoordinate(d.Longitude, gridSize)
+
+	return d
+}
+
+// HideAnonymousAuthor replaces the author of an anonymous report with a
+// placeholder. Use it whenever a report leaves the app, e.g. in webhooks.
+func (d ReportDetail) HideAnonymousAuthor() ReportDetail {
+	if d.IsAnonymous {
+		d.Author = UserDetail{Name: AnonymousAuthorName}
+	}
+
+	return d
+}
+
+// FuzzCoordinate rounds a coordinate down to its grid cell and returns the
+// centre of that cell, so every location inside a cell maps to the same point.
+func FuzzCoordinate(value, gridSize float64) float64 {
+	if gridSize <= 0 {
+		gridSize = DefaultLocationGrid
+	}
+
+	return math.Floor(value/gridSize)*gridSize + gridSize/2
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFuzzCoordinate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		gridSize float64
+		want     float64
+	}{
+		{"inside a cell", 0.0123, 0.01, 0.015},
+		{"cell edge", 0.02, 0.01, 0.025},
+		{"just below the next cell", 0.0299, 0.01, 0.025},
+		{"negative latitude", -6.2088, 0.01, -6.205},
+		{"negative rounds away from zero", -0.0001, 0.01, -0.005},
+		{"coarse grid", 106.8456, 0.1, 106.85},
+		{"zero grid uses the default", 0.0123, 0, 0.015},
+		{"negative grid uses the default", 0.0123, -1, 0.015},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FuzzCoordinate(tt.value, tt.gridSize); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("FuzzCoordinate(%v, %v) = %v, want %v", tt.value, tt.gridSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzCoordinateSnapsCell(t *testing.T) {
+	// every location in a cell maps to the same point
+	first := FuzzCoordinate(-6.2001, 0.01)
+	for _, value := range []float64{-6.2001, -6.2050, -6.2099} {
+		if got := FuzzCoordinate(value, 0.01); got != first {
+			t.Errorf("FuzzCoordinate(%v, 0.01) = %v, want %v like the rest of its cell", value, got, first)
+		}
+	}
+}
+
+func TestPublicView(t *testing.T) {
+	author := UserDetail{ID: "USR0001", Name: "Budi", ImageURL: "https://example.com/budi.png"}
+	tests := []struct {
+		name       string
+		anonymous  bool
+		wantAuthor UserDetail
+	}{
+		{"anonymous report hides the author", true, UserDetail{Name: AnonymousAuthorName}},
+		{"named report keeps the author", false, author},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := ReportDetail{
+				ID:          "RPT0001",
+				Author:      author,
+				Latitude:    -6.2088,
+				Longitude:   106.8456,
+				Address:     "Jalan Jendral Sudirman, Jakarta, Indonesia",
+				City:        "Jakarta",
+				IsAnonymous: tt.anonymous,
+			}
+
+			got := detail.PublicView(0.01)
+			if got.Author != tt.wantAuthor {
+				t.Errorf("author is %+v, want %+v", got.Author, tt.wantAuthor)
+			}
+			if got.Address != "" {
+				t.Errorf("address %q was not dropped", got.Address)
+			}
+			if math.Abs(got.Latitude-(-6.205)) > 1e-9 || math.Abs(got.Longitude-106.845) > 1e-9 {
+				t.Errorf("location is %v, %v, want the cell centre -6.205, 106.845", got.Latitude, got.Longitude)
+			}
+			if got.City != detail.City {
+				t.Errorf("city is %q, want %q", got.City, detail.City)
+			}
+			if detail.Author != author || detail.Address == "" {
+				t.Error("the original report was changed")
+			}
+		})
+	}
+}
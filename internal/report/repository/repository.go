@@ -15,6 +15,12 @@ func NewReportRepository(db database.Database) rpt.ReportRepository {
 	return &reportRepository{DB: db}
 }
 
+func (r *reportRepository) Transaction(fn func(repo rpt.ReportRepository) error) error {
+	return r.DB.Transaction(func(tx database.Database) error {
+		return fn(&reportRepository{DB: tx})
+	})
+}
+
 // Report
 func (r *reportRepository) Create(report rpt.Report) (*rpt.Report, error) {
 	if err := r.DB.GetDB().Create(&report).Error; err != nil {
@@ -33,13 +39,8 @@ func (r *reportRepository) FindByID(reportID string) (*rpt.Report, error) {
 	return &report, nil
 }
 
-func (r *reportRepository) FindLastID() (string, error) {
-	var report rpt.Report
-	if err := r.DB.GetDB().Unscoped().Order("id DESC").First(&report).Error; err != nil {
-		return "RPT0000", err
-	}
-
-	return report.ID, nil
+func (r *reportRepository) NextID() (string, error) {
+	return database.NextID(r.DB, "RPT")
 }
 
 func (r *reportRepository) Update(report rpt.Report) error {
@@ -85,6 +86,24 @@ func (r *reportRepository) FindAll(page, limit int, reportType, status string, d
 	return &reports, total, nil
 }
 
+// FindAllByStatuses lists the reports that are in any of the given statuses.
+func (r *reportRepository) FindAllByStatuses(page, limit int, statuses []string) (*[]rpt.Report, int64, error) {
+	var reports []rpt.Report
+	var total int64
+
+	db := r.DB.GetDB().Model(&rpt.Report{}).Where("status IN ?", statuses)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * limit
+	if err := db.Offset(offset).Limit(limit).Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &reports, total, nil
+}
+
 func (r *reportRepository) FindAllReportsByUser(userID string, limit int) (*[]rpt.Report, error) {
 	var reports []rpt.Report
 	if err := r.DB.GetDB().Where("author_id = ?", userID).Order("created_at desc").Limit(10).Find(&reports).Error; err != nil {
@@ -136,6 +155,25 @@ func (r *reportRepository) FindAllImage(reportID string) (*[]string, error) {
 	return &imageURLs, nil
 }
 
+func (r *reportRepository) FindImagesByReportIDs(reportIDs []string) (map[string][]string, error) {
+	var reportImages []rpt.ReportImage
+	imageURLs := make(map[string][]string)
+
+	if len(reportIDs) == 0 {
+		return imageURLs, nil
+	}
+
+	if err := r.DB.GetDB().Where("report_id IN ?", reportIDs).Find(&reportImages).Error; err != nil {
+		return nil, err
+	}
+
+	for _, image := range reportImages {
+		imageURLs[image.ReportID] = append(imageURLs[image.ReportID], image.ImageURL)
+	}
+
+	return imageURLs, nil
+}
+
 // Report Waste Materials
 func (r *reportRepository) AddReportMaterial(material rpt.ReportWasteMaterial) (*rpt.ReportWasteMaterial, error) {
 	if err := r.DB.GetDB().Create(&material).Error; err != nil {
@@ -155,19 +193,53 @@ func (r *reportRepository) DeleteAllReportMaterial(reportID string) error {
 }
 
 func (r *reportRepository) FindAllReportMaterial(reportID string) (*[]rpt.WasteMaterial, error) {
+	materials, err := r.FindMaterialsByReportIDs([]string{reportID})
+	if err != nil {
+		return nil, err
+	}
+
+	wasteMaterials := materials[reportID]
+	return &wasteMaterials, nil
+}
+
+func (r *reportRepository) FindMaterialsByReportIDs(reportIDs []string) (map[string][]rpt.WasteMaterial, error) {
 	var reportMaterials []rpt.ReportWasteMaterial
 	var wasteMaterials []rpt.WasteMaterial
+	materials := make(map[string][]rpt.WasteMaterial)
 
-	if err := r.DB.GetDB().Where("report_id = ?", reportID).Find(&reportMaterials).Error; err != nil {
+	if len(reportIDs) == 0 {
+		return materials, nil
+	}
+
+	if err := r.DB.GetDB().Where("report_id IN ?", reportIDs).Find(&reportMaterials).Error; err != nil {
 		return nil, err
 	}
 
-	for _, report := range reportMaterials {
-		wasteMaterial, _ := r.FindWasteMaterialByID(report.WasteMaterialID)
-		wasteMaterials = append(wasteMaterials, *wasteMaterial)
+	if len(reportMaterials) == 0 {
+		return materials, nil
 	}
 
-	return &wasteMaterials, nil
+	materialIDs := make([]string, 0, len(reportMaterials))
+	for _, reportMaterial := range reportMaterials {
+		materialIDs = append(materialIDs, reportMaterial.WasteMaterialID)
+	}
+
+	if err := r.DB.GetDB().Where("id IN ?", materialIDs).Find(&wasteMaterials).Error; err != nil {
+		return nil, err
+	}
+
+	materialByID := make(map[string]rpt.WasteMaterial, len(wasteMaterials))
+	for _, wasteMaterial := range wasteMaterials {
+		materialByID[wasteMaterial.ID] = wasteMaterial
+	}
+
+	for _, reportMaterial := range reportMaterials {
+		if wasteMaterial, ok := materialByID[reportMaterial.WasteMaterialID]; ok {
+			materials[reportMaterial.ReportID] = append(materials[reportMaterial.ReportID], wasteMaterial)
+		}
+	}
+
+	return materials, nil
 }
 
 func (r *reportRepository) FindWasteMaterialByID(materialID string) (*rpt.WasteMaterial, error) {
@@ -1,167 +1,117 @@
 package report
 
 import (
+	"log"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/sawalreverr/recything/internal/helper"
 	rpt "github.com/sawalreverr/recything/internal/report"
+	"github.com/sawalreverr/recything/internal/streak"
 	user "github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/internal/webhook"
 	"github.com/sawalreverr/recything/pkg"
 )
 
 type reportUsecase struct {
 	reportRepository rpt.ReportRepository
 	userRepository   user.UserRepository
+	webhook          webhook.Publisher
+	streak           streak.Recorder
+	locationGrid     float64
 }
 
-func NewReportUsecase(reportRepo rpt.ReportRepository, userRepo user.UserRepository) rpt.ReportUsecase {
-	return &reportUsecase{reportRepository: reportRepo, userRepository: userRepo}
+func NewReportUsecase(reportRepo rpt.ReportRepository, userRepo user.UserRepository, publisher webhook.Publisher, recorder streak.Recorder, locationGrid float64) rpt.ReportUsecase {
+	if locationGrid <= 0 {
+		locationGrid = rpt.DefaultLocationGrid
+	}
+
+	return &reportUsecase{reportRepository: reportRepo, userRepository: userRepo, webhook: publisher, streak: recorder, locationGrid: locationGrid}
 }
 
 func (uc *reportUsecase) CreateReport(report rpt.ReportInput, authorID string, imageURLs []string) (*rpt.ReportDetail, error) {
-	lastID, _ := uc.reportRepository.FindLastID()
-	newID := helper.GenerateCustomID(lastID, "RPT")
-
-	newReport := rpt.Report{
-		ID:          newID,
-		AuthorID:    authorID,
-		ReportType:  report.ReportType,
-		Title:       report.Title,
-		Description: report.Description,
-		WasteType:   report.WasteType,
-		Latitude:    report.Latitude,
-		Longitude:   report.Longitude,
-		Address:     report.Address,
-		City:        report.City,
-		Province:    report.Province,
-	}
-
-	createdReport, err := uc.reportRepository.Create(newReport)
-	if err != nil {
-		return nil, pkg.ErrStatusInternalError
-	}
+	var createdReport *rpt.Report
 
-	for _, materialType := range report.WasteMaterials {
-		material, err := uc.reportRepository.FindWasteMaterialByType(materialType)
+	err := uc.reportRepository.Transaction(func(repo rpt.ReportRepository) error {
+		newID, err := repo.NextID()
 		if err != nil {
-			_ = uc.reportRepository.Delete(createdReport.ID)
-			return nil, err
+			return err
 		}
 
-		reportMaterial := rpt.ReportWasteMaterial{
-			ID:              uuid.New(),
-			ReportID:        createdReport.ID,
-			WasteMaterialID: material.ID,
+		newReport := rpt.Report{
+			ID:          newID,
+			AuthorID:    authorID,
+			ReportType:  report.ReportType,
+			Title:       report.Title,
+			Description: report.Description,
+			WasteType:   report.WasteType,
+			Latitude:    report.Latitude,
+			Longitude:   report.Longitude,
+			Address:     report.Address,
+			City:        report.City,
+			Province:    report.Province,
+			IsAnonymous: report.IsAnonymous,
 		}
 
-		if _, err := uc.reportRepository.AddReportMaterial(reportMaterial); err != nil {
-			_ = uc.reportRepository.Delete(createdReport.ID)
-			return nil, err
+		created, err := repo.Create(newReport)
+		if err != nil {
+			return pkg.ErrStatusInternalError
 		}
-	}
 
-	for _, url := range imageURLs {
-		reportImage := rpt.ReportImage{
-			ID:       uuid.New(),
-			ReportID: createdReport.ID,
-			ImageURL: url,
+		for _, materialType := range report.WasteMaterials {
+			material, err := repo.FindWasteMaterialByType(materialType)
+			if err != nil {
+				return err
+			}
+
+			reportMaterial := rpt.ReportWasteMaterial{
+				ID:              uuid.New(),
+				ReportID:        created.ID,
+				WasteMaterialID: material.ID,
+			}
+
+			if _, err := repo.AddReportMaterial(reportMaterial); err != nil {
+				return err
+			}
 		}
 
-		if _, err := uc.reportRepository.AddImage(reportImage); err != nil {
-			_ = uc.reportRepository.Delete(createdReport.ID)
-			_ = uc.reportRepository.DeleteAllReportMaterial(createdReport.ID)
-			return nil, err
+		for _, url := range imageURLs {
+			reportImage := rpt.ReportImage{
+				ID:       uuid.New(),
+				ReportID: created.ID,
+				ImageURL: url,
+			}
+
+			if _, err := repo.AddImage(reportImage); err != nil {
+				return err
+			}
 		}
-	}
 
-	images, err := uc.reportRepository.FindAllImage(createdReport.ID)
+		createdReport = created
+		return nil
+	})
 	if err != nil {
-		return nil, pkg.ErrStatusInternalError
+		return nil, err
+	}
+
+	if err := uc.streak.Record(authorID, streak.ActionReport, createdReport.ID, createdReport.CreatedAt); err != nil {
+		log.Printf("record streak for report %s: %v", createdReport.ID, err)
 	}
 
-	materials, err := uc.reportRepository.FindAllReportMaterial(createdReport.ID)
+	reportDetails, err := uc.buildReportDetails([]rpt.Report{*createdReport})
 	if err != nil {
-		return nil, pkg.ErrStatusInternalError
+		return nil, err
 	}
 
-	userFound, _ := uc.userRepository.FindByID(authorID)
-	author := rpt.UserDetail{
-		ID:       userFound.ID,
-		Name:     userFound.Name,
-		ImageURL: userFound.PictureURL,
-	}
-
-	reportDetail := rpt.ReportDetail{
-		ID:             createdReport.ID,
-		Author:         author,
-		ReportType:     createdReport.ReportType,
-		Title:          createdReport.Title,
-		Description:    createdReport.Description,
-		WasteType:      createdReport.WasteType,
-		Latitude:       createdReport.Latitude,
-		Longitude:      createdReport.Longitude,
-		Address:        createdReport.Address,
-		City:           createdReport.City,
-		Province:       createdReport.Province,
-		Status:         createdReport.Status,
-		Reason:         createdReport.Reason,
-		CreatedAt:      createdReport.CreatedAt,
-		WasteMaterials: *materials,
-		ReportImages:   *images,
-	}
-
-	return &reportDetail, nil
+	return &(*reportDetails)[0], nil
 }
 
 func (uc *reportUsecase) FindHistoryUserReports(authorID string) (*[]rpt.ReportDetail, error) {
-	var reportDetails []rpt.ReportDetail
 	reports, err := uc.reportRepository.FindAllReportsByUser(authorID, 10)
 	if err != nil {
 		return nil, pkg.ErrStatusInternalError
 	}
 
-	for _, report := range *reports {
-		images, err := uc.reportRepository.FindAllImage(report.ID)
-		if err != nil {
-			return nil, pkg.ErrStatusInternalError
-		}
-
-		materials, err := uc.reportRepository.FindAllReportMaterial(report.ID)
-		if err != nil {
-			return nil, pkg.ErrStatusInternalError
-		}
-
-		userFound, _ := uc.userRepository.FindByID(authorID)
-		author := rpt.UserDetail{
-			ID:       userFound.ID,
-			Name:     userFound.Name,
-			ImageURL: userFound.PictureURL,
-		}
-
-		reportDetail := rpt.ReportDetail{
-			ID:             report.ID,
-			Author:         author,
-			ReportType:     report.ReportType,
-			Title:          report.Title,
-			Description:    report.Description,
-			WasteType:      report.WasteType,
-			Latitude:       report.Latitude,
-			Longitude:      report.Longitude,
-			Address:        report.Address,
-			City:           report.City,
-			Province:       report.Province,
-			Status:         report.Status,
-			Reason:         report.Reason,
-			CreatedAt:      report.CreatedAt,
-			WasteMaterials: *materials,
-			ReportImages:   *images,
-		}
-
-		reportDetails = append(reportDetails, reportDetail)
-	}
-
-	return &reportDetails, nil
+	return uc.buildReportDetails(*reports)
 }
 
 func (uc *reportUsecase) UpdateStatusReport(report rpt.UpdateStatus, reportID string) error {
@@ -170,6 +120,12 @@ func (uc *reportUsecase) UpdateStatusReport(report rpt.UpdateStatus, reportID st
 		return pkg.ErrReportNotFound
 	}
 
+	if report.Status == "resolve" && reportFound.Status != "approve" {
+		return pkg.ErrReportNotApproved
+	}
+
+	// partners are only told about a report once per status it reaches
+	changed := reportFound.Status != report.Status
 	reportFound.Status = report.Status
 
 	if reportFound.Status == "reject" {
@@ -180,37 +136,115 @@ func (uc *reportUsecase) UpdateStatusReport(report rpt.UpdateStatus, reportID st
 		return pkg.ErrStatusInternalError
 	}
 
+	if !changed {
+		return nil
+	}
+
+	var event string
+	switch reportFound.Status {
+	case "approve":
+		event = webhook.EventReportApproved
+	case "resolve":
+		event = webhook.EventReportResolved
+	default:
+		return nil
+	}
+
+	reportDetails, err := uc.buildReportDetails([]rpt.Report{*reportFound})
+	if err != nil {
+		return nil
+	}
+
+	region := webhook.Region{Province: reportFound.Province, City: reportFound.City}
+	if err := uc.webhook.Publish(event, region, (*reportDetails)[0].HideAnonymousAuthor()); err != nil {
+		log.Printf("queue webhook %s for report %s: %v", event, reportFound.ID, err)
+	}
+
 	return nil
 }
 
 func (uc *reportUsecase) FindAllReports(page, limit int, reportType, status string, date time.Time) (*[]rpt.ReportDetail, int64, error) {
-	var reportDetails []rpt.ReportDetail
 	reports, total, err := uc.reportRepository.FindAll(page, limit, reportType, status, date)
 	if err != nil {
 		return nil, 0, pkg.ErrStatusInternalError
 	}
 
-	for _, report := range *reports {
-		images, err := uc.reportRepository.FindAllImage(report.ID)
-		if err != nil {
-			return nil, 0, pkg.ErrStatusInternalError
-		}
+	reportDetails, err := uc.buildReportDetails(*reports)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		materials, err := uc.reportRepository.FindAllReportMaterial(report.ID)
-		if err != nil {
-			return nil, 0, pkg.ErrStatusInternalError
+	return reportDetails, total, nil
+}
+
+// FindPublicReports lists the reports other users may see, the approved ones
+// and those resolved since.
+func (uc *reportUsecase) FindPublicReports(page, limit int) (*[]rpt.ReportDetail, int64, error) {
+	reports, total, err := uc.reportRepository.FindAllByStatuses(page, limit, []string{"approve", "resolve"})
+	if err != nil {
+		return nil, 0, pkg.ErrStatusInternalError
+	}
+
+	reportDetails, err := uc.buildReportDetails(*reports)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, reportDetail := range *reportDetails {
+		(*reportDetails)[i] = reportDetail.PublicView(uc.locationGrid)
+	}
+
+	return reportDetails, total, nil
+}
+
+// buildReportDetails loads images, materials and authors for all reports at
+// once, so the number of queries does not grow with the number of reports.
+func (uc *reportUsecase) buildReportDetails(reports []rpt.Report) (*[]rpt.ReportDetail, error) {
+	reportDetails := make([]rpt.ReportDetail, 0, len(reports))
+	if len(reports) == 0 {
+		return &reportDetails, nil
+	}
+
+	reportIDs := make([]string, 0, len(reports))
+	authorIDs := make([]string, 0, len(reports))
+	seenAuthors := make(map[string]bool)
+	for _, report := range reports {
+		reportIDs = append(reportIDs, report.ID)
+		if !seenAuthors[report.AuthorID] {
+			seenAuthors[report.AuthorID] = true
+			authorIDs = append(authorIDs, report.AuthorID)
 		}
+	}
+
+	images, err := uc.reportRepository.FindImagesByReportIDs(reportIDs)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	materials, err := uc.reportRepository.FindMaterialsByReportIDs(reportIDs)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
 
-		userFound, _ := uc.userRepository.FindByID(report.AuthorID)
-		author := rpt.UserDetail{
+	users, err := uc.userRepository.FindByIDs(authorIDs)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	authors := make(map[string]rpt.UserDetail, len(*users))
+	for _, userFound := range *users {
+		authors[userFound.ID] = rpt.UserDetail{
 			ID:       userFound.ID,
 			Name:     userFound.Name,
 			ImageURL: userFound.PictureURL,
 		}
+	}
 
+	// reports without materials or images list them as [] rather than null
+	for _, report := range reports {
 		reportDetail := rpt.ReportDetail{
 			ID:             report.ID,
-			Author:         author,
+			Author:         authors[report.AuthorID],
 			ReportType:     report.ReportType,
 			Title:          report.Title,
 			Description:    report.Description,
@@ -222,13 +256,14 @@ func (uc *reportUsecase) FindAllReports(page, limit int, reportType, status stri
 			Province:       report.Province,
 			Status:         report.Status,
 			Reason:         report.Reason,
+			IsAnonymous:    report.IsAnonymous,
 			CreatedAt:      report.CreatedAt,
-			WasteMaterials: *materials,
-			ReportImages:   *images,
+			WasteMaterials: append([]rpt.WasteMaterial{}, materials[report.ID]...),
+			ReportImages:   append([]string{}, images[report.ID]...),
 		}
 
 		reportDetails = append(reportDetails, reportDetail)
 	}
 
-	return &reportDetails, total, nil
+	return &reportDetails, nil
 }
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+	rpt "github.com/sawalreverr/recything/internal/report"
+	reportRepo "github.com/sawalreverr/recything/internal/report/repository"
+	user "github.com/sawalreverr/recything/internal/user"
+	userRepo "github.com/sawalreverr/recything/internal/user/repository"
+)
+
+// seedReports creates count reports, each by its own author and with two
+// images and a waste material.
+func seedReports(t *testing.T, db database.Database, count int) []rpt.Report {
+	t.Helper()
+
+	// ids are at most 20 characters
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	material := rpt.WasteMaterial{ID: "TWM" + suffix, Type: "plastik"}
+	if err := db.GetDB().Create(&material).Error; err != nil {
+		t.Fatalf("seed waste material: %v", err)
+	}
+
+	reports := make([]rpt.Report, count)
+	for i := range reports {
+		author := user.User{ID: fmt.Sprintf("TUS%s%02d", suffix, i), Name: "author", Gender: "-", BirthDate: time.Now()}
+		if err := db.GetDB().Create(&author).Error; err != nil {
+			t.Fatalf("seed author: %v", err)
+		}
+
+		reports[i] = rpt.Report{
+			ID:         fmt.Sprintf("TRP%s%02d", suffix, i),
+			AuthorID:   author.ID,
+			ReportType: "littering",
+			Title:      "report",
+			WasteType:  "organik",
+			Status:     "approve",
+		}
+		if err := db.GetDB().Create(&reports[i]).Error; err != nil {
+			t.Fatalf("seed report: %v", err)
+		}
+
+		records := []interface{}{
+			&rpt.ReportImage{ID: uuid.New(), ReportID: reports[i].ID, ImageURL: "https://example.com/1.png"},
+			&rpt.ReportImage{ID: uuid.New(), ReportID: reports[i].ID, ImageURL: "https://example.com/2.png"},
+			&rpt.ReportWasteMaterial{ID: uuid.New(), ReportID: reports[i].ID, WasteMaterialID: material.ID},
+		}
+		for _, record := range records {
+			if err := db.GetDB().Create(record).Error; err != nil {
+				t.Fatalf("seed %T: %v", record, err)
+			}
+		}
+	}
+
+	return reports
+}
+
+func newTestUsecase(db database.Database) *reportUsecase {
+	return NewReportUsecase(reportRepo.NewReportRepository(db), userRepo.NewUserRepository(db), nil, nil, 0).(*reportUsecase)
+}
+
+func TestBuildReportDetailsQueryCount(t *testing.T) {
+	db := databasetest.Open(t)
+	uc := newTestUsecase(db)
+
+	queries := func(reports []rpt.Report) int {
+		stop := databasetest.CountQueries(t, db.GetDB())
+		details, err := uc.buildReportDetails(reports)
+		count := stop()
+		if err != nil {
+			t.Fatalf("build report details: %v", err)
+		}
+
+		for _, detail := range *details {
+			if len(detail.ReportImages) != 2 || len(detail.WasteMaterials) != 1 || detail.Author.ID == "" {
+				t.Fatalf("report %s has %d images, %d materials and author %q",
+					detail.ID, len(detail.ReportImages), len(detail.WasteMaterials), detail.Author.ID)
+			}
+		}
+		return count
+	}
+
+	single := queries(seedReports(t, db, 1))
+	page := queries(seedReports(t, db, 50))
+	if single == 0 {
+		t.Fatal("no queries were counted")
+	}
+	if page != single {
+		t.Errorf("a page of 50 reports ran %d queries, a single report %d", page, single)
+	}
+}
+
+func TestBuildReportDetailsEmptyLists(t *testing.T) {
+	db := databasetest.Open(t)
+	uc := newTestUsecase(db)
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	report := rpt.Report{ID: "TRP" + suffix, AuthorID: "TUS" + suffix, ReportType: "rubbish", WasteType: "organik"}
+	if err := db.GetDB().Create(&report).Error; err != nil {
+		t.Fatalf("seed report: %v", err)
+	}
+
+	details, err := uc.buildReportDetails([]rpt.Report{report})
+	if err != nil {
+		t.Fatalf("build report details: %v", err)
+	}
+
+	body, err := json.Marshal(*details)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"waste_materials":[]`, `"report_images":[]`} {
+		if !strings.Contains(string(body), field) {
+			t.Errorf("%s is missing from %s", field, body)
+		}
+	}
+
+	none, err := uc.buildReportDetails(nil)
+	if err != nil {
+		t.Fatalf("build report details: %v", err)
+	}
+	if body, _ := json.Marshal(*none); string(body) != "[]" {
+		t.Errorf("no reports serialize as %s, want []", body)
+	}
+}
+
+func TestFindPublicReportsStatuses(t *testing.T) {
+	db := databasetest.Open(t)
+	uc := newTestUsecase(db)
+
+	reports := seedReports(t, db, 4)
+	statuses := []string{"approve", "resolve", "reject", "need review"}
+	for i, status := range statuses {
+		if err := db.GetDB().Model(&rpt.Report{}).Where("id = ?", reports[i].ID).Update("status", status).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	details, _, err := uc.FindPublicReports(1, 10000)
+	if err != nil {
+		t.Fatalf("find public reports: %v", err)
+	}
+
+	listed := make(map[string]bool)
+	for _, detail := range *details {
+		listed[detail.ID] = true
+	}
+	for i, status := range statuses {
+		want := status == "approve" || status == "resolve"
+		if listed[reports[i].ID] != want {
+			t.Errorf("%s report listed is %v, want %v", status, listed[reports[i].ID], want)
+		}
+	}
+}
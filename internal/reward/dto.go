@@ -0,0 +1,61 @@
+package reward
+
+import "time"
+
+// RewardInput dates use the 2006-01-02 layout and may be left empty.
+type RewardInput struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url" validate:"omitempty,url"`
+	PointCost   int    `json:"point_cost" validate:"required,min=1"`
+	Stock       int    `json:"stock" validate:"min=0"`
+	ValidFrom   string `json:"valid_from"`
+	ValidUntil  string `json:"valid_until"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+type HandleInput struct {
+	Note string `json:"note" validate:"max=255"`
+}
+
+type RewardResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ImageURL    string     `json:"image_url"`
+	PointCost   int        `json:"point_cost"`
+	Stock       int        `json:"stock"`
+	ValidFrom   *time.Time `json:"valid_from"`
+	ValidUntil  *time.Time `json:"valid_until"`
+	IsActive    bool       `json:"is_active"`
+	CreatedBy   string     `json:"created_by"`
+}
+
+type RewardPaginationResponse struct {
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	Limit   int              `json:"limit"`
+	Rewards []RewardResponse `json:"rewards"`
+}
+
+type RedemptionResponse struct {
+	ID           uint       `json:"id"`
+	UserID       string     `json:"user_id"`
+	RewardID     uint       `json:"reward_id"`
+	RewardName   string     `json:"reward_name"`
+	PointCost    int        `json:"point_cost"`
+	VoucherCode  string     `json:"voucher_code"`
+	Status       string     `json:"status"`
+	PointEntryID uint       `json:"point_entry_id"`
+	HandledBy    *string    `json:"handled_by"`
+	HandledAt    *time.Time `json:"handled_at"`
+	Note         string     `json:"note"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type RedemptionPaginationResponse struct {
+	Total       int64                `json:"total"`
+	Page        int                  `json:"page"`
+	Limit       int                  `json:"limit"`
+	Redemptions []RedemptionResponse `json:"redemptions"`
+}
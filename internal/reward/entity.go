@@ -0,0 +1,121 @@
+package reward
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// redemption status
+const (
+	RedemptionPending   = "pending"
+	RedemptionFulfilled = "fulfilled"
+	RedemptionCancelled = "cancelled"
+)
+
+// struct
+
+// Reward is an item of the catalog users spend their points on. ValidFrom and
+// ValidUntil are whole days, a reward without them never expires.
+type Reward struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"type:varchar(100)"`
+	Description string     `json:"description" gorm:"type:text"`
+	ImageURL    string     `json:"image_url" gorm:"type:varchar(255)"`
+	PointCost   int        `json:"point_cost"`
+	Stock       int        `json:"stock"`
+	ValidFrom   *time.Time `json:"valid_from"`
+	ValidUntil  *time.Time `json:"valid_until"`
+	IsActive    bool       `json:"is_active" gorm:"default:true"`
+	CreatedBy   string     `json:"created_by"`
+
+	CreatedAt time.Time      `json:"-"`
+	UpdatedAt time.Time      `json:"-"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Redeemable reports whether the reward can be redeemed at now, stock aside.
+func (r Reward) Redeemable(now time.Time) bool {
+	if !r.IsActive {
+		return false
+	}
+	if r.ValidFrom != nil && now.Before(*r.ValidFrom) {
+		return false
+	}
+	if r.ValidUntil != nil && !now.Before(r.ValidUntil.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+// Redemption is a reward bought by a user. The points are taken from the
+// ledger when it is created and given back if it is cancelled before being
+// fulfilled.
+type Redemption struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       string     `json:"user_id" gorm:"index;type:varchar(20)"`
+	RewardID     uint       `json:"reward_id" gorm:"index"`
+	Reward       Reward     `json:"-"`
+	PointCost    int        `json:"point_cost"`
+	VoucherCode  string     `json:"voucher_code" gorm:"type:varchar(20);uniqueIndex"`
+	Status       string     `json:"status" gorm:"type:enum('pending', 'fulfilled', 'cancelled');default:'pending';index"`
+	PointEntryID uint       `json:"point_entry_id"`
+	HandledBy    *string    `json:"handled_by"`
+	HandledAt    *time.Time `json:"handled_at"`
+	Note         string     `json:"note"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (Redemption) TableName() string {
+	return "reward_redemptions"
+}
+
+// interface
+type RewardRepository interface {
+	Create(reward Reward) (*Reward, error)
+	FindByID(rewardID uint) (*Reward, error)
+	FindAll(availableAt *time.Time, page, limit int) (*[]Reward, int64, error)
+	Update(reward Reward) error
+	Delete(rewardID uint) error
+
+	Redeem(userID string, rewardID uint, voucherCode string, now time.Time) (*Redemption, error)
+	FindRedemptionByID(redemptionID uint) (*Redemption, error)
+	FindRedemptions(userID, status string, page, limit int) (*[]Redemption, int64, error)
+	Fulfill(redemptionID uint, adminID string, note string, now time.Time) error
+	Cancel(redemptionID uint, adminID string, note string, now time.Time) error
+}
+
+type RewardUsecase interface {
+	NewReward(input RewardInput, adminID string) (*RewardResponse, error)
+	GetRewardByID(rewardID uint) (*RewardResponse, error)
+	GetAllRewards(page, limit int) (*RewardPaginationResponse, error)
+	GetAvailableRewards(page, limit int) (*RewardPaginationResponse, error)
+	UpdateReward(rewardID uint, input RewardInput) error
+	DeleteReward(rewardID uint) error
+
+	Redeem(userID string, rewardID uint) (*RedemptionResponse, error)
+	GetUserRedemptions(userID string, page, limit int) (*RedemptionPaginationResponse, error)
+	GetRedemptionQueue(status string, page, limit int) (*RedemptionPaginationResponse, error)
+	Fulfill(redemptionID uint, input HandleInput, adminID string) error
+	Cancel(redemptionID uint, input HandleInput, adminID string) error
+}
+
+type RewardHandler interface {
+	NewReward(c echo.Context) error
+	GetRewardByID(c echo.Context) error
+	GetAllRewards(c echo.Context) error
+	UpdateReward(c echo.Context) error
+	DeleteReward(c echo.Context) error
+	UploadImage(c echo.Context) error
+
+	GetAvailableRewards(c echo.Context) error
+	Redeem(c echo.Context) error
+	GetUserRedemptions(c echo.Context) error
+
+	GetRedemptionQueue(c echo.Context) error
+	Fulfill(c echo.Context) error
+	Cancel(c echo.Context) error
+}
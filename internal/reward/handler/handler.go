@@ -0,0 +1,270 @@
+package reward
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sawalreverr/recything/internal/helper"
+	rwd "github.com/sawalreverr/recything/internal/reward"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+type rewardHandler struct {
+	rewardUsecase rwd.RewardUsecase
+}
+
+func NewRewardHandler(uc rwd.RewardUsecase) rwd.RewardHandler {
+	return &rewardHandler{rewardUsecase: uc}
+}
+
+// Reward
+func (h *rewardHandler) NewReward(c echo.Context) error {
+	var request rwd.RewardInput
+
+	adminID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	newReward, err := h.rewardUsecase.NewReward(request, adminID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrRewardInvalidDate) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusCreated, "reward created!", newReward)
+}
+
+func (h *rewardHandler) GetRewardByID(c echo.Context) error {
+	rewardID, err := strconv.Atoi(c.Param("rewardId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrRewardNotFound.Error())
+	}
+
+	reward, err := h.rewardUsecase.GetRewardByID(uint(rewardID))
+	if err != nil {
+		if errors.Is(err, pkg.ErrRewardNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", reward)
+}
+
+func (h *rewardHandler) GetAllRewards(c echo.Context) error {
+	page, limit := pagination(c)
+
+	rewards, err := h.rewardUsecase.GetAllRewards(page, limit)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", rewards)
+}
+
+func (h *rewardHandler) UpdateReward(c echo.Context) error {
+	var request rwd.RewardInput
+
+	rewardID, err := strconv.Atoi(c.Param("rewardId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrRewardNotFound.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.rewardUsecase.UpdateReward(uint(rewardID), request); err != nil {
+		if errors.Is(err, pkg.ErrRewardNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, pkg.ErrRewardInvalidDate) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "reward updated!", nil)
+}
+
+func (h *rewardHandler) DeleteReward(c echo.Context) error {
+	rewardID, err := strconv.Atoi(c.Param("rewardId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrRewardNotFound.Error())
+	}
+
+	if err := h.rewardUsecase.DeleteReward(uint(rewardID)); err != nil {
+		if errors.Is(err, pkg.ErrRewardNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "reward deleted!", nil)
+}
+
+func (h *rewardHandler) UploadImage(c echo.Context) error {
+	file, err := c.FormFile("image")
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "please upload your image!")
+	}
+
+	if file.Size > 2*1024*1024 {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "upload image size must less than 2MB!")
+	}
+
+	fileType := file.Header.Get("Content-Type")
+	if !strings.HasPrefix(fileType, "image/") {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "only image allowed!")
+	}
+
+	src, _ := file.Open()
+	defer src.Close()
+
+	resp, err := helper.UploadToCloudinary(src, "recything/rewards/")
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "upload failed, cloudinary server error!")
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "upload successfully!", echo.Map{
+		"image_url": resp,
+	})
+}
+
+// Redemption
+func (h *rewardHandler) GetAvailableRewards(c echo.Context) error {
+	page, limit := pagination(c)
+
+	rewards, err := h.rewardUsecase.GetAvailableRewards(page, limit)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", rewards)
+}
+
+func (h *rewardHandler) Redeem(c echo.Context) error {
+	userID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	rewardID, err := strconv.Atoi(c.Param("rewardId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrRewardNotFound.Error())
+	}
+
+	redemption, err := h.rewardUsecase.Redeem(userID, uint(rewardID))
+	if err != nil {
+		if errors.Is(err, pkg.ErrRewardNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, pkg.ErrRewardUnavailable) || errors.Is(err, pkg.ErrRewardOutOfStock) || errors.Is(err, pkg.ErrPointInsufficient) {
+			return helper.ErrorHandler(c, http.StatusConflict, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusCreated, "reward redeemed!", redemption)
+}
+
+func (h *rewardHandler) GetUserRedemptions(c echo.Context) error {
+	userID := c.Get("user").(*helper.JwtCustomClaims).UserID
+	page, limit := pagination(c)
+
+	redemptions, err := h.rewardUsecase.GetUserRedemptions(userID, page, limit)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", redemptions)
+}
+
+func (h *rewardHandler) GetRedemptionQueue(c echo.Context) error {
+	page, limit := pagination(c)
+
+	status := c.QueryParam("status")
+	if status == "" {
+		status = rwd.RedemptionPending
+	}
+	if status == "all" {
+		status = ""
+	}
+
+	redemptions, err := h.rewardUsecase.GetRedemptionQueue(status, page, limit)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", redemptions)
+}
+
+func (h *rewardHandler) Fulfill(c echo.Context) error {
+	return h.handleRedemption(c, h.rewardUsecase.Fulfill, "redemption fulfilled!")
+}
+
+func (h *rewardHandler) Cancel(c echo.Context) error {
+	return h.handleRedemption(c, h.rewardUsecase.Cancel, "redemption cancelled!")
+}
+
+func (h *rewardHandler) handleRedemption(c echo.Context, handle func(uint, rwd.HandleInput, string) error, message string) error {
+	var request rwd.HandleInput
+
+	adminID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	redemptionID, err := strconv.Atoi(c.Param("redemptionId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrRedemptionNotFound.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := handle(uint(redemptionID), request, adminID); err != nil {
+		if errors.Is(err, pkg.ErrRedemptionNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, pkg.ErrRedemptionNotPending) || errors.Is(err, pkg.ErrPointEntryReversed) {
+			return helper.ErrorHandler(c, http.StatusConflict, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, message, nil)
+}
+
+func pagination(c echo.Context) (int, int) {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	return page, limit
+}
@@ -0,0 +1,261 @@
+package reward
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/database"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	pointRepo "github.com/sawalreverr/recything/internal/point/repository"
+	rwd "github.com/sawalreverr/recything/internal/reward"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type rewardRepository struct {
+	DB database.Database
+}
+
+func NewRewardRepository(db database.Database) rwd.RewardRepository {
+	return &rewardRepository{DB: db}
+}
+
+// Reward
+func (r *rewardRepository) Create(reward rwd.Reward) (*rwd.Reward, error) {
+	if err := r.DB.GetDB().Create(&reward).Error; err != nil {
+		return nil, err
+	}
+
+	return &reward, nil
+}
+
+func (r *rewardRepository) FindByID(rewardID uint) (*rwd.Reward, error) {
+	var reward rwd.Reward
+	if err := r.DB.GetDB().Where("id = ?", rewardID).First(&reward).Error; err != nil {
+		return nil, err
+	}
+
+	return &reward, nil
+}
+
+// FindAll lists the catalog. When availableAt is set only rewards that are
+// active, valid on that day and in stock are listed.
+func (r *rewardRepository) FindAll(availableAt *time.Time, page, limit int) (*[]rwd.Reward, int64, error) {
+	var rewards []rwd.Reward
+	var total int64
+
+	db := r.DB.GetDB().Model(&rwd.Reward{})
+	if availableAt != nil {
+		day := availableAt.Format("2006-01-02")
+		db = db.Where("is_active = ? AND stock > 0", true).
+			Where("valid_from IS NULL OR DATE(valid_from) <= ?", day).
+			Where("valid_until IS NULL OR DATE(valid_until) >= ?", day)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := db.Order("point_cost, id").Offset(offset).Limit(limit).Find(&rewards).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &rewards, total, nil
+}
+
+func (r *rewardRepository) Update(reward rwd.Reward) error {
+	if err := r.DB.GetDB().Save(&reward).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *rewardRepository) Delete(rewardID uint) error {
+	if err := r.DB.GetDB().Where("id = ?", rewardID).Delete(&rwd.Reward{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Redemption
+
+// Redeem takes one unit of stock and the reward's cost in points in a single
+// transaction. The reward row is locked for the stock and the point ledger
+// locks the user row, so concurrent redemptions can neither oversell a reward
+// nor spend more points than the user holds.
+func (r *rewardRepository) Redeem(userID string, rewardID uint, voucherCode string, now time.Time) (*rwd.Redemption, error) {
+	var redemption rwd.Redemption
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		var reward rwd.Reward
+		if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", rewardID).
+			First(&reward).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ErrRewardNotFound
+			}
+			return err
+		}
+
+		if !reward.Redeemable(now) {
+			return pkg.ErrRewardUnavailable
+		}
+		if reward.Stock <= 0 {
+			return pkg.ErrRewardOutOfStock
+		}
+
+		if err := tx.GetDB().Model(&reward).Update("stock", gorm.Expr("stock - 1")).Error; err != nil {
+			return err
+		}
+
+		redemption = rwd.Redemption{
+			UserID:      userID,
+			RewardID:    reward.ID,
+			Reward:      reward,
+			PointCost:   reward.PointCost,
+			VoucherCode: voucherCode,
+			Status:      rwd.RedemptionPending,
+		}
+		if err := tx.GetDB().Omit("Reward").Create(&redemption).Error; err != nil {
+			return err
+		}
+
+		entry, err := pointRepo.NewPointRepository(tx).Append(pnt.Entry{
+			UserID:      userID,
+			Amount:      -reward.PointCost,
+			BasePoint:   -reward.PointCost,
+			SourceType:  pnt.SourceRedemption,
+			SourceID:    strconv.FormatUint(uint64(redemption.ID), 10),
+			Description: reward.Name,
+			CreatedBy:   userID,
+		})
+		if err != nil {
+			if errors.Is(err, pkg.ErrPointBalanceNegative) {
+				return pkg.ErrPointInsufficient
+			}
+			return err
+		}
+
+		redemption.PointEntryID = entry.ID
+		return tx.GetDB().Model(&redemption).Update("point_entry_id", entry.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &redemption, nil
+}
+
+func (r *rewardRepository) FindRedemptionByID(redemptionID uint) (*rwd.Redemption, error) {
+	var redemption rwd.Redemption
+	if err := r.DB.GetDB().Preload("Reward", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("id = ?", redemptionID).First(&redemption).Error; err != nil {
+		return nil, err
+	}
+
+	return &redemption, nil
+}
+
+// FindRedemptions lists redemptions newest first for a user, or oldest first
+// across users when userID is empty, which is the order the queue is worked
+// through.
+func (r *rewardRepository) FindRedemptions(userID, status string, page, limit int) (*[]rwd.Redemption, int64, error) {
+	var redemptions []rwd.Redemption
+	var total int64
+
+	db := r.DB.GetDB().Model(&rwd.Redemption{})
+	order := "id"
+	if userID != "" {
+		db = db.Where("user_id = ?", userID)
+		order = "id desc"
+	}
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := db.Preload("Reward", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Order(order).Offset(offset).Limit(limit).Find(&redemptions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &redemptions, total, nil
+}
+
+func (r *rewardRepository) Fulfill(redemptionID uint, adminID string, note string, now time.Time) error {
+	result := r.DB.GetDB().Model(&rwd.Redemption{}).
+		Where("id = ? AND status = ?", redemptionID, rwd.RedemptionPending).
+		Updates(map[string]interface{}{
+			"status":     rwd.RedemptionFulfilled,
+			"handled_by": adminID,
+			"handled_at": now,
+			"note":       note,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return pkg.ErrRedemptionNotPending
+	}
+
+	return nil
+}
+
+// Cancel gives the points back through a reversal entry and returns the unit
+// to stock. Only pending redemptions can be cancelled.
+func (r *rewardRepository) Cancel(redemptionID uint, adminID string, note string, now time.Time) error {
+	return r.DB.Transaction(func(tx database.Database) error {
+		var redemption rwd.Redemption
+		if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", redemptionID).
+			First(&redemption).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ErrRedemptionNotFound
+			}
+			return err
+		}
+
+		if redemption.Status != rwd.RedemptionPending {
+			return pkg.ErrRedemptionNotPending
+		}
+
+		if err := tx.GetDB().Model(&redemption).Updates(map[string]interface{}{
+			"status":     rwd.RedemptionCancelled,
+			"handled_by": adminID,
+			"handled_at": now,
+			"note":       note,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.GetDB().Unscoped().Model(&rwd.Reward{}).
+			Where("id = ?", redemption.RewardID).
+			Update("stock", gorm.Expr("stock + 1")).Error; err != nil {
+			return err
+		}
+
+		_, err := pointRepo.NewPointRepository(tx).Append(pnt.Entry{
+			UserID:      redemption.UserID,
+			Amount:      redemption.PointCost,
+			BasePoint:   redemption.PointCost,
+			SourceType:  pnt.SourceReversal,
+			SourceID:    strconv.FormatUint(uint64(redemption.PointEntryID), 10),
+			Description: "Redemption cancelled",
+			ReversalOf:  &redemption.PointEntryID,
+			CreatedBy:   adminID,
+		})
+		if errors.Is(err, pkg.ErrPointEntryExists) {
+			return pkg.ErrPointEntryReversed
+		}
+		return err
+	})
+}
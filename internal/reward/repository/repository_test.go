@@ -0,0 +1,158 @@
+package reward
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	pointRepo "github.com/sawalreverr/recything/internal/point/repository"
+	rwd "github.com/sawalreverr/recything/internal/reward"
+	user "github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm/clause"
+)
+
+// seedRedeemer creates a user holding balance points, earned through an
+// adjustment, and a reward costing cost points with stock left.
+func seedRedeemer(t *testing.T, db database.Database, balance, cost, stock int) (string, rwd.Reward) {
+	t.Helper()
+
+	// ids are at most 20 characters
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	userId := "TUS" + suffix
+	if err := db.GetDB().Omit(clause.Associations).Create(&user.User{ID: userId, Name: "redeemer", Gender: "-", BirthDate: time.Now()}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	if _, err := pointRepo.NewPointRepository(db).Append(pnt.Entry{
+		UserID:     userId,
+		Amount:     balance,
+		SourceType: pnt.SourceAdjustment,
+		SourceID:   "TAJ" + suffix,
+	}); err != nil {
+		t.Fatalf("seed balance: %v", err)
+	}
+
+	reward := rwd.Reward{Name: "TRW" + suffix, PointCost: cost, Stock: stock, IsActive: true}
+	if err := db.GetDB().Create(&reward).Error; err != nil {
+		t.Fatalf("seed reward: %v", err)
+	}
+	return userId, reward
+}
+
+func TestRedeemConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewRewardRepository(db)
+
+	// 100 points cover three redemptions of 30, the stock would allow ten
+	userId, reward := seedRedeemer(t, db, 100, 30, 10)
+
+	const attempts = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	redeemed := 0
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			voucher := fmt.Sprintf("TV%s%02d", userId[3:], i)
+			_, err := repository.Redeem(userId, reward.ID, voucher, time.Now())
+			switch {
+			case err == nil:
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			case !errors.Is(err, pkg.ErrPointInsufficient):
+				t.Errorf("redeem: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if redeemed != 3 {
+		t.Errorf("%d redemptions went through, want 3", redeemed)
+	}
+
+	var negative int64
+	if err := db.GetDB().Model(&pnt.Entry{}).Where("user_id = ? AND balance_after < 0", userId).Count(&negative).Error; err != nil {
+		t.Fatal(err)
+	}
+	if negative != 0 {
+		t.Errorf("%d ledger entries left a negative balance", negative)
+	}
+
+	var stored user.User
+	if err := db.GetDB().Where("id = ?", userId).First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Point != 10 {
+		t.Errorf("users.point is %d, want 10", stored.Point)
+	}
+
+	var left rwd.Reward
+	if err := db.GetDB().Where("id = ?", reward.ID).First(&left).Error; err != nil {
+		t.Fatal(err)
+	}
+	if left.Stock != 10-redeemed {
+		t.Errorf("stock is %d after %d redemptions, want %d", left.Stock, redeemed, 10-redeemed)
+	}
+}
+
+func TestRedeemKeepsLevel(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewRewardRepository(db)
+
+	// the user earns exactly the upper target, so no level created by other
+	// tests lies between it and what they earned
+	now := time.Now().UnixNano()
+	base := 1000 + int(now%900000)
+	suffix := strconv.FormatInt(now, 36)
+	lower := achievement.Achievement{Level: "TLVa" + suffix, Kind: achievement.KindPoint, TargetPoint: base}
+	upper := achievement.Achievement{Level: "TLVb" + suffix, Kind: achievement.KindPoint, TargetPoint: base + 100}
+	for _, level := range []*achievement.Achievement{&lower, &upper} {
+		if err := db.GetDB().Create(level).Error; err != nil {
+			t.Fatalf("seed level: %v", err)
+		}
+	}
+
+	userId, reward := seedRedeemer(t, db, base+100, 50, 1)
+	level := func() int {
+		var stored user.User
+		if err := db.GetDB().Where("id = ?", userId).First(&stored).Error; err != nil {
+			t.Fatal(err)
+		}
+		return stored.AchievementID
+	}
+	if got := level(); got != upper.ID {
+		t.Fatalf("user holds achievement %d after earning, want %d", got, upper.ID)
+	}
+
+	redemption, err := repository.Redeem(userId, reward.ID, "TV"+suffix, time.Now())
+	if err != nil {
+		t.Fatalf("redeem: %v", err)
+	}
+	if got := level(); got != upper.ID {
+		t.Errorf("user holds achievement %d after redeeming, want %d", got, upper.ID)
+	}
+
+	if err := repository.Cancel(redemption.ID, "TAD"+suffix, "out of stock", time.Now()); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if got := level(); got != upper.ID {
+		t.Errorf("user holds achievement %d after the redemption was cancelled, want %d", got, upper.ID)
+	}
+
+	var changes int64
+	if err := db.GetDB().Model(&achievement.UserLevelChange{}).Where("user_id = ? AND from_achievement_id = ?", userId, upper.ID).Count(&changes).Error; err != nil {
+		t.Fatal(err)
+	}
+	if changes != 0 {
+		t.Errorf("%d level changes away from %s were recorded", changes, upper.Level)
+	}
+}
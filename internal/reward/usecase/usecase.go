@@ -0,0 +1,283 @@
+package reward
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"time"
+
+	rwd "github.com/sawalreverr/recything/internal/reward"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+)
+
+// voucher codes leave out characters that are easy to misread
+const voucherAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const voucherLength = 10
+
+type rewardUsecase struct {
+	rewardRepository rwd.RewardRepository
+}
+
+func NewRewardUsecase(repo rwd.RewardRepository) rwd.RewardUsecase {
+	return &rewardUsecase{rewardRepository: repo}
+}
+
+// Reward
+func (uc *rewardUsecase) NewReward(input rwd.RewardInput, adminID string) (*rwd.RewardResponse, error) {
+	validFrom, validUntil, err := parseValidity(input)
+	if err != nil {
+		return nil, err
+	}
+
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	newReward, err := uc.rewardRepository.Create(rwd.Reward{
+		Name:        input.Name,
+		Description: input.Description,
+		ImageURL:    input.ImageURL,
+		PointCost:   input.PointCost,
+		Stock:       input.Stock,
+		ValidFrom:   validFrom,
+		ValidUntil:  validUntil,
+		IsActive:    isActive,
+		CreatedBy:   adminID,
+	})
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := rewardResponse(*newReward)
+	return &response, nil
+}
+
+func (uc *rewardUsecase) GetRewardByID(rewardID uint) (*rwd.RewardResponse, error) {
+	reward, err := uc.rewardRepository.FindByID(rewardID)
+	if err != nil {
+		return nil, pkg.ErrRewardNotFound
+	}
+
+	response := rewardResponse(*reward)
+	return &response, nil
+}
+
+func (uc *rewardUsecase) GetAllRewards(page, limit int) (*rwd.RewardPaginationResponse, error) {
+	return uc.findRewards(nil, page, limit)
+}
+
+func (uc *rewardUsecase) GetAvailableRewards(page, limit int) (*rwd.RewardPaginationResponse, error) {
+	now := time.Now()
+	return uc.findRewards(&now, page, limit)
+}
+
+func (uc *rewardUsecase) findRewards(availableAt *time.Time, page, limit int) (*rwd.RewardPaginationResponse, error) {
+	rewards, total, err := uc.rewardRepository.FindAll(availableAt, page, limit)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := rwd.RewardPaginationResponse{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Rewards: []rwd.RewardResponse{},
+	}
+	for _, reward := range *rewards {
+		response.Rewards = append(response.Rewards, rewardResponse(reward))
+	}
+
+	return &response, nil
+}
+
+func (uc *rewardUsecase) UpdateReward(rewardID uint, input rwd.RewardInput) error {
+	reward, err := uc.rewardRepository.FindByID(rewardID)
+	if err != nil {
+		return pkg.ErrRewardNotFound
+	}
+
+	validFrom, validUntil, err := parseValidity(input)
+	if err != nil {
+		return err
+	}
+
+	reward.Name = input.Name
+	reward.Description = input.Description
+	reward.ImageURL = input.ImageURL
+	reward.PointCost = input.PointCost
+	reward.Stock = input.Stock
+	reward.ValidFrom = validFrom
+	reward.ValidUntil = validUntil
+	if input.IsActive != nil {
+		reward.IsActive = *input.IsActive
+	}
+
+	if err := uc.rewardRepository.Update(*reward); err != nil {
+		return pkg.ErrStatusInternalError
+	}
+
+	return nil
+}
+
+func (uc *rewardUsecase) DeleteReward(rewardID uint) error {
+	if _, err := uc.rewardRepository.FindByID(rewardID); err != nil {
+		return pkg.ErrRewardNotFound
+	}
+
+	if err := uc.rewardRepository.Delete(rewardID); err != nil {
+		return pkg.ErrStatusInternalError
+	}
+
+	return nil
+}
+
+// Redemption
+func (uc *rewardUsecase) Redeem(userID string, rewardID uint) (*rwd.RedemptionResponse, error) {
+	code, err := voucherCode()
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	redemption, err := uc.rewardRepository.Redeem(userID, rewardID, code, time.Now())
+	if err != nil {
+		if errors.Is(err, pkg.ErrRewardNotFound) || errors.Is(err, pkg.ErrRewardUnavailable) ||
+			errors.Is(err, pkg.ErrRewardOutOfStock) || errors.Is(err, pkg.ErrPointInsufficient) {
+			return nil, err
+		}
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := redemptionResponse(*redemption)
+	return &response, nil
+}
+
+func (uc *rewardUsecase) GetUserRedemptions(userID string, page, limit int) (*rwd.RedemptionPaginationResponse, error) {
+	return uc.findRedemptions(userID, "", page, limit)
+}
+
+func (uc *rewardUsecase) GetRedemptionQueue(status string, page, limit int) (*rwd.RedemptionPaginationResponse, error) {
+	return uc.findRedemptions("", status, page, limit)
+}
+
+func (uc *rewardUsecase) findRedemptions(userID, status string, page, limit int) (*rwd.RedemptionPaginationResponse, error) {
+	redemptions, total, err := uc.rewardRepository.FindRedemptions(userID, status, page, limit)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := rwd.RedemptionPaginationResponse{
+		Total:       total,
+		Page:        page,
+		Limit:       limit,
+		Redemptions: []rwd.RedemptionResponse{},
+	}
+	for _, redemption := range *redemptions {
+		response.Redemptions = append(response.Redemptions, redemptionResponse(redemption))
+	}
+
+	return &response, nil
+}
+
+func (uc *rewardUsecase) Fulfill(redemptionID uint, input rwd.HandleInput, adminID string) error {
+	if _, err := uc.rewardRepository.FindRedemptionByID(redemptionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return pkg.ErrRedemptionNotFound
+		}
+		return pkg.ErrStatusInternalError
+	}
+
+	if err := uc.rewardRepository.Fulfill(redemptionID, adminID, input.Note, time.Now()); err != nil {
+		if errors.Is(err, pkg.ErrRedemptionNotPending) {
+			return err
+		}
+		return pkg.ErrStatusInternalError
+	}
+
+	return nil
+}
+
+func (uc *rewardUsecase) Cancel(redemptionID uint, input rwd.HandleInput, adminID string) error {
+	if err := uc.rewardRepository.Cancel(redemptionID, adminID, input.Note, time.Now()); err != nil {
+		if errors.Is(err, pkg.ErrRedemptionNotFound) || errors.Is(err, pkg.ErrRedemptionNotPending) ||
+			errors.Is(err, pkg.ErrPointEntryReversed) {
+			return err
+		}
+		return pkg.ErrStatusInternalError
+	}
+
+	return nil
+}
+
+func parseValidity(input rwd.RewardInput) (*time.Time, *time.Time, error) {
+	var validFrom, validUntil *time.Time
+
+	if input.ValidFrom != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", input.ValidFrom, time.Local)
+		if err != nil {
+			return nil, nil, pkg.ErrRewardInvalidDate
+		}
+		validFrom = &parsed
+	}
+	if input.ValidUntil != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", input.ValidUntil, time.Local)
+		if err != nil {
+			return nil, nil, pkg.ErrRewardInvalidDate
+		}
+		validUntil = &parsed
+	}
+
+	if validFrom != nil && validUntil != nil && validUntil.Before(*validFrom) {
+		return nil, nil, pkg.ErrRewardInvalidDate
+	}
+
+	return validFrom, validUntil, nil
+}
+
+func voucherCode() (string, error) {
+	code := make([]byte, voucherLength)
+	max := big.NewInt(int64(len(voucherAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = voucherAlphabet[n.Int64()]
+	}
+
+	return "RCY-" + string(code), nil
+}
+
+func rewardResponse(reward rwd.Reward) rwd.RewardResponse {
+	return rwd.RewardResponse{
+		ID:          reward.ID,
+		Name:        reward.Name,
+		Description: reward.Description,
+		ImageURL:    reward.ImageURL,
+		PointCost:   reward.PointCost,
+		Stock:       reward.Stock,
+		ValidFrom:   reward.ValidFrom,
+		ValidUntil:  reward.ValidUntil,
+		IsActive:    reward.IsActive,
+		CreatedBy:   reward.CreatedBy,
+	}
+}
+
+func redemptionResponse(redemption rwd.Redemption) rwd.RedemptionResponse {
+	return rwd.RedemptionResponse{
+		ID:           redemption.ID,
+		UserID:       redemption.UserID,
+		RewardID:     redemption.RewardID,
+		RewardName:   redemption.Reward.Name,
+		PointCost:    redemption.PointCost,
+		VoucherCode:  redemption.VoucherCode,
+		Status:       redemption.Status,
+		PointEntryID: redemption.PointEntryID,
+		HandledBy:    redemption.HandledBy,
+		HandledAt:    redemption.HandledAt,
+		Note:         redemption.Note,
+		CreatedAt:    redemption.CreatedAt,
+	}
+}
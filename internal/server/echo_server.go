@@ -67,6 +67,9 @@ func (s *echoServer) Start() {
 	// manage task handler
 	s.manageTask()
 
+	// task template handler
+	s.taskTemplate()
+
 	// user task handler
 	s.userTask()
 
@@ -106,6 +109,18 @@ func (s *echoServer) Start() {
 	// dashboard handler
 	s.dashboardHandler()
 
+	// webhook handler
+	s.webhookHandler()
+
+	// point ledger handler
+	s.pointHandler()
+
+	// reward catalog handler
+	s.rewardHandler()
+
+	// activity streak handler
+	s.streakHandler()
+
 	serverPORT := fmt.Sprintf(":%d", s.conf.Server.Port)
 	s.app.Logger.Fatal(s.app.Start(serverPORT))
 }
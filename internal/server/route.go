@@ -37,17 +37,30 @@ import (
 	leaderboardRepo "github.com/sawalreverr/recything/internal/leaderboard/repository"
 	leaderboardUsecase "github.com/sawalreverr/recything/internal/leaderboard/usecase"
 	"github.com/sawalreverr/recything/internal/middleware"
+	pointHandler "github.com/sawalreverr/recything/internal/point/handler"
+	pointRepo "github.com/sawalreverr/recything/internal/point/repository"
+	pointUsecase "github.com/sawalreverr/recything/internal/point/usecase"
 	reminaiHandler "github.com/sawalreverr/recything/internal/remin-ai/handler"
 	reminaiUsecase "github.com/sawalreverr/recything/internal/remin-ai/usecase"
 	reportHandler "github.com/sawalreverr/recything/internal/report/handler"
 	reportRepo "github.com/sawalreverr/recything/internal/report/repository"
 	reportUsecase "github.com/sawalreverr/recything/internal/report/usecase"
+	rewardHandler "github.com/sawalreverr/recything/internal/reward/handler"
+	rewardRepo "github.com/sawalreverr/recything/internal/reward/repository"
+	rewardUsecase "github.com/sawalreverr/recything/internal/reward/usecase"
+	"github.com/sawalreverr/recything/internal/streak"
+	streakHandler "github.com/sawalreverr/recything/internal/streak/handler"
+	streakRepo "github.com/sawalreverr/recything/internal/streak/repository"
+	streakUsecase "github.com/sawalreverr/recything/internal/streak/usecase"
 	approvalTaskHandler "github.com/sawalreverr/recything/internal/task/approval_task/handler"
 	approvalTaskRepo "github.com/sawalreverr/recything/internal/task/approval_task/repository"
 	approvalTaskUsecase "github.com/sawalreverr/recything/internal/task/approval_task/usecase"
 	taskHandler "github.com/sawalreverr/recything/internal/task/manage_task/handler"
 	taskRepo "github.com/sawalreverr/recything/internal/task/manage_task/repository"
 	taskUsecase "github.com/sawalreverr/recything/internal/task/manage_task/usecase"
+	taskTemplateHandler "github.com/sawalreverr/recything/internal/task/task_template/handler"
+	taskTemplateRepo "github.com/sawalreverr/recything/internal/task/task_template/repository"
+	taskTemplateUsecase "github.com/sawalreverr/recything/internal/task/task_template/usecase"
 	userTaskHandler "github.com/sawalreverr/recything/internal/task/user_task/handler"
 	userTaskRepo "github.com/sawalreverr/recything/internal/task/user_task/repository"
 	userTaskUsecase "github.com/sawalreverr/recything/internal/task/user_task/usecase"
@@ -60,6 +73,10 @@ import (
 	userVideoHandler "github.com/sawalreverr/recything/internal/video/user_video/handler"
 	userVideoRepo "github.com/sawalreverr/recything/internal/video/user_video/repository"
 	userVideoUsecase "github.com/sawalreverr/recything/internal/video/user_video/usecase"
+	"github.com/sawalreverr/recything/internal/webhook"
+	webhookHandler "github.com/sawalreverr/recything/internal/webhook/handler"
+	webhookRepo "github.com/sawalreverr/recything/internal/webhook/repository"
+	webhookUsecase "github.com/sawalreverr/recything/internal/webhook/usecase"
 )
 
 var (
@@ -117,7 +134,7 @@ func (s *echoServer) authHttpHandler() {
 
 func (s *echoServer) userHttpHandler() {
 	repository := userRepo.NewUserRepository(s.db)
-	usecase := userUsecase.NewUserUsecase(repository)
+	usecase := userUsecase.NewUserUsecase(repository, s.streakUsecase())
 	handler := userHandler.NewUserHandler(usecase)
 
 	// Profile user based on JWT user token
@@ -166,7 +183,12 @@ func (s *echoServer) supAdminHttpHandler() {
 func (s *echoServer) reportHttpHandler() {
 	reportRepository := reportRepo.NewReportRepository(s.db)
 	userRepository := userRepo.NewUserRepository(s.db)
-	usecase := reportUsecase.NewReportUsecase(reportRepository, userRepository)
+	var locationGrid float64
+	if s.conf.Report != nil {
+		locationGrid = s.conf.Report.LocationGrid
+	}
+
+	usecase := reportUsecase.NewReportUsecase(reportRepository, userRepository, s.webhookPublisher(), s.streakUsecase(), locationGrid)
 	handler := reportHandler.NewReportHandler(usecase)
 
 	// User create new report
@@ -175,6 +197,9 @@ func (s *echoServer) reportHttpHandler() {
 	// User get all history reports
 	s.gr.GET("/report", handler.GetHistoryUserReports, UserMiddleware)
 
+	// User get approved reports of other users, anonymized and with blurred location
+	s.gr.GET("/report/public", handler.GetPublicReports, UserMiddleware)
+
 	// Admin update status approved or reject
 	s.gr.PUT("/report/:reportId", handler.UpdateStatus, SuperAdminOrAdminMiddleware)
 
@@ -217,11 +242,45 @@ func (s *echoServer) manageTask() {
 	// delete task challenge
 	s.gr.DELETE("/tasks/:taskId", handler.DeleteTaskHandler, SuperAdminOrAdminMiddleware)
 
+	// insert a step into a task challenge
+	s.gr.POST("/tasks/:taskId/steps", handler.AddTaskStepHandler, SuperAdminOrAdminMiddleware)
+
+	// reorder the steps of a task challenge
+	s.gr.PUT("/tasks/:taskId/steps/order", handler.ReorderTaskStepsHandler, SuperAdminOrAdminMiddleware)
+
+	// remove a step from a task challenge
+	s.gr.DELETE("/tasks/:taskId/steps/:stepId", handler.DeleteTaskStepHandler, SuperAdminOrAdminMiddleware)
+
+}
+
+func (s *echoServer) taskTemplate() {
+	repository := taskTemplateRepo.NewTaskTemplateRepository(s.db)
+	usecase := taskTemplateUsecase.NewTaskTemplateUsecase(repository)
+	handler := taskTemplateHandler.NewTaskTemplateHandler(usecase)
+
+	// create recurring task template
+	s.gr.POST("/task-templates", handler.CreateTemplateHandler, SuperAdminOrAdminMiddleware)
+
+	// get all task templates
+	s.gr.GET("/task-templates", handler.GetAllTemplatesHandler, SuperAdminOrAdminMiddleware)
+
+	// get task template by id
+	s.gr.GET("/task-templates/:templateId", handler.GetTemplateByIdHandler, SuperAdminOrAdminMiddleware)
+
+	// preview upcoming instances of a template
+	s.gr.GET("/task-templates/:templateId/preview", handler.PreviewTemplateHandler, SuperAdminOrAdminMiddleware)
+
+	// pause or resume a template
+	s.gr.PUT("/task-templates/:templateId/pause", handler.PauseTemplateHandler, SuperAdminOrAdminMiddleware)
+	s.gr.PUT("/task-templates/:templateId/resume", handler.ResumeTemplateHandler, SuperAdminOrAdminMiddleware)
+
+	// delete task template
+	s.gr.DELETE("/task-templates/:templateId", handler.DeleteTemplateHandler, SuperAdminOrAdminMiddleware)
 }
 
 func (s *echoServer) userTask() {
 	repository := userTaskRepo.NewUserTaskRepository(s.db)
-	usecase := userTaskUsecase.NewUserTaskUsecase(repository)
+	usecase := userTaskUsecase.NewUserTaskUsecase(repository, s.streakUsecase())
 	handler := userTaskHandler.NewUserTaskHandler(usecase)
 
 	// get all tasks
@@ -259,11 +318,17 @@ func (s *echoServer) userTask() {
 
 	// get user task rejected by user current
 	s.gr.GET("/user/tasks/rejected/:userTaskId", handler.GetUserTaskRejectedByUserIdHandler, UserMiddleware)
+
+	// get every submission of a user task with the review it received
+	s.gr.GET("/user-current/tasks/:userTaskId/submissions", handler.GetSubmissionHistoryHandler, UserMiddleware)
+
+	// withdraw from a task, the user can join the challenge again later
+	s.gr.POST("/user-current/tasks/:userTaskId/withdraw", handler.WithdrawUserTaskHandler, UserMiddleware)
 }
 
 func (s *echoServer) approvalTask() {
 	repository := approvalTaskRepo.NewApprovalTaskRepositoryImpl(s.db)
-	usecase := approvalTaskUsecase.NewApprovalTaskUsecase(repository)
+	usecase := approvalTaskUsecase.NewApprovalTaskUsecase(repository, s.webhookPublisher(), s.streakUsecase())
 	handler := approvalTaskHandler.NewApprovalTaskHandler(usecase)
 
 	// get all pagination user task
@@ -275,6 +340,18 @@ func (s *echoServer) approvalTask() {
 	// reject user task
 	s.gr.PUT("/reject-tasks/:userTaskId", handler.RejectUserTaskHandler, SuperAdminOrAdminMiddleware)
 
+	// approve many user tasks at once, reporting the result of every item
+	s.gr.POST("/approve-tasks/bulk", handler.BulkApproveUserTaskHandler, SuperAdminOrAdminMiddleware)
+
+	// reject many user tasks at once with a shared or per item reason
+	s.gr.POST("/reject-tasks/bulk", handler.BulkRejectUserTaskHandler, SuperAdminOrAdminMiddleware)
+
+	// claim a user task so other admins do not review it at the same time
+	s.gr.PUT("/approval-tasks/:userTaskId/claim", handler.ClaimUserTaskHandler, SuperAdminOrAdminMiddleware)
+
+	// release a claimed user task back to the queue
+	s.gr.PUT("/approval-tasks/:userTaskId/release", handler.ReleaseUserTaskHandler, SuperAdminOrAdminMiddleware)
+
 	// get user task details
 	s.gr.GET("/user-task/:userTaskId", handler.GetUserTaskDetailsHandler, SuperAdminOrAdminMiddleware)
 }
@@ -284,6 +361,9 @@ func (s *echoServer) manageAchievement() {
 	usecase := achievementUsecase.NewManageAchievementUsecase(repository)
 	handler := achievementHandler.NewManageAchievementHandler(usecase)
 
+	// create achievement
+	s.gr.POST("/achievements", handler.CreateAchievementHandler, SuperAdminOrAdminMiddleware)
+
 	// get all achievement
 	s.gr.GET("/achievements", handler.GetAllAchievementHandler, SuperAdminOrAdminMiddleware)
 
@@ -295,6 +375,13 @@ func (s *echoServer) manageAchievement() {
 
 	// delete achievement
 	s.gr.DELETE("/achievements/:achievementId", handler.DeleteAchievementHandler, SuperAdminOrAdminMiddleware)
+
+	// recompute the achievement of every user in the background
+	s.gr.POST("/achievement-level-jobs", handler.StartLevelRecomputeHandler, SuperAdminOrAdminMiddleware)
+
+	// follow the level recompute jobs started by the current admin
+	s.gr.GET("/achievement-level-jobs", handler.GetLevelJobsHandler, SuperAdminOrAdminMiddleware)
+	s.gr.GET("/achievement-level-jobs/:jobId", handler.GetLevelJobHandler, SuperAdminOrAdminMiddleware)
 }
 
 func (s *echoServer) customDataHandler() {
@@ -395,8 +482,19 @@ func (s *echoServer) leaderboardHandler() {
 	usecase := leaderboardUsecase.NewLeaderboardUsecase(repository)
 	handler := leaderboardHandler.NewLeaderboardHandler(usecase)
 
-	// Get leaderboard
+	// Get weekly, monthly or all-time leaderboard, optionally per province and city
+	// (?period=&province=&city=&page=&limit=)
 	s.gr.GET("/leaderboard", handler.GetLeaderboardHandler, AllRoleMiddleware)
+
+	// Admin manage competitive seasons
+	s.gr.POST("/seasons", handler.CreateSeasonHandler, SuperAdminOrAdminMiddleware)
+	s.gr.PUT("/seasons/:seasonId", handler.UpdateSeasonHandler, SuperAdminOrAdminMiddleware)
+	s.gr.DELETE("/seasons/:seasonId", handler.DeleteSeasonHandler, SuperAdminOrAdminMiddleware)
+
+	// List seasons and view the standings of the running season or of a past one
+	s.gr.GET("/seasons", handler.GetSeasonsHandler, AllRoleMiddleware)
+	s.gr.GET("/seasons/active", handler.GetActiveSeasonStandingsHandler, AllRoleMiddleware)
+	s.gr.GET("/seasons/:seasonId", handler.GetSeasonStandingsHandler, AllRoleMiddleware)
 }
 
 func (s *echoServer) articleHandler() {
@@ -424,6 +522,9 @@ func (s *echoServer) articleHandler() {
 	// Update article by admin
 	s.gr.PUT("/article/:articleId", handler.UpdateArticle, SuperAdminOrAdminMiddleware)
 
+	// Admin move an article through draft, review, scheduled, published and archived
+	s.gr.PUT("/article/:articleId/status", handler.ChangeArticleStatus, SuperAdminOrAdminMiddleware)
+
 	// Delete article by admin
 	s.gr.DELETE("/article/:articleId", handler.DeleteArticle, SuperAdminOrAdminMiddleware)
 
@@ -439,7 +540,7 @@ func (s *echoServer) articleHandler() {
 
 func (s *echoServer) homepageHandler() {
 	repository := homepageRepo.NewHomepageRepository(s.db)
-	usecase := homepageUsecase.NewHomepageUsecase(repository)
+	usecase := homepageUsecase.NewHomepageUsecase(repository, s.streakUsecase(), leaderboardUsecase.NewLeaderboardUsecase(leaderboardRepo.NewLeaderboardRepository(s.db)))
 	handler := homepageHandler.NewHomePageHandler(usecase)
 
 	// Get homepage
@@ -454,3 +555,92 @@ func (s *echoServer) dashboardHandler() {
 	// Get dashboard
 	s.gr.GET("/dashboards", handler.GetDashboardHandler, SuperAdminOrAdminMiddleware)
 }
+
+func (s *echoServer) webhookPublisher() webhook.Publisher {
+	repository := webhookRepo.NewWebhookRepository(s.db)
+	return webhookUsecase.NewWebhookUsecase(repository, webhook.NewHTTPSender(webhook.SendTimeout))
+}
+
+func (s *echoServer) webhookHandler() {
+	repository := webhookRepo.NewWebhookRepository(s.db)
+	usecase := webhookUsecase.NewWebhookUsecase(repository, webhook.NewHTTPSender(webhook.SendTimeout))
+	handler := webhookHandler.NewWebhookHandler(usecase)
+
+	// Admin manage webhook subscriptions
+	s.gr.POST("/webhooks", handler.NewSubscription, SuperAdminOrAdminMiddleware)
+	s.gr.GET("/webhooks", handler.GetAllSubscriptions, SuperAdminOrAdminMiddleware)
+	s.gr.GET("/webhooks/:webhookId", handler.GetSubscriptionByID, SuperAdminOrAdminMiddleware)
+	s.gr.PUT("/webhooks/:webhookId", handler.UpdateSubscription, SuperAdminOrAdminMiddleware)
+	s.gr.DELETE("/webhooks/:webhookId", handler.DeleteSubscription, SuperAdminOrAdminMiddleware)
+
+	// Admin delivery log and replay
+	s.gr.GET("/webhooks/:webhookId/deliveries", handler.GetDeliveries, SuperAdminOrAdminMiddleware)
+	s.gr.POST("/webhook-deliveries/:deliveryId/replay", handler.ReplayDelivery, SuperAdminOrAdminMiddleware)
+}
+
+func (s *echoServer) pointHandler() {
+	repository := pointRepo.NewPointRepository(s.db)
+	usecase := pointUsecase.NewPointUsecase(repository)
+	handler := pointHandler.NewPointHandler(usecase)
+
+	// Admin view the point ledger of a user
+	s.gr.GET("/users/:userId/points", handler.GetUserHistory, SuperAdminOrAdminMiddleware)
+
+	// Admin reverse a point entry
+	s.gr.POST("/points/:entryId/reverse", handler.ReverseEntry, SuperAdminOrAdminMiddleware)
+
+	// Super admin credit or debit the points of a user
+	s.gr.POST("/users/:userId/points/adjustments", handler.AdjustUserPoint, SuperAdminMiddleware)
+
+	// Super admin list point adjustments of a user
+	s.gr.GET("/users/:userId/points/adjustments", handler.GetUserAdjustments, SuperAdminMiddleware)
+
+	// Super admin list point adjustments of all users
+	s.gr.GET("/points/adjustments", handler.GetAllAdjustments, SuperAdminMiddleware)
+}
+
+func (s *echoServer) rewardHandler() {
+	repository := rewardRepo.NewRewardRepository(s.db)
+	usecase := rewardUsecase.NewRewardUsecase(repository)
+	handler := rewardHandler.NewRewardHandler(usecase)
+
+	// Admin manage the reward catalog
+	s.gr.POST("/rewards", handler.NewReward, SuperAdminOrAdminMiddleware)
+	s.gr.GET("/rewards", handler.GetAllRewards, SuperAdminOrAdminMiddleware)
+	s.gr.GET("/rewards/:rewardId", handler.GetRewardByID, SuperAdminOrAdminMiddleware)
+	s.gr.PUT("/rewards/:rewardId", handler.UpdateReward, SuperAdminOrAdminMiddleware)
+	s.gr.DELETE("/rewards/:rewardId", handler.DeleteReward, SuperAdminOrAdminMiddleware)
+	s.gr.POST("/rewards/upload", handler.UploadImage, SuperAdminOrAdminMiddleware)
+
+	// User browse and redeem rewards
+	s.gr.GET("/user-current/rewards", handler.GetAvailableRewards, UserMiddleware)
+	s.gr.POST("/user-current/rewards/:rewardId/redeem", handler.Redeem, UserMiddleware)
+	s.gr.GET("/user-current/redemptions", handler.GetUserRedemptions, UserMiddleware)
+
+	// Admin fulfilment queue
+	s.gr.GET("/redemptions", handler.GetRedemptionQueue, SuperAdminOrAdminMiddleware)
+	s.gr.PUT("/redemptions/:redemptionId/fulfill", handler.Fulfill, SuperAdminOrAdminMiddleware)
+	s.gr.PUT("/redemptions/:redemptionId/cancel", handler.Cancel, SuperAdminOrAdminMiddleware)
+}
+
+// streakUsecase records qualifying actions for the modules that produce them
+// and reads streaks for the profile and homepage.
+func (s *echoServer) streakUsecase() streak.StreakUsecase {
+	return streakUsecase.NewStreakUsecase(streakRepo.NewStreakRepository(s.db))
+}
+
+func (s *echoServer) streakHandler() {
+	handler := streakHandler.NewStreakHandler(s.streakUsecase())
+
+	// User view their daily and weekly streak
+	s.gr.GET("/user-current/streaks", handler.GetUserStreak, UserMiddleware)
+
+	// Admin manage how often a missed day or week is forgiven
+	s.gr.GET("/streak-rules", handler.GetRules, SuperAdminOrAdminMiddleware)
+	s.gr.PUT("/streak-rules/:period", handler.UpdateRule, SuperAdminOrAdminMiddleware)
+
+	// Admin manage bonus points paid at streak milestones
+	s.gr.GET("/streak-milestones", handler.GetMilestones, SuperAdminOrAdminMiddleware)
+	s.gr.POST("/streak-milestones", handler.NewMilestone, SuperAdminOrAdminMiddleware)
+	s.gr.DELETE("/streak-milestones/:milestoneId", handler.DeleteMilestone, SuperAdminOrAdminMiddleware)
+}
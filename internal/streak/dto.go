@@ -0,0 +1,32 @@
+package streak
+
+type RuleInput struct {
+	FreezeEvery *int `json:"freeze_every" validate:"required,min=0,max=365"`
+}
+
+type MilestoneInput struct {
+	Period     string `json:"period" validate:"required,oneof=daily weekly"`
+	Length     int    `json:"length" validate:"required,min=2"`
+	BonusPoint int    `json:"bonus_point" validate:"required,min=1"`
+}
+
+type RuleResponse struct {
+	Period      string `json:"period"`
+	FreezeEvery int    `json:"freeze_every"`
+}
+
+type PeriodSummary struct {
+	Current         int     `json:"current"`
+	Longest         int     `json:"longest"`
+	LastActiveOn    *string `json:"last_active_on"`
+	FreezeAvailable bool    `json:"freeze_available"`
+	NextMilestone   *int    `json:"next_milestone"`
+}
+
+// Summary is the streak state of a user, evaluated on their local date.
+type Summary struct {
+	Timezone string        `json:"timezone"`
+	Today    string        `json:"today"`
+	Daily    PeriodSummary `json:"daily"`
+	Weekly   PeriodSummary `json:"weekly"`
+}
@@ -0,0 +1,201 @@
+package streak
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// periods a streak is counted in
+const (
+	PeriodDaily  = "daily"
+	PeriodWeekly = "weekly"
+)
+
+// actions that keep a streak alive
+const (
+	ActionTaskStep     = "task_step"
+	ActionReport       = "report"
+	ActionTaskApproval = "task_approval"
+)
+
+var Periods = []string{PeriodDaily, PeriodWeekly}
+
+// DefaultFreezeEvery is used for a period without a stored rule.
+var DefaultFreezeEvery = map[string]int{
+	PeriodDaily:  7,
+	PeriodWeekly: 4,
+}
+
+// struct
+
+// Streak counts consecutive days or weeks in which a user did at least one
+// qualifying action. Dates are local dates of the user stored at midnight UTC.
+type Streak struct {
+	UserID       string     `json:"user_id" gorm:"primaryKey;type:varchar(20)"`
+	Period       string     `json:"period" gorm:"primaryKey;type:varchar(10)"`
+	Current      int        `json:"current"`
+	Longest      int        `json:"longest"`
+	StartedOn    *time.Time `json:"started_on" gorm:"type:date"`
+	LastActiveOn *time.Time `json:"last_active_on" gorm:"type:date"`
+	FreezeUsedOn *time.Time `json:"freeze_used_on" gorm:"type:date"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (Streak) TableName() string {
+	return "user_streaks"
+}
+
+// Activity is one qualifying action. An action is counted once, so repeating
+// the same source does not move a streak.
+type Activity struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     string    `json:"user_id" gorm:"index;type:varchar(20)"`
+	Action     string    `json:"action" gorm:"type:varchar(20);uniqueIndex:idx_streak_activity_source"`
+	SourceID   string    `json:"source_id" gorm:"type:varchar(50);uniqueIndex:idx_streak_activity_source"`
+	OccurredAt time.Time `json:"occurred_at"`
+	LocalDate  time.Time `json:"local_date" gorm:"type:date"`
+}
+
+func (Activity) TableName() string {
+	return "streak_activities"
+}
+
+// Rule holds the freeze policy of a period. One missed day or week is forgiven
+// once every FreezeEvery days or weeks, zero turns freezes off.
+type Rule struct {
+	Period      string    `json:"period" gorm:"primaryKey;type:varchar(10)"`
+	FreezeEvery int       `json:"freeze_every"`
+	UpdatedBy   string    `json:"updated_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Rule) TableName() string {
+	return "streak_rules"
+}
+
+// Milestone pays BonusPoint when a streak reaches Length.
+type Milestone struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Period     string    `json:"period" gorm:"type:varchar(10);uniqueIndex:idx_streak_milestone"`
+	Length     int       `json:"length" gorm:"uniqueIndex:idx_streak_milestone"`
+	BonusPoint int       `json:"bonus_point"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (Milestone) TableName() string {
+	return "streak_milestones"
+}
+
+// Advance counts an active date. It reports false when the date falls in a day
+// or week that was already counted.
+func (s *Streak) Advance(date time.Time, freezeEvery int) bool {
+	unit := Unit(s.Period, date)
+
+	switch {
+	case s.LastActiveOn == nil:
+		s.Current = 1
+		s.StartedOn = &date
+	case unit <= Unit(s.Period, *s.LastActiveOn):
+		return false
+	case unit == Unit(s.Period, *s.LastActiveOn)+1:
+		s.Current++
+	case unit == Unit(s.Period, *s.LastActiveOn)+2 && s.FreezeAvailable(unit-1, freezeEvery):
+		missed := UnitDate(s.Period, unit-1)
+		s.FreezeUsedOn = &missed
+		s.Current++
+	default:
+		s.Current = 1
+		s.StartedOn = &date
+	}
+
+	s.LastActiveOn = &date
+	if s.Current > s.Longest {
+		s.Longest = s.Current
+	}
+
+	return true
+}
+
+// FreezeAvailable reports whether the missed unit can be covered by a freeze.
+func (s Streak) FreezeAvailable(missed int64, freezeEvery int) bool {
+	if freezeEvery <= 0 {
+		return false
+	}
+
+	return s.FreezeUsedOn == nil || missed-Unit(s.Period, *s.FreezeUsedOn) >= int64(freezeEvery)
+}
+
+// CurrentOn is the streak as seen on today. A streak stays alive through the
+// running day or week and through one missed unit a freeze can still cover.
+func (s Streak) CurrentOn(today time.Time, freezeEvery int) int {
+	if s.LastActiveOn == nil {
+		return 0
+	}
+
+	last := Unit(s.Period, *s.LastActiveOn)
+	switch Unit(s.Period, today) - last {
+	case 0, 1:
+		return s.Current
+	case 2:
+		if s.FreezeAvailable(last+1, freezeEvery) {
+			return s.Current
+		}
+	}
+
+	return 0
+}
+
+// interface
+type Recorder interface {
+	Record(userID, action, sourceID string, at time.Time) error
+}
+
+type Reader interface {
+	Summary(userID string) (*Summary, error)
+}
+
+type StreakRepository interface {
+	Transaction(fn func(repo StreakRepository) error) error
+
+	AddActivity(activity Activity) (bool, error)
+	FindUserProvince(userID string) (string, error)
+	LockStreak(userID, period string) (*Streak, error)
+	SaveStreak(streak Streak) error
+	FindStreaks(userID string) (*[]Streak, error)
+
+	FindRules() (*[]Rule, error)
+	SaveRule(rule Rule) error
+
+	FindMilestones() (*[]Milestone, error)
+	FindMilestone(period string, length int) (*Milestone, error)
+	FindMilestoneByID(milestoneID uint) (*Milestone, error)
+	CreateMilestone(milestone Milestone) (*Milestone, error)
+	DeleteMilestone(milestoneID uint) error
+
+	AwardBonus(userID string, milestone Milestone, startedOn time.Time) error
+}
+
+type StreakUsecase interface {
+	Recorder
+	Reader
+
+	GetRules() (*[]RuleResponse, error)
+	UpdateRule(period string, input RuleInput, adminID string) (*RuleResponse, error)
+
+	GetMilestones() (*[]Milestone, error)
+	NewMilestone(input MilestoneInput, adminID string) (*Milestone, error)
+	DeleteMilestone(milestoneID uint) error
+}
+
+type StreakHandler interface {
+	GetUserStreak(c echo.Context) error
+
+	GetRules(c echo.Context) error
+	UpdateRule(c echo.Context) error
+
+	GetMilestones(c echo.Context) error
+	NewMilestone(c echo.Context) error
+	DeleteMilestone(c echo.Context) error
+}
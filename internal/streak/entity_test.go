@@ -0,0 +1,169 @@
+package streak
+
+import (
+	"testing"
+	"time"
+)
+
+// onDay is the date n days after 2024-06-10, a Monday.
+func onDay(n int) time.Time {
+	return time.Date(2024, 6, 10+n, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStreakAdvance(t *testing.T) {
+	tests := []struct {
+		name        string
+		period      string
+		freezeEvery int
+		usedOn      *time.Time
+		active      []time.Time
+		wantCurrent int
+		wantLongest int
+		wantFreeze  *time.Time
+	}{
+		{
+			name:        "consecutive days",
+			period:      PeriodDaily,
+			freezeEvery: 7,
+			active:      []time.Time{onDay(0), onDay(1), onDay(2)},
+			wantCurrent: 3,
+			wantLongest: 3,
+		},
+		{
+			name:        "gap covered by a freeze",
+			period:      PeriodDaily,
+			freezeEvery: 7,
+			active:      []time.Time{onDay(0), onDay(1), onDay(3)},
+			wantCurrent: 3,
+			wantLongest: 3,
+			wantFreeze:  ptr(onDay(2)),
+		},
+		{
+			name:        "freeze used too recently",
+			period:      PeriodDaily,
+			freezeEvery: 7,
+			usedOn:      ptr(onDay(-3)),
+			active:      []time.Time{onDay(0), onDay(1), onDay(3)},
+			wantCurrent: 1,
+			wantLongest: 2,
+			wantFreeze:  ptr(onDay(-3)),
+		},
+		{
+			name:        "expired freeze is available again",
+			period:      PeriodDaily,
+			freezeEvery: 7,
+			usedOn:      ptr(onDay(-5)),
+			active:      []time.Time{onDay(0), onDay(1), onDay(3)},
+			wantCurrent: 3,
+			wantLongest: 3,
+			wantFreeze:  ptr(onDay(2)),
+		},
+		{
+			name:        "freezes turned off",
+			period:      PeriodDaily,
+			freezeEvery: 0,
+			active:      []time.Time{onDay(0), onDay(1), onDay(3)},
+			wantCurrent: 1,
+			wantLongest: 2,
+		},
+		{
+			name:        "two missed days break the streak",
+			period:      PeriodDaily,
+			freezeEvery: 7,
+			active:      []time.Time{onDay(0), onDay(1), onDay(4)},
+			wantCurrent: 1,
+			wantLongest: 2,
+		},
+		{
+			name:        "consecutive Monday weeks",
+			period:      PeriodWeekly,
+			freezeEvery: 4,
+			active:      []time.Time{onDay(0), onDay(13), onDay(14)},
+			wantCurrent: 3,
+			wantLongest: 3,
+		},
+		{
+			name:        "missed week covered by a freeze",
+			period:      PeriodWeekly,
+			freezeEvery: 4,
+			active:      []time.Time{onDay(6), onDay(20)},
+			wantCurrent: 2,
+			wantLongest: 2,
+			wantFreeze:  ptr(onDay(7)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Streak{Period: tt.period, FreezeUsedOn: tt.usedOn}
+			for _, date := range tt.active {
+				if !s.Advance(date, tt.freezeEvery) {
+					t.Fatalf("Advance(%s) was not counted", date.Format("2006-01-02"))
+				}
+			}
+
+			if s.Current != tt.wantCurrent || s.Longest != tt.wantLongest {
+				t.Errorf("current %d and longest %d, want %d and %d", s.Current, s.Longest, tt.wantCurrent, tt.wantLongest)
+			}
+			if !sameDate(s.FreezeUsedOn, tt.wantFreeze) {
+				t.Errorf("freeze used on %v, want %v", s.FreezeUsedOn, tt.wantFreeze)
+			}
+		})
+	}
+}
+
+func TestStreakAdvanceSameUnit(t *testing.T) {
+	daily := Streak{Period: PeriodDaily}
+	daily.Advance(onDay(0), 7)
+	if daily.Advance(onDay(0), 7) {
+		t.Error("the same day was counted twice")
+	}
+
+	// Monday and Sunday are the same week
+	weekly := Streak{Period: PeriodWeekly}
+	weekly.Advance(onDay(0), 4)
+	if weekly.Advance(onDay(6), 4) {
+		t.Error("the same week was counted twice")
+	}
+	if weekly.Current != 1 {
+		t.Errorf("current is %d, want 1", weekly.Current)
+	}
+}
+
+func TestStreakCurrentOn(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastActive  *time.Time
+		usedOn      *time.Time
+		freezeEvery int
+		today       time.Time
+		want        int
+	}{
+		{"never active", nil, nil, 7, onDay(0), 0},
+		{"active today", ptr(onDay(0)), nil, 7, onDay(0), 5},
+		{"running day", ptr(onDay(0)), nil, 7, onDay(1), 5},
+		{"missed day a freeze covers", ptr(onDay(0)), nil, 7, onDay(2), 5},
+		{"missed day after a recent freeze", ptr(onDay(0)), ptr(onDay(-2)), 7, onDay(2), 0},
+		{"missed day after an expired freeze", ptr(onDay(0)), ptr(onDay(-6)), 7, onDay(2), 5},
+		{"missed day with freezes off", ptr(onDay(0)), nil, 0, onDay(2), 0},
+		{"two missed days", ptr(onDay(0)), nil, 7, onDay(3), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Streak{Period: PeriodDaily, Current: 5, Longest: 5, LastActiveOn: tt.lastActive, FreezeUsedOn: tt.usedOn}
+			if got := s.CurrentOn(tt.today, tt.freezeEvery); got != tt.want {
+				t.Errorf("CurrentOn(%s) = %d, want %d", tt.today.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,122 @@
+package streak
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sawalreverr/recything/internal/helper"
+	stk "github.com/sawalreverr/recything/internal/streak"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+type streakHandler struct {
+	streakUsecase stk.StreakUsecase
+}
+
+func NewStreakHandler(uc stk.StreakUsecase) stk.StreakHandler {
+	return &streakHandler{streakUsecase: uc}
+}
+
+func (h *streakHandler) GetUserStreak(c echo.Context) error {
+	userID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	summary, err := h.streakUsecase.Summary(userID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrUserNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", summary)
+}
+
+// Rule
+func (h *streakHandler) GetRules(c echo.Context) error {
+	rules, err := h.streakUsecase.GetRules()
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", rules)
+}
+
+func (h *streakHandler) UpdateRule(c echo.Context) error {
+	var request stk.RuleInput
+
+	adminID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	rule, err := h.streakUsecase.UpdateRule(c.Param("period"), request, adminID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrStreakPeriod) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "streak rule updated!", rule)
+}
+
+// Milestone
+func (h *streakHandler) GetMilestones(c echo.Context) error {
+	milestones, err := h.streakUsecase.GetMilestones()
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", milestones)
+}
+
+func (h *streakHandler) NewMilestone(c echo.Context) error {
+	var request stk.MilestoneInput
+
+	adminID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	milestone, err := h.streakUsecase.NewMilestone(request, adminID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrStreakMilestoneExists) {
+			return helper.ErrorHandler(c, http.StatusConflict, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusCreated, "streak milestone created!", milestone)
+}
+
+func (h *streakHandler) DeleteMilestone(c echo.Context) error {
+	milestoneID, err := strconv.Atoi(c.Param("milestoneId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrStreakMilestoneNotFound.Error())
+	}
+
+	if err := h.streakUsecase.DeleteMilestone(uint(milestoneID)); err != nil {
+		if errors.Is(err, pkg.ErrStreakMilestoneNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "streak milestone deleted!", nil)
+}
@@ -0,0 +1,167 @@
+package streak
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/database"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	pointRepo "github.com/sawalreverr/recything/internal/point/repository"
+	stk "github.com/sawalreverr/recything/internal/streak"
+	"github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type streakRepository struct {
+	DB database.Database
+}
+
+func NewStreakRepository(db database.Database) stk.StreakRepository {
+	return &streakRepository{DB: db}
+}
+
+func (r *streakRepository) Transaction(fn func(repo stk.StreakRepository) error) error {
+	return r.DB.Transaction(func(tx database.Database) error {
+		return fn(&streakRepository{DB: tx})
+	})
+}
+
+// AddActivity stores an activity and reports false when its source was
+// already recorded.
+func (r *streakRepository) AddActivity(activity stk.Activity) (bool, error) {
+	result := r.DB.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&activity)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *streakRepository) FindUserProvince(userID string) (string, error) {
+	var found user.User
+	if err := r.DB.GetDB().Select("province").Where("id = ?", userID).First(&found).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", pkg.ErrUserNotFound
+		}
+		return "", err
+	}
+
+	return found.Province, nil
+}
+
+// LockStreak returns the streak of a user in a period, creating an empty one
+// first, and keeps it locked until the surrounding transaction ends.
+func (r *streakRepository) LockStreak(userID, period string) (*stk.Streak, error) {
+	if err := r.DB.GetDB().Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&stk.Streak{UserID: userID, Period: period}).Error; err != nil {
+		return nil, err
+	}
+
+	var streak stk.Streak
+	if err := r.DB.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND period = ?", userID, period).
+		First(&streak).Error; err != nil {
+		return nil, err
+	}
+
+	return &streak, nil
+}
+
+func (r *streakRepository) SaveStreak(streak stk.Streak) error {
+	if err := r.DB.GetDB().Save(&streak).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *streakRepository) FindStreaks(userID string) (*[]stk.Streak, error) {
+	var streaks []stk.Streak
+	if err := r.DB.GetDB().Where("user_id = ?", userID).Find(&streaks).Error; err != nil {
+		return nil, err
+	}
+
+	return &streaks, nil
+}
+
+func (r *streakRepository) FindRules() (*[]stk.Rule, error) {
+	var rules []stk.Rule
+	if err := r.DB.GetDB().Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+func (r *streakRepository) SaveRule(rule stk.Rule) error {
+	if err := r.DB.GetDB().Save(&rule).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *streakRepository) FindMilestones() (*[]stk.Milestone, error) {
+	var milestones []stk.Milestone
+	if err := r.DB.GetDB().Order("period asc, length asc").Find(&milestones).Error; err != nil {
+		return nil, err
+	}
+
+	return &milestones, nil
+}
+
+func (r *streakRepository) FindMilestone(period string, length int) (*stk.Milestone, error) {
+	var milestone stk.Milestone
+	if err := r.DB.GetDB().Where("period = ? AND length = ?", period, length).First(&milestone).Error; err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+func (r *streakRepository) FindMilestoneByID(milestoneID uint) (*stk.Milestone, error) {
+	var milestone stk.Milestone
+	if err := r.DB.GetDB().Where("id = ?", milestoneID).First(&milestone).Error; err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+func (r *streakRepository) CreateMilestone(milestone stk.Milestone) (*stk.Milestone, error) {
+	if err := r.DB.GetDB().Create(&milestone).Error; err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+func (r *streakRepository) DeleteMilestone(milestoneID uint) error {
+	if err := r.DB.GetDB().Delete(&stk.Milestone{}, milestoneID).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AwardBonus credits a milestone to the ledger. The source names the streak
+// run, so a user earns each milestone once per run even when an activity is
+// replayed.
+func (r *streakRepository) AwardBonus(userID string, milestone stk.Milestone, startedOn time.Time) error {
+	_, err := pointRepo.NewPointRepository(r.DB).Append(pnt.Entry{
+		UserID:      userID,
+		Amount:      milestone.BonusPoint,
+		BasePoint:   milestone.BonusPoint,
+		SourceType:  pnt.SourceStreakBonus,
+		SourceID:    fmt.Sprintf("%s:%s:%s:%d", userID, milestone.Period, startedOn.Format("2006-01-02"), milestone.Length),
+		Description: fmt.Sprintf("%d %s streak bonus", milestone.Length, milestone.Period),
+	})
+	if errors.Is(err, pkg.ErrPointEntryExists) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,245 @@
+package streak
+
+import (
+	"errors"
+	"time"
+
+	stk "github.com/sawalreverr/recything/internal/streak"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+)
+
+type streakUsecase struct {
+	streakRepository stk.StreakRepository
+}
+
+func NewStreakUsecase(repo stk.StreakRepository) stk.StreakUsecase {
+	return &streakUsecase{streakRepository: repo}
+}
+
+// Record counts a qualifying action on the local date of the user and pays
+// out any milestone the streak reaches. Recording the same source twice has
+// no effect.
+func (uc *streakUsecase) Record(userID, action, sourceID string, at time.Time) error {
+	return uc.streakRepository.Transaction(func(repo stk.StreakRepository) error {
+		province, err := repo.FindUserProvince(userID)
+		if err != nil {
+			return err
+		}
+		date := stk.LocalDate(at, stk.Zone(province))
+
+		added, err := repo.AddActivity(stk.Activity{
+			UserID:     userID,
+			Action:     action,
+			SourceID:   sourceID,
+			OccurredAt: at,
+			LocalDate:  date,
+		})
+		if err != nil || !added {
+			return err
+		}
+
+		freezeEvery, err := freezeRules(repo)
+		if err != nil {
+			return err
+		}
+
+		for _, period := range stk.Periods {
+			streak, err := repo.LockStreak(userID, period)
+			if err != nil {
+				return err
+			}
+
+			if !streak.Advance(date, freezeEvery[period]) {
+				continue
+			}
+			if err := repo.SaveStreak(*streak); err != nil {
+				return err
+			}
+
+			milestone, err := repo.FindMilestone(period, streak.Current)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := repo.AwardBonus(userID, *milestone, *streak.StartedOn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Summary evaluates the streaks of a user on today's date in their zone, so a
+// streak that lapsed without new activity already shows as zero.
+func (uc *streakUsecase) Summary(userID string) (*stk.Summary, error) {
+	province, err := uc.streakRepository.FindUserProvince(userID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	zone := stk.Zone(province)
+	today := stk.LocalDate(time.Now(), zone)
+
+	freezeEvery, err := freezeRules(uc.streakRepository)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	streaks, err := uc.streakRepository.FindStreaks(userID)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	milestones, err := uc.streakRepository.FindMilestones()
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	byPeriod := map[string]stk.Streak{}
+	for _, streak := range *streaks {
+		byPeriod[streak.Period] = streak
+	}
+
+	summary := stk.Summary{
+		Timezone: zone.String(),
+		Today:    today.Format("2006-01-02"),
+	}
+	for _, period := range stk.Periods {
+		streak, ok := byPeriod[period]
+		if !ok {
+			streak = stk.Streak{UserID: userID, Period: period}
+		}
+
+		data := stk.PeriodSummary{
+			Current:         streak.CurrentOn(today, freezeEvery[period]),
+			Longest:         streak.Longest,
+			FreezeAvailable: streak.FreezeAvailable(stk.Unit(period, today), freezeEvery[period]),
+		}
+		if streak.LastActiveOn != nil {
+			lastActiveOn := streak.LastActiveOn.Format("2006-01-02")
+			data.LastActiveOn = &lastActiveOn
+		}
+		for _, milestone := range *milestones {
+			if milestone.Period == period && milestone.Length > data.Current {
+				length := milestone.Length
+				data.NextMilestone = &length
+				break
+			}
+		}
+
+		if period == stk.PeriodDaily {
+			summary.Daily = data
+		} else {
+			summary.Weekly = data
+		}
+	}
+
+	return &summary, nil
+}
+
+// Rule
+func (uc *streakUsecase) GetRules() (*[]stk.RuleResponse, error) {
+	freezeEvery, err := freezeRules(uc.streakRepository)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	var rules []stk.RuleResponse
+	for _, period := range stk.Periods {
+		rules = append(rules, stk.RuleResponse{Period: period, FreezeEvery: freezeEvery[period]})
+	}
+
+	return &rules, nil
+}
+
+func (uc *streakUsecase) UpdateRule(period string, input stk.RuleInput, adminID string) (*stk.RuleResponse, error) {
+	if !validPeriod(period) {
+		return nil, pkg.ErrStreakPeriod
+	}
+
+	if err := uc.streakRepository.SaveRule(stk.Rule{
+		Period:      period,
+		FreezeEvery: *input.FreezeEvery,
+		UpdatedBy:   adminID,
+	}); err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	return &stk.RuleResponse{Period: period, FreezeEvery: *input.FreezeEvery}, nil
+}
+
+// Milestone
+func (uc *streakUsecase) GetMilestones() (*[]stk.Milestone, error) {
+	milestones, err := uc.streakRepository.FindMilestones()
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	return milestones, nil
+}
+
+func (uc *streakUsecase) NewMilestone(input stk.MilestoneInput, adminID string) (*stk.Milestone, error) {
+	if _, err := uc.streakRepository.FindMilestone(input.Period, input.Length); err == nil {
+		return nil, pkg.ErrStreakMilestoneExists
+	}
+
+	milestone, err := uc.streakRepository.CreateMilestone(stk.Milestone{
+		Period:     input.Period,
+		Length:     input.Length,
+		BonusPoint: input.BonusPoint,
+		CreatedBy:  adminID,
+	})
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	return milestone, nil
+}
+
+func (uc *streakUsecase) DeleteMilestone(milestoneID uint) error {
+	if _, err := uc.streakRepository.FindMilestoneByID(milestoneID); err != nil {
+		return pkg.ErrStreakMilestoneNotFound
+	}
+
+	if err := uc.streakRepository.DeleteMilestone(milestoneID); err != nil {
+		return pkg.ErrStatusInternalError
+	}
+
+	return nil
+}
+
+// freezeRules returns the freeze interval of every period, using the default
+// for periods an admin never configured.
+func freezeRules(repo stk.StreakRepository) (map[string]int, error) {
+	rules, err := repo.FindRules()
+	if err != nil {
+		return nil, err
+	}
+
+	freezeEvery := map[string]int{}
+	for period, every := range stk.DefaultFreezeEvery {
+		freezeEvery[period] = every
+	}
+	for _, rule := range *rules {
+		freezeEvery[rule.Period] = rule.FreezeEvery
+	}
+
+	return freezeEvery, nil
+}
+
+func validPeriod(period string) bool {
+	for _, p := range stk.Periods {
+		if p == period {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package streak
+
+import (
+	"strings"
+	"time"
+)
+
+// Indonesian time zones
+var (
+	WIB  = time.FixedZone("WIB", 7*60*60)
+	WITA = time.FixedZone("WITA", 8*60*60)
+	WIT  = time.FixedZone("WIT", 9*60*60)
+)
+
+var witaProvinces = map[string]bool{
+	"bali":                true,
+	"nusa tenggara barat": true,
+	"ntb":                 true,
+	"nusa tenggara timur": true,
+	"ntt":                 true,
+	"kalimantan selatan":  true,
+	"kalimantan timur":    true,
+	"kalimantan utara":    true,
+	"sulawesi utara":      true,
+	"sulawesi tengah":     true,
+	"sulawesi selatan":    true,
+	"sulawesi tenggara":   true,
+	"sulawesi barat":      true,
+	"gorontalo":           true,
+}
+
+var witProvinces = map[string]bool{
+	"maluku":           true,
+	"maluku utara":     true,
+	"papua":            true,
+	"papua barat":      true,
+	"papua barat daya": true,
+	"papua selatan":    true,
+	"papua tengah":     true,
+	"papua pegunungan": true,
+}
+
+// Zone returns the time zone of a province. Unknown or empty provinces fall
+// back to WIB.
+func Zone(province string) *time.Location {
+	name := strings.ToLower(strings.TrimSpace(province))
+	name = strings.TrimPrefix(name, "provinsi ")
+
+	switch {
+	case witProvinces[name]:
+		return WIT
+	case witaProvinces[name]:
+		return WITA
+	default:
+		return WIB
+	}
+}
+
+// LocalDate is the calendar date of t in loc, at midnight UTC.
+func LocalDate(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Unit numbers the days or Monday-based weeks of a period so that
+// consecutive units differ by one.
+func Unit(period string, date time.Time) int64 {
+	day := date.Unix() / 86400
+	if period == PeriodWeekly {
+		// 1970-01-01 was a Thursday
+		return (day + 3) / 7
+	}
+
+	return day
+}
+
+// UnitDate is the first date of a unit.
+func UnitDate(period string, unit int64) time.Time {
+	day := unit
+	if period == PeriodWeekly {
+		day = unit*7 - 3
+	}
+
+	return time.Unix(day*86400, 0).UTC()
+}
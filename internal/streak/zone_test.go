@@ -0,0 +1,93 @@
+package streak
+
+import (
+	"testing"
+	"time"
+)
+
+func TestZone(t *testing.T) {
+	tests := []struct {
+		province string
+		want     *time.Location
+	}{
+		{"DKI Jakarta", WIB},
+		{"Jawa Barat", WIB},
+		{"Sumatera Utara", WIB},
+		{"Bali", WITA},
+		{"NTB", WITA},
+		{"Sulawesi Selatan", WITA},
+		{"Maluku", WIT},
+		{"Papua Pegunungan", WIT},
+		{"Provinsi Papua Barat", WIT},
+		{"  kalimantan timur ", WITA},
+		{"", WIB},
+		{"Atlantis", WIB},
+	}
+	for _, tt := range tests {
+		if got := Zone(tt.province); got != tt.want {
+			t.Errorf("Zone(%q) = %v, want %v", tt.province, got, tt.want)
+		}
+	}
+}
+
+func TestLocalDateAcrossZones(t *testing.T) {
+	// 23:30 in Jakarta is already 01:30 the next day in Jayapura
+	at := time.Date(2024, 6, 10, 16, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		loc  *time.Location
+		want time.Time
+	}{
+		{WIB, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)},
+		{WITA, time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)},
+		{WIT, time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := LocalDate(at, tt.loc); !got.Equal(tt.want) {
+			t.Errorf("LocalDate(%v, %v) = %v, want %v", at, tt.loc, got, tt.want)
+		}
+	}
+
+	wib := Unit(PeriodDaily, LocalDate(at, WIB))
+	wit := Unit(PeriodDaily, LocalDate(at, WIT))
+	if wit != wib+1 {
+		t.Errorf("the same moment is day %d in WIB and day %d in WIT, want consecutive days", wib, wit)
+	}
+}
+
+func TestUnit(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		period string
+		date   time.Time
+		want   int64
+	}{
+		{"epoch day", PeriodDaily, date(1970, 1, 1), 0},
+		{"next day", PeriodDaily, date(1970, 1, 2), 1},
+		{"epoch is a Thursday in week 0", PeriodWeekly, date(1970, 1, 1), 0},
+		{"Sunday closes week 0", PeriodWeekly, date(1970, 1, 4), 0},
+		{"Monday opens week 1", PeriodWeekly, date(1970, 1, 5), 1},
+		{"(day+3)/7 for a Monday", PeriodWeekly, date(2024, 6, 10), (19884 + 3) / 7},
+		{"the Sunday after is the same week", PeriodWeekly, date(2024, 6, 16), (19884 + 3) / 7},
+		{"the Sunday before is the previous week", PeriodWeekly, date(2024, 6, 9), (19884+3)/7 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unit(tt.period, tt.date); got != tt.want {
+				t.Errorf("Unit(%s, %s) = %d, want %d", tt.period, tt.date.Format("2006-01-02 Mon"), got, tt.want)
+			}
+		})
+	}
+
+	// a week starts on its Monday
+	for _, day := range []int{10, 13, 16} {
+		unit := Unit(PeriodWeekly, date(2024, 6, day))
+		if got := UnitDate(PeriodWeekly, unit); !got.Equal(date(2024, 6, 10)) {
+			t.Errorf("week of 2024-06-%02d starts on %s, want 2024-06-10", day, got.Format("2006-01-02"))
+		}
+	}
+}
@@ -1,5 +1,29 @@
 package dto
 
+import "time"
+
 type RejectUserTaskRequest struct {
 	Reason string `json:"reason" validate:"required"`
 }
+
+type BulkApproveUserTaskRequest struct {
+	UserTaskIds []string `json:"user_task_ids" validate:"required,min=1,max=100"`
+}
+
+type BulkRejectUserTaskRequest struct {
+	UserTaskIds []string `json:"user_task_ids" validate:"required,min=1,max=100"`
+	// Reason is used for every user task without its own entry in Reasons.
+	Reason  string            `json:"reason"`
+	Reasons map[string]string `json:"reasons"`
+}
+
+// ApprovalTaskFilter narrows the approval queue, empty fields are ignored.
+type ApprovalTaskFilter struct {
+	StatusAccept    string
+	TaskChallengeId string
+	UserId          string
+	SubmittedFrom   time.Time
+	SubmittedTo     time.Time
+	Search          string
+	Claimed         string
+}
@@ -16,11 +16,20 @@ type DataUser struct {
 }
 
 type DataUserTask struct {
-	Id            string    `json:"id"`
-	StatusAccept  string    `json:"status_accept"`
-	Point         int       `json:"point"`
-	TaskChallenge DataTasks `json:"task"`
-	User          DataUser  `json:"user"`
+	Id            string     `json:"id"`
+	StatusAccept  string     `json:"status_accept"`
+	Point         int        `json:"point"`
+	SubmittedAt   *time.Time `json:"submitted_at"`
+	TaskChallenge DataTasks  `json:"task"`
+	User          DataUser   `json:"user"`
+	Claim         *DataClaim `json:"claim"`
+}
+
+// DataClaim shows the admin currently reviewing a submission.
+type DataClaim struct {
+	AdminId      string    `json:"admin_id"`
+	Name         string    `json:"name"`
+	ClaimedUntil time.Time `json:"claimed_until"`
 }
 
 type GetUserTaskPagination struct {
@@ -34,13 +43,39 @@ type GetUserTaskPagination struct {
 }
 
 type GetUserTaskDetailsResponse struct {
-	Id          string        `json:"id"`
-	TitleTask   string        `json:"title_task"`
-	StartDate   time.Time     `json:"start_date"`
-	EndDate     time.Time     `json:"end_date"`
-	UserName    string        `json:"user_name"`
-	Images      []*DataImages `json:"images"`
-	Description string        `json:"description_image"`
+	Id          string              `json:"id"`
+	TitleTask   string              `json:"title_task"`
+	StartDate   time.Time           `json:"start_date"`
+	EndDate     time.Time           `json:"end_date"`
+	UserName    string              `json:"user_name"`
+	SubmittedAt *time.Time          `json:"submitted_at"`
+	Claim       *DataClaim          `json:"claim"`
+	Images      []*DataImages       `json:"images"`
+	Description string              `json:"description_image"`
+	Steps       []*DataStepEvidence `json:"steps"`
+	Submissions []*DataSubmission   `json:"submissions"`
+}
+
+type DataSubmission struct {
+	Attempt      int           `json:"attempt"`
+	Description  string        `json:"description"`
+	Images       []*DataImages `json:"images"`
+	SubmittedAt  time.Time     `json:"submitted_at"`
+	Decision     string        `json:"decision"`
+	Reason       string        `json:"reason"`
+	ReviewerId   *string       `json:"reviewer_id"`
+	ReviewerName string        `json:"reviewer_name"`
+	ReviewedAt   *time.Time    `json:"reviewed_at"`
+}
+
+type DataStepEvidence struct {
+	Id            int           `json:"id"`
+	Position      int           `json:"position"`
+	Title         string        `json:"title"`
+	RequiresPhoto bool          `json:"requires_photo"`
+	Completed     bool          `json:"completed"`
+	Note          string        `json:"note"`
+	Images        []*DataImages `json:"images"`
 }
 
 type DataImages struct {
@@ -48,3 +83,27 @@ type DataImages struct {
 	ImageUrl   string    `json:"image_url"`
 	UploadedAt time.Time `json:"uploaded_at"`
 }
+
+type TaskApprovedEvent struct {
+	UserTaskId      string    `json:"user_task_id"`
+	UserId          string    `json:"user_id"`
+	TaskChallengeId string    `json:"task_challenge_id"`
+	Point           int       `json:"point"`
+	ApprovedAt      time.Time `json:"approved_at"`
+}
+
+// BulkItemResult is the outcome for one user task. Conflict is set when the
+// task was already decided or claimed by another review.
+type BulkItemResult struct {
+	UserTaskId string `json:"user_task_id"`
+	Success    bool   `json:"success"`
+	Conflict   bool   `json:"conflict,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type BulkResultResponse struct {
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
@@ -6,5 +6,9 @@ type ApprovalTaskHandler interface {
 	GetAllApprovalTaskPaginationHandler(c echo.Context) error
 	ApproveUserTaskHandler(c echo.Context) error
 	RejectUserTaskHandler(c echo.Context) error
+	BulkApproveUserTaskHandler(c echo.Context) error
+	BulkRejectUserTaskHandler(c echo.Context) error
+	ClaimUserTaskHandler(c echo.Context) error
+	ReleaseUserTaskHandler(c echo.Context) error
 	GetUserTaskDetailsHandler(c echo.Context) error
 }
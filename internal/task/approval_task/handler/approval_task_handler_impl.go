@@ -4,11 +4,13 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sawalreverr/recything/internal/helper"
 	"github.com/sawalreverr/recything/internal/task/approval_task/dto"
 	"github.com/sawalreverr/recything/internal/task/approval_task/usecase"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 	"github.com/sawalreverr/recything/pkg"
 )
 
@@ -38,7 +40,25 @@ func (handler *ApprovalTaskHandlerImpl) GetAllApprovalTaskPaginationHandler(c ec
 		return err
 	}
 
-	userTask, total, err := handler.usecase.GetAllApprovalTaskPaginationUseCase(limitInt, pageInt)
+	filter := dto.ApprovalTaskFilter{
+		StatusAccept:    c.QueryParam("status"),
+		TaskChallengeId: c.QueryParam("task_id"),
+		UserId:          c.QueryParam("user_id"),
+		Search:          c.QueryParam("search"),
+		Claimed:         c.QueryParam("claimed"),
+	}
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if filter.SubmittedFrom, err = time.Parse("2006-01-02", startDate); err != nil {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrDateFormat.Error())
+		}
+	}
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if filter.SubmittedTo, err = time.Parse("2006-01-02", endDate); err != nil {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrDateFormat.Error())
+		}
+	}
+
+	userTask, total, err := handler.usecase.GetAllApprovalTaskPaginationUseCase(filter, limitInt, pageInt)
 	if err != nil {
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
 	}
@@ -49,6 +69,7 @@ func (handler *ApprovalTaskHandlerImpl) GetAllApprovalTaskPaginationHandler(c ec
 			Id:           task.ID,
 			StatusAccept: task.StatusAccept,
 			Point:        task.Point,
+			SubmittedAt:  task.SubmittedAt,
 			TaskChallenge: dto.DataTasks{
 				Id:        task.TaskChallenge.ID,
 				Title:     task.TaskChallenge.Title,
@@ -60,6 +81,7 @@ func (handler *ApprovalTaskHandlerImpl) GetAllApprovalTaskPaginationHandler(c ec
 				Name:    task.User.Name,
 				Profile: task.User.PictureURL,
 			},
+			Claim: claimData(task),
 		})
 	}
 
@@ -84,13 +106,17 @@ func (handler *ApprovalTaskHandlerImpl) GetAllApprovalTaskPaginationHandler(c ec
 
 func (handler *ApprovalTaskHandlerImpl) ApproveUserTaskHandler(c echo.Context) error {
 	userTaskId := c.Param("userTaskId")
-	if err := handler.usecase.ApproveUserTaskUseCase(userTaskId); err != nil {
-		if errors.Is(err, pkg.ErrUserTaskAlreadyApprove) {
-			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrUserTaskAlreadyApprove.Error())
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	if err := handler.usecase.ApproveUserTaskUseCase(userTaskId, adminId); err != nil {
+		if errors.Is(err, pkg.ErrUserTaskClaimed) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskClaimed.Error())
 		}
 		if errors.Is(err, pkg.ErrUserTaskNotFound) {
 			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserTaskNotFound.Error())
 		}
+		if errors.Is(err, pkg.ErrUserTaskNotReviewable) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrUserTaskNotReviewable.Error())
+		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
 	}
 
@@ -108,7 +134,11 @@ func (handler *ApprovalTaskHandlerImpl) RejectUserTaskHandler(c echo.Context) er
 	if err := c.Validate(&request); err != nil {
 		return helper.ErrorHandler(c, http.StatusBadRequest, "invalid request body, detail : "+err.Error())
 	}
-	if err := handler.usecase.RejectUserTaskUseCase(&request, userTaskId); err != nil {
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	if err := handler.usecase.RejectUserTaskUseCase(&request, userTaskId, adminId); err != nil {
+		if errors.Is(err, pkg.ErrUserTaskClaimed) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskClaimed.Error())
+		}
 		if errors.Is(err, pkg.ErrUserTaskAlreadyReject) {
 			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrUserTaskAlreadyReject.Error())
 		}
@@ -118,6 +148,9 @@ func (handler *ApprovalTaskHandlerImpl) RejectUserTaskHandler(c echo.Context) er
 		if errors.Is(err, pkg.ErrUserTaskAlreadyAccepted) {
 			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrUserTaskAlreadyAccepted.Error())
 		}
+		if errors.Is(err, pkg.ErrUserTaskNotReviewable) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrUserTaskNotReviewable.Error())
+		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
 	}
 
@@ -126,6 +159,92 @@ func (handler *ApprovalTaskHandlerImpl) RejectUserTaskHandler(c echo.Context) er
 	return c.JSON(http.StatusOK, responseData)
 }
 
+func (handler *ApprovalTaskHandlerImpl) BulkApproveUserTaskHandler(c echo.Context) error {
+	var request dto.BulkApproveUserTaskRequest
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "invalid request body, detail : "+err.Error())
+	}
+
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	result := handler.usecase.BulkApproveUserTaskUseCase(&request, adminId)
+	responseData := helper.ResponseData(http.StatusOK, "bulk approve processed", result)
+
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *ApprovalTaskHandlerImpl) BulkRejectUserTaskHandler(c echo.Context) error {
+	var request dto.BulkRejectUserTaskRequest
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "invalid request body, detail : "+err.Error())
+	}
+
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	result := handler.usecase.BulkRejectUserTaskUseCase(&request, adminId)
+	responseData := helper.ResponseData(http.StatusOK, "bulk reject processed", result)
+
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *ApprovalTaskHandlerImpl) ClaimUserTaskHandler(c echo.Context) error {
+	userTaskId := c.Param("userTaskId")
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	claimedUntil, err := handler.usecase.ClaimUserTaskUseCase(userTaskId, adminId)
+	if err != nil {
+		return claimErrorHandler(c, err)
+	}
+
+	data := dto.DataClaim{AdminId: adminId, ClaimedUntil: *claimedUntil}
+	responseData := helper.ResponseData(http.StatusOK, "success claim user task", &data)
+
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *ApprovalTaskHandlerImpl) ReleaseUserTaskHandler(c echo.Context) error {
+	userTaskId := c.Param("userTaskId")
+	adminId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	if err := handler.usecase.ReleaseUserTaskUseCase(userTaskId, adminId); err != nil {
+		return claimErrorHandler(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "success release user task", nil)
+
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func claimErrorHandler(c echo.Context, err error) error {
+	if errors.Is(err, pkg.ErrUserTaskNotFound) {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserTaskNotFound.Error())
+	}
+	if errors.Is(err, pkg.ErrUserTaskClaimed) {
+		return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskClaimed.Error())
+	}
+	if errors.Is(err, pkg.ErrUserTaskNotClaimed) {
+		return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskNotClaimed.Error())
+	}
+	if errors.Is(err, pkg.ErrUserTaskNotReviewable) {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrUserTaskNotReviewable.Error())
+	}
+	return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+}
+
+// claimData returns the active claim on a user task, or nil once it expired.
+func claimData(userTask *user_task.UserTaskChallenge) *dto.DataClaim {
+	if userTask.ClaimedBy == nil || userTask.ClaimedUntil == nil || !userTask.ClaimedUntil.After(time.Now()) {
+		return nil
+	}
+	return &dto.DataClaim{
+		AdminId:      *userTask.ClaimedBy,
+		Name:         userTask.Claimer.Name,
+		ClaimedUntil: *userTask.ClaimedUntil,
+	}
+}
+
 func (handler *ApprovalTaskHandlerImpl) GetUserTaskDetailsHandler(c echo.Context) error {
 	userTaskId := c.Param("userTaskId")
 	task, images, err := handler.usecase.GetUserTaskDetailsUseCase(userTaskId)
@@ -143,6 +262,8 @@ func (handler *ApprovalTaskHandlerImpl) GetUserTaskDetailsHandler(c echo.Context
 		StartDate:   task.TaskChallenge.StartDate,
 		EndDate:     task.TaskChallenge.EndDate,
 		UserName:    task.User.Name,
+		SubmittedAt: task.SubmittedAt,
+		Claim:       claimData(task),
 		Images:      []*dto.DataImages{},
 		Description: task.DescriptionImage,
 	}
@@ -157,6 +278,59 @@ func (handler *ApprovalTaskHandlerImpl) GetUserTaskDetailsHandler(c echo.Context
 
 	data.Images = dataImages
 
+	// evidence is grouped under the step it proves, in completion order
+	progress := make(map[int]user_task.UserTaskStep, len(task.UserTaskSteps))
+	for _, step := range task.UserTaskSteps {
+		progress[step.TaskStepID] = step
+	}
+
+	data.Steps = []*dto.DataStepEvidence{}
+	for _, step := range task.TaskChallenge.TaskSteps {
+		userStep := progress[step.ID]
+		stepImages := []*dto.DataImages{}
+		for _, image := range userStep.Images {
+			stepImages = append(stepImages, &dto.DataImages{
+				Id:         image.ID,
+				ImageUrl:   image.ImageUrl,
+				UploadedAt: image.CreatedAt,
+			})
+		}
+
+		data.Steps = append(data.Steps, &dto.DataStepEvidence{
+			Id:            step.ID,
+			Position:      step.Position,
+			Title:         step.Title,
+			RequiresPhoto: step.RequiresPhoto,
+			Completed:     userStep.Completed,
+			Note:          userStep.Note,
+			Images:        stepImages,
+		})
+	}
+
+	data.Submissions = []*dto.DataSubmission{}
+	for _, submission := range task.Submissions {
+		submissionImages := []*dto.DataImages{}
+		for _, image := range submission.Images {
+			submissionImages = append(submissionImages, &dto.DataImages{
+				Id:         image.ID,
+				ImageUrl:   image.ImageUrl,
+				UploadedAt: image.CreatedAt,
+			})
+		}
+
+		data.Submissions = append(data.Submissions, &dto.DataSubmission{
+			Attempt:      submission.Attempt,
+			Description:  submission.DescriptionImage,
+			Images:       submissionImages,
+			SubmittedAt:  submission.SubmittedAt,
+			Decision:     submission.Decision,
+			Reason:       submission.Reason,
+			ReviewerId:   submission.ReviewedBy,
+			ReviewerName: submission.Reviewer.Name,
+			ReviewedAt:   submission.ReviewedAt,
+		})
+	}
+
 	responseData := helper.ResponseData(http.StatusOK, "success get user task details", &data)
 
 	return c.JSON(http.StatusOK, responseData)
@@ -1,15 +1,21 @@
 package repository
 
 import (
+	"time"
+
+	pnt "github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/internal/task/approval_task/dto"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 )
 
 type ApprovalTaskRepository interface {
-	GetAllApprovalTaskPagination(limit int, offset int) ([]*user_task.UserTaskChallenge, int, error)
+	GetAllApprovalTaskPagination(filter dto.ApprovalTaskFilter, limit int, offset int) ([]*user_task.UserTaskChallenge, int, error)
 	FindUserTask(userTaskId string) (*user_task.UserTaskChallenge, error)
-	ApproveUserTask(userTaskId string) error
-	RejectUserTask(data *user_task.UserTaskChallenge, userTaskId string) error
+	ApproveUserTask(userTaskId string, adminId string) (*pnt.Entry, error)
+	RejectUserTask(data *user_task.UserTaskChallenge, userTaskId string, adminId string) error
 	GetUserTaskDetails(userTaskId string) (*user_task.UserTaskChallenge, []*user_task.UserTaskImage, error)
 	FindUserTaskForApprove(userTaskId string) (*user_task.UserTaskChallenge, error)
 	FindUserTaskForReject(userTaskId string) (*user_task.UserTaskChallenge, error)
+	ClaimUserTask(userTaskId string, adminId string, until time.Time) error
+	ReleaseUserTask(userTaskId string, adminId string) error
 }
@@ -1,13 +1,21 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/database"
 	"github.com/sawalreverr/recything/internal/helper"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	pointRepo "github.com/sawalreverr/recything/internal/point/repository"
+	"github.com/sawalreverr/recything/internal/task/approval_task/dto"
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 	user_entity "github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ApprovalTaskRepositoryImpl struct {
@@ -20,32 +28,114 @@ func NewApprovalTaskRepositoryImpl(db database.Database) *ApprovalTaskRepository
 	}
 }
 
-func (repository *ApprovalTaskRepositoryImpl) GetAllApprovalTaskPagination(limit int, offset int) ([]*user_task.UserTaskChallenge, int, error) {
+func (repository *ApprovalTaskRepositoryImpl) GetAllApprovalTaskPagination(filter dto.ApprovalTaskFilter, limit int, offset int) ([]*user_task.UserTaskChallenge, int, error) {
 	var tasks []*user_task.UserTaskChallenge
 	var total int64
 	offset = (offset - 1) * limit
 
-	if err := repository.DB.GetDB().Model(&user_task.UserTaskChallenge{}).
-		Where("status_progress = ?", "done").
-		Count(&total).
-		Error; err != nil {
+	query := repository.DB.GetDB().Model(&user_task.UserTaskChallenge{}).
+		Where("user_task_challenges.status_progress = ?", "done")
+
+	if filter.StatusAccept != "" {
+		query = query.Where("user_task_challenges.status_accept = ?", filter.StatusAccept)
+	}
+	if filter.TaskChallengeId != "" {
+		query = query.Where("user_task_challenges.task_challenge_id = ?", filter.TaskChallengeId)
+	}
+	if filter.UserId != "" {
+		query = query.Where("user_task_challenges.user_id = ?", filter.UserId)
+	}
+	if !filter.SubmittedFrom.IsZero() {
+		query = query.Where("user_task_challenges.submitted_at >= ?", filter.SubmittedFrom)
+	}
+	if !filter.SubmittedTo.IsZero() {
+		query = query.Where("user_task_challenges.submitted_at < ?", filter.SubmittedTo.AddDate(0, 0, 1))
+	}
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		query = query.
+			Joins("LEFT JOIN task_challenges ON task_challenges.id = user_task_challenges.task_challenge_id").
+			Joins("LEFT JOIN users ON users.id = user_task_challenges.user_id").
+			Where("user_task_challenges.id LIKE ? OR task_challenges.title LIKE ? OR users.name LIKE ?", search, search, search)
+	}
+
+	now := time.Now()
+	switch filter.Claimed {
+	case "true":
+		query = query.Where("user_task_challenges.claimed_until > ?", now)
+	case "false":
+		query = query.Where("user_task_challenges.claimed_until IS NULL OR user_task_challenges.claimed_until <= ?", now)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := repository.DB.GetDB().
-		Preload("TaskChallenge.TaskSteps").
+	if err := query.
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
 		Preload("User").
+		Preload("Claimer").
 		Limit(limit).
-		Offset(offset).Order("id desc").
-		Where("status_progress = ?", "done").
+		Offset(offset).Order("user_task_challenges.id desc").
 		Find(&tasks).Error; err != nil {
 		return nil, 0, err
 	}
 	return tasks, int(total), nil
 }
 
+// ClaimUserTask locks a submission waiting for review to one admin until the
+// given time. An admin can renew their own claim or take over an expired one.
+func (repository *ApprovalTaskRepositoryImpl) ClaimUserTask(userTaskId string, adminId string, until time.Time) error {
+	result := repository.DB.GetDB().Model(&user_task.UserTaskChallenge{}).
+		Where("id = ? AND status_progress = ? AND status_accept = ?", userTaskId, "done", "need_rivew").
+		Where("claimed_by IS NULL OR claimed_by = ? OR claimed_until IS NULL OR claimed_until <= ?", adminId, time.Now()).
+		Updates(map[string]interface{}{
+			"claimed_by":    adminId,
+			"claimed_until": until,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.claimConflict(userTaskId)
+	}
+	return nil
+}
+
+func (repository *ApprovalTaskRepositoryImpl) ReleaseUserTask(userTaskId string, adminId string) error {
+	result := repository.DB.GetDB().Model(&user_task.UserTaskChallenge{}).
+		Where("id = ? AND claimed_by = ?", userTaskId, adminId).
+		Updates(map[string]interface{}{
+			"claimed_by":    nil,
+			"claimed_until": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := repository.FindUserTask(userTaskId); err != nil {
+			return pkg.ErrUserTaskNotFound
+		}
+		return pkg.ErrUserTaskNotClaimed
+	}
+	return nil
+}
+
+// claimConflict explains why a claim did not match any submission.
+func (repository *ApprovalTaskRepositoryImpl) claimConflict(userTaskId string) error {
+	userTask, err := repository.FindUserTask(userTaskId)
+	if err != nil {
+		return pkg.ErrUserTaskNotFound
+	}
+	if userTask.StatusProgress != "done" || userTask.StatusAccept != "need_rivew" {
+		return pkg.ErrUserTaskNotReviewable
+	}
+	return pkg.ErrUserTaskClaimed
+}
+
 func (repository *ApprovalTaskRepositoryImpl) FindUserTask(userTaskId string) (*user_task.UserTaskChallenge, error) {
 	var userTask user_task.UserTaskChallenge
 	if err := repository.DB.GetDB().
+		Preload("User").
 		Where("id = ?", userTaskId).
 		First(&userTask).Error; err != nil {
 		return nil, err
@@ -53,84 +143,172 @@ func (repository *ApprovalTaskRepositoryImpl) FindUserTask(userTaskId string) (*
 	return &userTask, nil
 }
 
-func (repository *ApprovalTaskRepositoryImpl) ApproveUserTask(userTaskId string) error {
-	var userTask user_task.UserTaskChallenge
-	tx := repository.DB.GetDB().Begin()
+// ApproveUserTask accepts a user task and credits its points. The user task
+// and user rows are locked until the points are written, so concurrent
+// approvals neither credit a task twice nor lose each other's points. It
+// returns the ledger entry written for the task, or nil without changing
+// anything when the task was already accepted, and rejects tasks that were
+// never submitted or have been abandoned.
+func (repository *ApprovalTaskRepositoryImpl) ApproveUserTask(userTaskId string, adminId string) (*pnt.Entry, error) {
+	var credited *pnt.Entry
+	err := repository.DB.Transaction(func(tx database.Database) error {
+		var userTask user_task.UserTaskChallenge
+		if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("TaskChallenge").
+			Where("id = ?", userTaskId).
+			First(&userTask).Error; err != nil {
+			return err
+		}
 
-	if err := tx.Where("id = ?", userTaskId).First(&userTask).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		if userTask.StatusAccept == "accept" {
+			return nil
+		}
+		if userTask.StatusProgress != "done" || userTask.StatusAccept != "need_rivew" {
+			return pkg.ErrUserTaskNotReviewable
+		}
 
-	acceptedAt := time.Now()
-	if err := tx.Model(&userTask).Where("id = ?", userTaskId).Updates(map[string]interface{}{
-		"status_accept": "accept",
-		"accepted_at":   acceptedAt,
-		"reason":        "",
-	}).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		acceptedAt := time.Now()
+		if userTask.ClaimedByOther(adminId, acceptedAt) {
+			return pkg.ErrUserTaskClaimed
+		}
 
-	point := userTask.Point
+		if err := tx.GetDB().Model(&userTask).Where("id = ?", userTaskId).Updates(map[string]interface{}{
+			"status_accept": "accept",
+			"accepted_at":   acceptedAt,
+			"reason":        "",
+			"claimed_by":    nil,
+			"claimed_until": nil,
+		}).Error; err != nil {
+			return err
+		}
 
-	var user user_entity.User
-	if err := tx.Where("id = ?", userTask.UserId).First(&user).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		if err := reviewSubmission(tx.GetDB(), userTaskId, "accept", "", adminId, acceptedAt); err != nil {
+			return err
+		}
 
-	pointBonus := helper.BonusTask(user.Badge, point)
+		var user user_entity.User
+		if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userTask.UserId).
+			First(&user).Error; err != nil {
+			return err
+		}
 
-	pointUpdate := int(user.Point) + pointBonus
+		// users whose achievement was removed get no bonus, users that were
+		// never given one get the bonus of the level their points reach
+		points := pointRepo.NewPointRepository(tx)
+		lookup := tx.GetDB().Where("id = ?", user.AchievementID)
+		if user.AchievementID == 0 {
+			earned, err := points.Earned(user.ID)
+			if err != nil {
+				return err
+			}
+			lookup = tx.GetDB().Scopes(achievement.ReachedBy(earned)).Order("target_point desc")
+		}
+		var level achievement.Achievement
+		if err := lookup.First(&level).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
 
-	var achievements []achievement.Achievement
+		point := userTask.Point
+		pointBonus := helper.BonusTask(level.BonusPercent, point)
 
-	if err := tx.Model(&achievement.Achievement{}).Order("target_point desc").Find(&achievements).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		entry, err := points.Append(pnt.Entry{
+			UserID:       userTask.UserId,
+			Amount:       pointBonus,
+			BasePoint:    point,
+			BonusPercent: level.BonusPercent,
+			BonusPoint:   pointBonus - point,
+			SourceType:   pnt.SourceTaskApproval,
+			SourceID:     userTask.ID,
+			Description:  userTask.TaskChallenge.Title,
+			CreatedBy:    adminId,
+		})
+		if err != nil {
+			return err
+		}
 
-	if len(achievements) == 0 {
-		tx.Rollback()
+		credited = entry
 		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	var badge string
-	for _, ach := range achievements {
-		if pointUpdate >= ach.TargetPoint {
-			badge = ach.BadgeUrlUser
-			break
-		}
-	}
 
-	if badge != "" {
-		if err := tx.Model(&user_entity.User{}).Where("id = ?", userTask.UserId).
-			Updates(map[string]interface{}{"badge": badge, "point": pointUpdate}).Error; err != nil {
-			tx.Rollback()
+	return credited, nil
+}
+
+// RejectUserTask rejects a submitted user task. The user task row is locked
+// while its status is checked and written, so a reject racing an approval
+// never overturns a task that was already accepted and credited.
+func (repository *ApprovalTaskRepositoryImpl) RejectUserTask(data *user_task.UserTaskChallenge, userTaskId string, adminId string) error {
+	return repository.DB.Transaction(func(tx database.Database) error {
+		var userTask user_task.UserTaskChallenge
+		if err := tx.GetDB().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userTaskId).
+			First(&userTask).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ErrUserTaskNotFound
+			}
 			return err
 		}
-	}
 
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		switch {
+		case userTask.StatusAccept == "accept":
+			return pkg.ErrUserTaskAlreadyAccepted
+		case userTask.StatusAccept == "reject":
+			return pkg.ErrUserTaskAlreadyReject
+		case userTask.StatusProgress != "done" || userTask.StatusAccept != "need_rivew":
+			return pkg.ErrUserTaskNotReviewable
+		}
 
-	return nil
+		rejectedAt := time.Now()
+		if userTask.ClaimedByOther(adminId, rejectedAt) {
+			return pkg.ErrUserTaskClaimed
+		}
+
+		if err := tx.GetDB().Model(&user_task.UserTaskChallenge{}).Where("id = ?", userTaskId).Updates(map[string]interface{}{
+			"status_accept": data.StatusAccept,
+			"reason":        data.Reason,
+			"claimed_by":    nil,
+			"claimed_until": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return reviewSubmission(tx.GetDB(), userTaskId, data.StatusAccept, data.Reason, adminId, rejectedAt)
+	})
 }
 
-func (repository *ApprovalTaskRepositoryImpl) RejectUserTask(data *user_task.UserTaskChallenge, userTaskId string) error {
-	if err := repository.DB.GetDB().Where("id = ?", userTaskId).Updates(&data).Error; err != nil {
+// reviewSubmission records the decision on the latest attempt of a user task.
+func reviewSubmission(tx *gorm.DB, userTaskId string, decision string, reason string, adminId string, reviewedAt time.Time) error {
+	var submission user_task.UserTaskSubmission
+	err := tx.Where("user_task_challenge_id = ?", userTaskId).
+		Order("attempt desc").
+		First(&submission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
-	return nil
+
+	return tx.Model(&submission).Updates(map[string]interface{}{
+		"decision":    decision,
+		"reason":      reason,
+		"reviewed_by": adminId,
+		"reviewed_at": reviewedAt,
+	}).Error
 }
 
 func (repository *ApprovalTaskRepositoryImpl) GetUserTaskDetails(userTaskId string) (*user_task.UserTaskChallenge, []*user_task.UserTaskImage, error) {
 	var userTask user_task.UserTaskChallenge
 	if err := repository.DB.GetDB().
 		Preload("User").
-		Preload("TaskChallenge").
+		Preload("Claimer").
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps.Images").
+		Preload("Submissions", user_task.OrderSubmissions).
+		Preload("Submissions.Images").
+		Preload("Submissions.Reviewer").
 		Where("id = ?", userTaskId).
 		Where("status_progress = ?", "done").
 		First(&userTask).Error; err != nil {
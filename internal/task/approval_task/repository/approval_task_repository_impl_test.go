@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	admin "github.com/sawalreverr/recything/internal/admin/entity"
+	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+	pnt "github.com/sawalreverr/recything/internal/point"
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	user_entity "github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm/clause"
+)
+
+// seedUserTasks creates an admin, a user and a challenge, plus one user task
+// per given progress status, all waiting for review.
+func seedUserTasks(t *testing.T, db database.Database, statuses ...string) (string, string, []user_task.UserTaskChallenge) {
+	t.Helper()
+
+	now := time.Now()
+	// ids are at most 20 characters
+	suffix := strconv.FormatInt(now.UnixNano(), 36)
+	adminId := "TAD" + suffix
+	userId := "TUS" + suffix
+
+	records := []interface{}{
+		&admin.Admin{ID: adminId, Name: "test admin", Role: "admin"},
+		&user_entity.User{ID: userId, Name: "test user", Gender: "-", BirthDate: now},
+		&task.TaskChallenge{
+			ID:        "TTC" + suffix,
+			Title:     "test challenge",
+			StartDate: now,
+			EndDate:   now.AddDate(0, 0, 7),
+			Point:     10,
+			AdminId:   adminId,
+			Status:    true,
+		},
+	}
+	for _, record := range records {
+		if err := db.GetDB().Omit(clause.Associations).Create(record).Error; err != nil {
+			t.Fatalf("seed %T: %v", record, err)
+		}
+	}
+
+	userTasks := make([]user_task.UserTaskChallenge, len(statuses))
+	for i, status := range statuses {
+		userTasks[i] = user_task.UserTaskChallenge{
+			ID:              fmt.Sprintf("TUT%s%02d", suffix, i),
+			UserId:          userId,
+			TaskChallengeId: "TTC" + suffix,
+			StatusProgress:  status,
+			StatusAccept:    "need_rivew",
+			Point:           10 + i,
+			AcceptedAt:      now,
+		}
+		if err := db.GetDB().Omit(clause.Associations).Create(&userTasks[i]).Error; err != nil {
+			t.Fatalf("seed user task: %v", err)
+		}
+	}
+
+	return adminId, userId, userTasks
+}
+
+func TestApproveUserTaskConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewApprovalTaskRepositoryImpl(db)
+
+	const tasks = 8
+	statuses := make([]string, tasks)
+	for i := range statuses {
+		statuses[i] = "done"
+	}
+	adminId, userId, userTasks := seedUserTasks(t, db, statuses...)
+
+	// every task is approved twice at the same time, and all tasks belong to
+	// the same user, so approvals race on both the task and the user row
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*tasks)
+	for _, userTask := range userTasks {
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func(userTaskId string) {
+				defer wg.Done()
+				if _, err := repository.ApproveUserTask(userTaskId, adminId); err != nil {
+					errs <- err
+				}
+			}(userTask.ID)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("approve user task: %v", err)
+	}
+
+	for _, userTask := range userTasks {
+		var entries int64
+		if err := db.GetDB().Model(&pnt.Entry{}).
+			Where("source_type = ? AND source_id = ?", pnt.SourceTaskApproval, userTask.ID).
+			Count(&entries).Error; err != nil {
+			t.Fatal(err)
+		}
+		if entries != 1 {
+			t.Errorf("user task %s has %d ledger entries, want 1", userTask.ID, entries)
+		}
+	}
+
+	var ledger int
+	if err := db.GetDB().Model(&pnt.Entry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userId).
+		Scan(&ledger).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var user user_entity.User
+	if err := db.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if int(user.Point) != ledger {
+		t.Errorf("users.point is %d, ledger sum is %d", user.Point, ledger)
+	}
+}
+
+func TestApproveAndRejectUserTaskConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewApprovalTaskRepositoryImpl(db)
+
+	const tasks = 8
+	statuses := make([]string, tasks)
+	for i := range statuses {
+		statuses[i] = "done"
+	}
+	adminId, _, userTasks := seedUserTasks(t, db, statuses...)
+
+	// every task is approved and rejected at the same time, exactly one of
+	// the two decisions may win
+	approved := make([]bool, tasks)
+	rejected := make([]bool, tasks)
+	var wg sync.WaitGroup
+	for i, userTask := range userTasks {
+		wg.Add(2)
+		go func(i int, userTaskId string) {
+			defer wg.Done()
+			entry, err := repository.ApproveUserTask(userTaskId, adminId)
+			switch {
+			case err == nil:
+				approved[i] = entry != nil
+			case !errors.Is(err, pkg.ErrUserTaskNotReviewable):
+				t.Errorf("approve user task: %v", err)
+			}
+		}(i, userTask.ID)
+		go func(i int, userTaskId string) {
+			defer wg.Done()
+			err := repository.RejectUserTask(&user_task.UserTaskChallenge{StatusAccept: "reject", Reason: "blurry"}, userTaskId, adminId)
+			switch {
+			case err == nil:
+				rejected[i] = true
+			case !errors.Is(err, pkg.ErrUserTaskAlreadyAccepted):
+				t.Errorf("reject user task: %v", err)
+			}
+		}(i, userTask.ID)
+	}
+	wg.Wait()
+
+	for i, userTask := range userTasks {
+		if approved[i] == rejected[i] {
+			t.Errorf("user task %s: approved %v, rejected %v, want exactly one", userTask.ID, approved[i], rejected[i])
+			continue
+		}
+
+		var stored user_task.UserTaskChallenge
+		if err := db.GetDB().Where("id = ?", userTask.ID).First(&stored).Error; err != nil {
+			t.Fatal(err)
+		}
+		var entries int64
+		if err := db.GetDB().Model(&pnt.Entry{}).
+			Where("source_type = ? AND source_id = ?", pnt.SourceTaskApproval, userTask.ID).
+			Count(&entries).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		want, wantEntries := "reject", int64(0)
+		if approved[i] {
+			want, wantEntries = "accept", 1
+		}
+		if stored.StatusAccept != want || entries != wantEntries {
+			t.Errorf("user task %s is %s with %d ledger entries, want %s with %d",
+				userTask.ID, stored.StatusAccept, entries, want, wantEntries)
+		}
+	}
+}
+
+func TestRejectUserTaskNotSubmitted(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewApprovalTaskRepositoryImpl(db)
+
+	adminId, _, userTasks := seedUserTasks(t, db, "in_progress", "abandoned")
+
+	for _, userTask := range userTasks {
+		err := repository.RejectUserTask(&user_task.UserTaskChallenge{StatusAccept: "reject", Reason: "blurry"}, userTask.ID, adminId)
+		if !errors.Is(err, pkg.ErrUserTaskNotReviewable) {
+			t.Errorf("reject %s user task: got %v, want %v", userTask.StatusProgress, err, pkg.ErrUserTaskNotReviewable)
+		}
+
+		var stored user_task.UserTaskChallenge
+		if err := db.GetDB().Where("id = ?", userTask.ID).First(&stored).Error; err != nil {
+			t.Fatal(err)
+		}
+		if stored.StatusAccept != "need_rivew" {
+			t.Errorf("%s user task is %s, want need_rivew", userTask.StatusProgress, stored.StatusAccept)
+		}
+	}
+}
+
+func TestApproveUserTaskNotSubmitted(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewApprovalTaskRepositoryImpl(db)
+
+	adminId, userId, userTasks := seedUserTasks(t, db, "in_progress", "abandoned")
+
+	for _, userTask := range userTasks {
+		entry, err := repository.ApproveUserTask(userTask.ID, adminId)
+		if !errors.Is(err, pkg.ErrUserTaskNotReviewable) {
+			t.Errorf("approve %s user task: got %v, want %v", userTask.StatusProgress, err, pkg.ErrUserTaskNotReviewable)
+		}
+		if entry != nil {
+			t.Errorf("approve %s user task credited %d points", userTask.StatusProgress, entry.Amount)
+		}
+	}
+
+	var entries int64
+	if err := db.GetDB().Model(&pnt.Entry{}).Where("user_id = ?", userId).Count(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entries != 0 {
+		t.Errorf("user has %d ledger entries, want 0", entries)
+	}
+}
+
+func TestApproveUserTaskWithoutAchievement(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewApprovalTaskRepositoryImpl(db)
+
+	adminId, userId, userTasks := seedUserTasks(t, db, "done")
+
+	// the user was never given an achievement, but their points reach the
+	// upper of two levels
+	base := 1000 + int(time.Now().UnixNano()%900000)
+	levels := []achievement.Achievement{
+		{Level: fmt.Sprintf("lower %d", base), Kind: achievement.KindPoint, TargetPoint: base - 500, BonusPercent: 10},
+		{Level: fmt.Sprintf("upper %d", base), Kind: achievement.KindPoint, TargetPoint: base, BonusPercent: 50},
+	}
+	for i := range levels {
+		if err := db.GetDB().Create(&levels[i]).Error; err != nil {
+			t.Fatalf("seed level: %v", err)
+		}
+	}
+	if err := db.GetDB().Create(&pnt.Entry{
+		UserID:     userId,
+		Amount:     base,
+		SourceType: pnt.SourceAdjustment,
+		SourceID:   userId,
+	}).Error; err != nil {
+		t.Fatalf("seed ledger: %v", err)
+	}
+	if err := db.GetDB().Model(&user_entity.User{}).Where("id = ?", userId).Update("point", base).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := repository.ApproveUserTask(userTasks[0].ID, adminId)
+	if err != nil {
+		t.Fatalf("approve user task: %v", err)
+	}
+	if entry.BonusPercent != 50 {
+		t.Errorf("approval got a %d%% bonus, want the 50%% of the level the points reach", entry.BonusPercent)
+	}
+
+	var user user_entity.User
+	if err := db.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.AchievementID != levels[1].ID {
+		t.Errorf("user holds achievement %d, want %d", user.AchievementID, levels[1].ID)
+	}
+}
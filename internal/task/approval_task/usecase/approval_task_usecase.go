@@ -1,13 +1,19 @@
 package usecase
 
 import (
+	"time"
+
 	"github.com/sawalreverr/recything/internal/task/approval_task/dto"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 )
 
 type ApprovalTaskUsecase interface {
-	GetAllApprovalTaskPaginationUseCase(limit int, offset int) ([]*user_task.UserTaskChallenge, int, error)
-	ApproveUserTaskUseCase(userTaskId string) error
-	RejectUserTaskUseCase(request *dto.RejectUserTaskRequest, userTaskId string) error
+	GetAllApprovalTaskPaginationUseCase(filter dto.ApprovalTaskFilter, limit int, offset int) ([]*user_task.UserTaskChallenge, int, error)
+	ApproveUserTaskUseCase(userTaskId string, adminId string) error
+	RejectUserTaskUseCase(request *dto.RejectUserTaskRequest, userTaskId string, adminId string) error
+	BulkApproveUserTaskUseCase(request *dto.BulkApproveUserTaskRequest, adminId string) *dto.BulkResultResponse
+	BulkRejectUserTaskUseCase(request *dto.BulkRejectUserTaskRequest, adminId string) *dto.BulkResultResponse
+	ClaimUserTaskUseCase(userTaskId string, adminId string) (*time.Time, error)
+	ReleaseUserTaskUseCase(userTaskId string, adminId string) error
 	GetUserTaskDetailsUseCase(userTaskId string) (*user_task.UserTaskChallenge, []*user_task.UserTaskImage, error)
 }
@@ -1,23 +1,44 @@
 package usecase
 
 import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/streak"
 	"github.com/sawalreverr/recything/internal/task/approval_task/dto"
 	"github.com/sawalreverr/recything/internal/task/approval_task/repository"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"github.com/sawalreverr/recything/internal/webhook"
 	"github.com/sawalreverr/recything/pkg"
 	"gorm.io/gorm"
 )
 
+// ClaimDuration is how long a reviewer holds a submission before another
+// admin may take it over.
+const ClaimDuration = 15 * time.Minute
+
+// reviewConflicts are the errors of a user task another review got to first.
+var reviewConflicts = []error{
+	pkg.ErrUserTaskAlreadyAccepted,
+	pkg.ErrUserTaskAlreadyReject,
+	pkg.ErrUserTaskNotReviewable,
+	pkg.ErrUserTaskClaimed,
+}
+
 type ApprovalTaskUsecaseImpl struct {
 	ApprovalTaskRepository repository.ApprovalTaskRepository
+	Webhook                webhook.Publisher
+	Streak                 streak.Recorder
 }
 
-func NewApprovalTaskUsecase(approvalTaskRepository repository.ApprovalTaskRepository) *ApprovalTaskUsecaseImpl {
-	return &ApprovalTaskUsecaseImpl{ApprovalTaskRepository: approvalTaskRepository}
+func NewApprovalTaskUsecase(approvalTaskRepository repository.ApprovalTaskRepository, publisher webhook.Publisher, recorder streak.Recorder) *ApprovalTaskUsecaseImpl {
+	return &ApprovalTaskUsecaseImpl{ApprovalTaskRepository: approvalTaskRepository, Webhook: publisher, Streak: recorder}
 }
 
-func (usecase *ApprovalTaskUsecaseImpl) GetAllApprovalTaskPaginationUseCase(limit int, offset int) ([]*user_task.UserTaskChallenge, int, error) {
-	task, total, err := usecase.ApprovalTaskRepository.GetAllApprovalTaskPagination(limit, offset)
+func (usecase *ApprovalTaskUsecaseImpl) GetAllApprovalTaskPaginationUseCase(filter dto.ApprovalTaskFilter, limit int, offset int) ([]*user_task.UserTaskChallenge, int, error) {
+	task, total, err := usecase.ApprovalTaskRepository.GetAllApprovalTaskPagination(filter, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -25,46 +46,133 @@ func (usecase *ApprovalTaskUsecaseImpl) GetAllApprovalTaskPaginationUseCase(limi
 	return task, total, nil
 }
 
-func (usecase *ApprovalTaskUsecaseImpl) ApproveUserTaskUseCase(userTaskId string) error {
+func (usecase *ApprovalTaskUsecaseImpl) ApproveUserTaskUseCase(userTaskId string, adminId string) error {
 	userTask, err := usecase.ApprovalTaskRepository.FindUserTask(userTaskId)
 	if err != nil {
 		return pkg.ErrUserTaskNotFound
 	}
 
-	if userTask.StatusAccept == "accept" {
-		return pkg.ErrUserTaskAlreadyApprove
+	if userTask.ClaimedByOther(adminId, time.Now()) {
+		return pkg.ErrUserTaskClaimed
 	}
 
-	if err := usecase.ApprovalTaskRepository.ApproveUserTask(userTaskId); err != nil {
-		return err
+	// approving twice is harmless, the points are only credited once
+	if userTask.StatusAccept == "accept" {
+		return nil
 	}
-	return nil
 
-}
-
-func (usecase *ApprovalTaskUsecaseImpl) RejectUserTaskUseCase(request *dto.RejectUserTaskRequest, userTaskId string) error {
-	userTask, err := usecase.ApprovalTaskRepository.FindUserTask(userTaskId)
+	entry, err := usecase.ApprovalTaskRepository.ApproveUserTask(userTaskId, adminId)
 	if err != nil {
-		return pkg.ErrUserTaskNotFound
+		return err
 	}
-	if userTask.StatusAccept == "reject" {
-		return pkg.ErrUserTaskAlreadyReject
+	if entry == nil {
+		return nil
 	}
 
-	if userTask.StatusAccept == "accept" {
-		return pkg.ErrUserTaskAlreadyAccepted
+	// the point is what was credited, the task point plus the badge bonus
+	data := dto.TaskApprovedEvent{
+		UserTaskId:      userTask.ID,
+		UserId:          userTask.UserId,
+		TaskChallengeId: userTask.TaskChallengeId,
+		Point:           entry.Amount,
+		ApprovedAt:      entry.CreatedAt,
+	}
+	region := webhook.Region{Province: userTask.User.Province, City: userTask.User.City}
+	if err := usecase.Webhook.Publish(webhook.EventTaskApproved, region, data); err != nil {
+		log.Printf("queue webhook %s for user task %s: %v", webhook.EventTaskApproved, userTask.ID, err)
+	}
+	if err := usecase.Streak.Record(userTask.UserId, streak.ActionTaskApproval, userTask.ID, data.ApprovedAt); err != nil {
+		log.Printf("record streak for user task %s: %v", userTask.ID, err)
 	}
+	return nil
+
+}
 
+// RejectUserTaskUseCase rejects a submitted user task. The status, progress
+// and claim are checked by the repository while the user task is locked.
+func (usecase *ApprovalTaskUsecaseImpl) RejectUserTaskUseCase(request *dto.RejectUserTaskRequest, userTaskId string, adminId string) error {
 	status := "reject"
 	if err := usecase.ApprovalTaskRepository.RejectUserTask(&user_task.UserTaskChallenge{
 		StatusAccept: status,
 		Reason:       request.Reason,
-	}, userTaskId); err != nil {
+	}, userTaskId, adminId); err != nil {
 		return err
 	}
 	return nil
 }
 
+// BulkApproveUserTaskUseCase approves each user task in its own transaction,
+// so one failing item does not undo the others.
+func (usecase *ApprovalTaskUsecaseImpl) BulkApproveUserTaskUseCase(request *dto.BulkApproveUserTaskRequest, adminId string) *dto.BulkResultResponse {
+	return processBulk(request.UserTaskIds, func(userTaskId string) error {
+		return usecase.ApproveUserTaskUseCase(userTaskId, adminId)
+	})
+}
+
+// BulkRejectUserTaskUseCase rejects each user task in its own transaction with
+// its own reason, falling back to the shared one.
+func (usecase *ApprovalTaskUsecaseImpl) BulkRejectUserTaskUseCase(request *dto.BulkRejectUserTaskRequest, adminId string) *dto.BulkResultResponse {
+	return processBulk(request.UserTaskIds, func(userTaskId string) error {
+		reason := strings.TrimSpace(request.Reasons[userTaskId])
+		if reason == "" {
+			reason = strings.TrimSpace(request.Reason)
+		}
+		if reason == "" {
+			return pkg.ErrRejectReasonRequired
+		}
+		return usecase.RejectUserTaskUseCase(&dto.RejectUserTaskRequest{Reason: reason}, userTaskId, adminId)
+	})
+}
+
+// ClaimUserTaskUseCase reserves a submission for the admin for ClaimDuration.
+// Claiming again before it expires extends the claim.
+func (usecase *ApprovalTaskUsecaseImpl) ClaimUserTaskUseCase(userTaskId string, adminId string) (*time.Time, error) {
+	until := time.Now().Add(ClaimDuration)
+	if err := usecase.ApprovalTaskRepository.ClaimUserTask(userTaskId, adminId, until); err != nil {
+		return nil, err
+	}
+	return &until, nil
+}
+
+func (usecase *ApprovalTaskUsecaseImpl) ReleaseUserTaskUseCase(userTaskId string, adminId string) error {
+	return usecase.ApprovalTaskRepository.ReleaseUserTask(userTaskId, adminId)
+}
+
+func processBulk(userTaskIds []string, process func(userTaskId string) error) *dto.BulkResultResponse {
+	response := &dto.BulkResultResponse{Results: []dto.BulkItemResult{}}
+	seen := make(map[string]bool, len(userTaskIds))
+
+	for _, userTaskId := range userTaskIds {
+		if seen[userTaskId] {
+			continue
+		}
+		seen[userTaskId] = true
+
+		result := dto.BulkItemResult{UserTaskId: userTaskId, Success: true}
+		if err := process(userTaskId); err != nil {
+			result.Success = false
+			result.Conflict = isReviewConflict(err)
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	response.Total = len(response.Results)
+	return response
+}
+
+func isReviewConflict(err error) bool {
+	for _, conflict := range reviewConflicts {
+		if errors.Is(err, conflict) {
+			return true
+		}
+	}
+	return false
+}
+
 func (usecase *ApprovalTaskUsecaseImpl) GetUserTaskDetailsUseCase(userTaskId string) (*user_task.UserTaskChallenge, []*user_task.UserTaskImage, error) {
 	task, images, err := usecase.ApprovalTaskRepository.GetUserTaskDetails(userTaskId)
 	if err != nil {
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sawalreverr/recything/internal/task/approval_task/dto"
+	"github.com/sawalreverr/recything/internal/task/approval_task/repository"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+// fakeRepository rejects user tasks with the error set for their id.
+type fakeRepository struct {
+	repository.ApprovalTaskRepository
+	rejectErrs map[string]error
+	rejected   []string
+}
+
+func (f *fakeRepository) RejectUserTask(data *user_task.UserTaskChallenge, userTaskId string, adminId string) error {
+	if err := f.rejectErrs[userTaskId]; err != nil {
+		return err
+	}
+	f.rejected = append(f.rejected, userTaskId)
+	return nil
+}
+
+func TestBulkRejectReportsConflicts(t *testing.T) {
+	repo := &fakeRepository{rejectErrs: map[string]error{
+		"UT0002": pkg.ErrUserTaskAlreadyAccepted,
+		"UT0003": pkg.ErrUserTaskClaimed,
+		"UT0004": pkg.ErrUserTaskNotFound,
+		"UT0005": errors.New("connection reset"),
+	}}
+	usecase := NewApprovalTaskUsecase(repo, nil, nil)
+
+	result := usecase.BulkRejectUserTaskUseCase(&dto.BulkRejectUserTaskRequest{
+		UserTaskIds: []string{"UT0001", "UT0002", "UT0003", "UT0004", "UT0005", "UT0001"},
+		Reason:      "blurry",
+	}, "AD0001")
+
+	if result.Total != 5 || result.Succeeded != 1 || result.Failed != 4 {
+		t.Errorf("got total %d, succeeded %d, failed %d, want 5, 1 and 4", result.Total, result.Succeeded, result.Failed)
+	}
+	if len(repo.rejected) != 1 || repo.rejected[0] != "UT0001" {
+		t.Errorf("rejected %v, want [UT0001]", repo.rejected)
+	}
+
+	tests := []struct {
+		userTaskId string
+		success    bool
+		conflict   bool
+	}{
+		{"UT0001", true, false},
+		{"UT0002", false, true},
+		{"UT0003", false, true},
+		{"UT0004", false, false},
+		{"UT0005", false, false},
+	}
+	for i, tt := range tests {
+		got := result.Results[i]
+		if got.UserTaskId != tt.userTaskId || got.Success != tt.success || got.Conflict != tt.conflict {
+			t.Errorf("result %d is %+v, want %s with success %v and conflict %v", i, got, tt.userTaskId, tt.success, tt.conflict)
+		}
+		if !tt.success && got.Error == "" {
+			t.Errorf("result for %s has no error", tt.userTaskId)
+		}
+	}
+}
@@ -10,12 +10,23 @@ type CreateTaskResquest struct {
 	Point       int                   `json:"point" validate:"required"`
 	Thumbnail   *multipart.FileHeader `json:"-"`
 	TaskSteps   []TaskSteps           `json:"task_steps" validate:"required"`
+
+	MaxParticipants    int      `json:"max_participants" validate:"min=0"`
+	MinAchievementId   *int     `json:"min_achievement_id"`
+	Provinces          []string `json:"provinces"`
+	EnrollmentDeadline string   `json:"enrollment_deadline"`
+	// MaxResubmissions falls back to the default when left out.
+	MaxResubmissions int `json:"max_resubmissions"`
+	// AllowRejoin defaults to true when left out.
+	AllowRejoin *bool `json:"allow_rejoin"`
 }
 
 type TaskSteps struct {
-	Id          int    `json:"id"`
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description" validate:"required"`
+	Id            int    `json:"id"`
+	Position      int    `json:"position"`
+	Title         string `json:"title" validate:"required"`
+	Description   string `json:"description" validate:"required"`
+	RequiresPhoto bool   `json:"requires_photo"`
 }
 
 type UpdateTaskRequest struct {
@@ -26,4 +37,24 @@ type UpdateTaskRequest struct {
 	Point       int                   `json:"point"`
 	Thumbnail   *multipart.FileHeader `json:"-"`
 	TaskSteps   []TaskSteps           `json:"task_steps" validate:"required"`
+
+	// rules are only changed when present, send 0, an empty list or an empty
+	// string to remove a rule
+	MaxParticipants    *int      `json:"max_participants"`
+	MinAchievementId   *int      `json:"min_achievement_id"`
+	Provinces          *[]string `json:"provinces"`
+	EnrollmentDeadline *string   `json:"enrollment_deadline"`
+	MaxResubmissions   *int      `json:"max_resubmissions"`
+	AllowRejoin        *bool     `json:"allow_rejoin"`
+}
+
+type AddTaskStepRequest struct {
+	Title         string `json:"title" validate:"required"`
+	Description   string `json:"description" validate:"required"`
+	Position      int    `json:"position"`
+	RequiresPhoto bool   `json:"requires_photo"`
+}
+
+type ReorderTaskStepsRequest struct {
+	StepIds []int `json:"step_ids" validate:"required"`
 }
@@ -3,28 +3,32 @@ package dto
 import "time"
 
 type CreateTaskResponse struct {
-	Id          string      `json:"id"`
-	Title       string      `json:"title"`
-	Description string      `json:"description"`
-	Thumbnail   string      `json:"thumbnail"`
-	StartDate   time.Time   `json:"start_date"`
-	EndDate     time.Time   `json:"end_date"`
-	Point       int         `json:"point"`
-	Status      bool        `json:"status"`
-	Steps       []TaskSteps `json:"steps"`
+	Id           string      `json:"id"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	Thumbnail    string      `json:"thumbnail"`
+	StartDate    time.Time   `json:"start_date"`
+	EndDate      time.Time   `json:"end_date"`
+	Point        int         `json:"point"`
+	Status       bool        `json:"status"`
+	Availability string      `json:"availability"`
+	Steps        []TaskSteps `json:"steps"`
+	Rules        TaskRules   `json:"rules"`
 }
 
 type DataTasks struct {
-	Id          string           `json:"id"`
-	Title       string           `json:"title"`
-	Description string           `json:"description"`
-	Thumbnail   string           `json:"thumbnail"`
-	StartDate   time.Time        `json:"start_date"`
-	EndDate     time.Time        `json:"end_date"`
-	Point       int              `json:"point"`
-	Status      bool             `json:"status"`
-	Steps       []TaskSteps      `json:"steps"`
-	TaskCreator TaskCreatorAdmin `json:"task_creator"`
+	Id           string           `json:"id"`
+	Title        string           `json:"title"`
+	Description  string           `json:"description"`
+	Thumbnail    string           `json:"thumbnail"`
+	StartDate    time.Time        `json:"start_date"`
+	EndDate      time.Time        `json:"end_date"`
+	Point        int              `json:"point"`
+	Status       bool             `json:"status"`
+	Availability string           `json:"availability"`
+	Steps        []TaskSteps      `json:"steps"`
+	Rules        TaskRules        `json:"rules"`
+	TaskCreator  TaskCreatorAdmin `json:"task_creator"`
 }
 
 type TaskCreatorAdmin struct {
@@ -43,16 +47,18 @@ type GetTaskPagination struct {
 }
 
 type TaskGetByIdResponse struct {
-	Id          string           `json:"id"`
-	Title       string           `json:"title"`
-	Description string           `json:"description"`
-	Thumbnail   string           `json:"thumbnail"`
-	StartDate   time.Time        `json:"start_date"`
-	EndDate     time.Time        `json:"end_date"`
-	Point       int              `json:"point"`
-	Status      bool             `json:"status"`
-	Steps       []TaskSteps      `json:"steps"`
-	TaskCreator TaskCreatorAdmin `json:"task_creator"`
+	Id           string           `json:"id"`
+	Title        string           `json:"title"`
+	Description  string           `json:"description"`
+	Thumbnail    string           `json:"thumbnail"`
+	StartDate    time.Time        `json:"start_date"`
+	EndDate      time.Time        `json:"end_date"`
+	Point        int              `json:"point"`
+	Status       bool             `json:"status"`
+	Availability string           `json:"availability"`
+	Steps        []TaskSteps      `json:"steps"`
+	Rules        TaskRules        `json:"rules"`
+	TaskCreator  TaskCreatorAdmin `json:"task_creator"`
 }
 
 type UpdateTaskResponse struct {
@@ -64,4 +70,20 @@ type UpdateTaskResponse struct {
 	EndDate     time.Time   `json:"end_date"`
 	Point       int         `json:"point"`
 	Steps       []TaskSteps `json:"steps"`
+	Rules       TaskRules   `json:"rules"`
+}
+
+type TaskStepsResponse struct {
+	TaskId string      `json:"task_id"`
+	Steps  []TaskSteps `json:"steps"`
+	Rules  TaskRules   `json:"rules"`
+}
+
+type TaskRules struct {
+	MaxParticipants    int        `json:"max_participants"`
+	MinAchievementId   *int       `json:"min_achievement_id"`
+	Provinces          []string   `json:"provinces"`
+	EnrollmentDeadline *time.Time `json:"enrollment_deadline"`
+	MaxResubmissions   int        `json:"max_resubmissions"`
+	AllowRejoin        bool       `json:"allow_rejoin"`
 }
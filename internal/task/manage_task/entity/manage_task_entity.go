@@ -1,14 +1,16 @@
 package entity
 
 import (
+	"strings"
 	"time"
 
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	admin "github.com/sawalreverr/recything/internal/admin/entity"
 	"gorm.io/gorm"
 )
 
 type TaskChallenge struct {
-	ID          string `gorm:"primaryKey"`
+	ID          string `gorm:"primaryKey;type:varchar(20)"`
 	Title       string
 	Description string
 	Thumbnail   string
@@ -16,20 +18,117 @@ type TaskChallenge struct {
 	EndDate     time.Time
 	Point       int
 	Status      bool
-	TaskSteps   []TaskStep     `gorm:"foreignKey:TaskChallengeId"`
-	AdminId     string         `gorm:"index"`
-	Admin       admin.Admin    `gorm:"foreignKey:AdminId"`
-	CreatedAt   time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	TaskSteps   []TaskStep  `gorm:"foreignKey:TaskChallengeId"`
+	AdminId     string      `gorm:"index;type:varchar(20)"`
+	Admin       admin.Admin `gorm:"foreignKey:AdminId"`
+	// MaxParticipants caps how many users can join, 0 means unlimited.
+	MaxParticipants int `gorm:"default:0"`
+	// MinAchievementId is the lowest achievement a user must have reached to join.
+	MinAchievementId *int
+	MinAchievement   *achievement.Achievement `gorm:"foreignKey:MinAchievementId"`
+	// Provinces limits the challenge to users living in these provinces
+	// ("Jawa Barat,Bali"), empty means open to every province.
+	Provinces string
+	// EnrollmentDeadline is the last day users can join, nil means until the
+	// challenge ends.
+	EnrollmentDeadline *time.Time
+	// MaxResubmissions is how many times a rejected submission can be sent
+	// again for review.
+	MaxResubmissions int `gorm:"default:3"`
+	// AllowRejoin lets users who withdrew join the challenge again, nil means
+	// allowed. Resubmissions are counted over every attempt either way.
+	AllowRejoin *bool `gorm:"default:true"`
+	// TaskTemplateId is set when the challenge was generated from a template.
+	TaskTemplateId *uint          `gorm:"index"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
 }
 
 type TaskStep struct {
 	ID              int    `gorm:"primaryKey"`
-	TaskChallengeId string `gorm:"index"`
-	Title           string
-	Description     string
-	CreatedAt       time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt       time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt       gorm.DeletedAt `gorm:"index"`
+	TaskChallengeId string `gorm:"index;type:varchar(20)"`
+	// Position is the 1-based order in which participants complete the step.
+	Position    int `gorm:"index"`
+	Title       string
+	Description string
+	// RequiresPhoto makes participants attach at least one photo when they
+	// complete the step.
+	RequiresPhoto bool           `gorm:"default:false"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+// OrderSteps is a preload scope that returns task steps in completion order.
+func OrderSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+// DefaultMaxResubmissions applies to challenges created without their own cap.
+const DefaultMaxResubmissions = 3
+
+// RejoinAllowed reports whether users who withdrew may join again.
+func (t *TaskChallenge) RejoinAllowed() bool {
+	return t.AllowRejoin == nil || *t.AllowRejoin
+}
+
+// ValidMaxResubmissions reports whether n is an allowed resubmission cap.
+func ValidMaxResubmissions(n int) bool {
+	return n >= 1 && n <= 10
+}
+
+const (
+	AvailabilityUpcoming = "upcoming"
+	AvailabilityActive   = "active"
+	AvailabilityEnded    = "ended"
+)
+
+// Availability reports whether the challenge is upcoming, active or ended at
+// the given time. The end date is inclusive, so a challenge stays active until
+// the end of its last day.
+func (t *TaskChallenge) Availability(now time.Time) string {
+	if now.Before(t.StartDate) {
+		return AvailabilityUpcoming
+	}
+	if !now.Before(t.EndDate.AddDate(0, 0, 1)) {
+		return AvailabilityEnded
+	}
+	return AvailabilityActive
+}
+
+// IsActive reports whether users can follow the challenge at the given time.
+func (t *TaskChallenge) IsActive(now time.Time) bool {
+	return t.Availability(now) == AvailabilityActive
+}
+
+// ProvinceList returns the provinces the challenge is restricted to.
+func (t *TaskChallenge) ProvinceList() []string {
+	var provinces []string
+	for _, province := range strings.Split(t.Provinces, ",") {
+		if province = strings.TrimSpace(province); province != "" {
+			provinces = append(provinces, province)
+		}
+	}
+	return provinces
+}
+
+// AllowsProvince reports whether users from the province can join.
+func (t *TaskChallenge) AllowsProvince(province string) bool {
+	provinces := t.ProvinceList()
+	if len(provinces) == 0 {
+		return true
+	}
+	for _, allowed := range provinces {
+		if strings.EqualFold(allowed, strings.TrimSpace(province)) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnrollmentOpen reports whether users can still join at the given time. Like
+// the end date, the enrollment deadline includes its whole day.
+func (t *TaskChallenge) EnrollmentOpen(now time.Time) bool {
+	return t.EnrollmentDeadline == nil || now.Before(t.EnrollmentDeadline.AddDate(0, 0, 1))
 }
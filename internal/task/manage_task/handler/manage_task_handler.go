@@ -8,4 +8,7 @@ type ManageTaskHandler interface {
 	GetTaskByIdHandler(c echo.Context) error
 	UpdateTaskHandler(c echo.Context) error
 	DeleteTaskHandler(c echo.Context) error
+	AddTaskStepHandler(c echo.Context) error
+	ReorderTaskStepsHandler(c echo.Context) error
+	DeleteTaskStepHandler(c echo.Context) error
 }
@@ -6,10 +6,12 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sawalreverr/recything/internal/helper"
 	"github.com/sawalreverr/recything/internal/task/manage_task/dto"
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	"github.com/sawalreverr/recything/internal/task/manage_task/usecase"
 	"github.com/sawalreverr/recything/pkg"
 )
@@ -47,6 +49,15 @@ func (handler *ManageTaskHandlerImpl) CreateTaskHandler(c echo.Context) error {
 		if errors.Is(err, pkg.ErrParsedTime) {
 			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrParsedTime.Error())
 		}
+		if errors.Is(err, pkg.ErrAchievementNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrAchievementNotFound.Error())
+		}
+		if errors.Is(err, pkg.ErrMaxParticipants) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrMaxParticipants.Error())
+		}
+		if errors.Is(err, pkg.ErrMaxResubmissions) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrMaxResubmissions.Error())
+		}
 		if errors.Is(err, pkg.ErrThumbnail) {
 			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrThumbnail.Error())
 		}
@@ -68,21 +79,25 @@ func (handler *ManageTaskHandlerImpl) CreateTaskHandler(c echo.Context) error {
 	taskStep := []dto.TaskSteps{}
 
 	data := dto.CreateTaskResponse{
-		Id:          taskChallange.ID,
-		Title:       taskChallange.Title,
-		Description: taskChallange.Description,
-		Thumbnail:   taskChallange.Thumbnail,
-		StartDate:   taskChallange.StartDate,
-		EndDate:     taskChallange.EndDate,
-		Point:       taskChallange.Point,
-		Status:      taskChallange.Status,
-		Steps:       taskStep,
+		Id:           taskChallange.ID,
+		Title:        taskChallange.Title,
+		Description:  taskChallange.Description,
+		Thumbnail:    taskChallange.Thumbnail,
+		StartDate:    taskChallange.StartDate,
+		EndDate:      taskChallange.EndDate,
+		Point:        taskChallange.Point,
+		Status:       taskChallange.Status,
+		Availability: taskChallange.Availability(time.Now()),
+		Steps:        taskStep,
+		Rules:        taskRules(taskChallange),
 	}
 	for _, step := range taskChallange.TaskSteps {
 		taskSteps := dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Position:      step.Position,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		}
 		taskStep = append(taskStep, taskSteps)
 	}
@@ -134,21 +149,25 @@ func (handler *ManageTaskHandlerImpl) GetTaskChallengePaginationHandler(c echo.C
 		var taskSteps []dto.TaskSteps
 		for _, step := range task.TaskSteps {
 			taskSteps = append(taskSteps, dto.TaskSteps{
-				Id:          step.ID,
-				Title:       step.Title,
-				Description: step.Description,
+				Id:            step.ID,
+				Position:      step.Position,
+				Title:         step.Title,
+				Description:   step.Description,
+				RequiresPhoto: step.RequiresPhoto,
 			})
 		}
 		data = append(data, dto.DataTasks{
-			Id:          task.ID,
-			Title:       task.Title,
-			Description: task.Description,
-			Thumbnail:   task.Thumbnail,
-			StartDate:   task.StartDate,
-			EndDate:     task.EndDate,
-			Point:       task.Point,
-			Status:      task.Status,
-			Steps:       taskSteps,
+			Id:           task.ID,
+			Title:        task.Title,
+			Description:  task.Description,
+			Thumbnail:    task.Thumbnail,
+			StartDate:    task.StartDate,
+			EndDate:      task.EndDate,
+			Point:        task.Point,
+			Status:       task.Status,
+			Availability: task.Availability(time.Now()),
+			Steps:        taskSteps,
+			Rules:        taskRules(&task),
 			TaskCreator: dto.TaskCreatorAdmin{
 				Id:   task.AdminId,
 				Name: task.Admin.Name,
@@ -187,21 +206,25 @@ func (handler *ManageTaskHandlerImpl) GetTaskByIdHandler(c echo.Context) error {
 	var taskSteps []dto.TaskSteps
 	for _, step := range task.TaskSteps {
 		taskSteps = append(taskSteps, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Position:      step.Position,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 	data := dto.TaskGetByIdResponse{
-		Id:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		Thumbnail:   task.Thumbnail,
-		StartDate:   task.StartDate,
-		EndDate:     task.EndDate,
-		Point:       task.Point,
-		Status:      task.Status,
-		Steps:       taskSteps,
+		Id:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Thumbnail:    task.Thumbnail,
+		StartDate:    task.StartDate,
+		EndDate:      task.EndDate,
+		Point:        task.Point,
+		Status:       task.Status,
+		Availability: task.Availability(time.Now()),
+		Steps:        taskSteps,
+		Rules:        taskRules(task),
 		TaskCreator: dto.TaskCreatorAdmin{
 			Id:   task.AdminId,
 			Name: task.Admin.Name,
@@ -240,6 +263,15 @@ func (handler *ManageTaskHandlerImpl) UpdateTaskHandler(c echo.Context) error {
 		if errors.Is(err, pkg.ErrParsedTime) {
 			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrParsedTime.Error())
 		}
+		if errors.Is(err, pkg.ErrAchievementNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrAchievementNotFound.Error())
+		}
+		if errors.Is(err, pkg.ErrMaxParticipants) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrMaxParticipants.Error())
+		}
+		if errors.Is(err, pkg.ErrMaxResubmissions) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrMaxResubmissions.Error())
+		}
 		if errors.Is(err, pkg.ErrThumbnail) {
 			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrThumbnail.Error())
 		}
@@ -260,9 +292,11 @@ func (handler *ManageTaskHandlerImpl) UpdateTaskHandler(c echo.Context) error {
 	var taskSteps []dto.TaskSteps
 	for _, step := range task.TaskSteps {
 		taskSteps = append(taskSteps, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Position:      step.Position,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 	data := dto.UpdateTaskResponse{
@@ -274,6 +308,7 @@ func (handler *ManageTaskHandlerImpl) UpdateTaskHandler(c echo.Context) error {
 		EndDate:     task.EndDate,
 		Point:       task.Point,
 		Steps:       taskSteps,
+		Rules:       taskRules(task),
 	}
 	responseData := helper.ResponseData(http.StatusOK, "data updated successfully", data)
 	return c.JSON(http.StatusOK, responseData)
@@ -291,3 +326,98 @@ func (handler *ManageTaskHandlerImpl) DeleteTaskHandler(c echo.Context) error {
 	responseData := helper.ResponseData(http.StatusOK, "data deleted successfully", nil)
 	return c.JSON(http.StatusOK, responseData)
 }
+
+func (handler *ManageTaskHandlerImpl) AddTaskStepHandler(c echo.Context) error {
+	var request dto.AddTaskStepRequest
+	taskId := c.Param("taskId")
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	task, err := handler.Usecase.AddTaskStepUsecase(&request, taskId)
+	if err != nil {
+		return taskStepErrorHandler(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusCreated, "step added successfully", taskStepsResponse(task))
+	return c.JSON(http.StatusCreated, responseData)
+}
+
+func (handler *ManageTaskHandlerImpl) ReorderTaskStepsHandler(c echo.Context) error {
+	var request dto.ReorderTaskStepsRequest
+	taskId := c.Param("taskId")
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	task, err := handler.Usecase.ReorderTaskStepsUsecase(&request, taskId)
+	if err != nil {
+		return taskStepErrorHandler(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "steps reordered successfully", taskStepsResponse(task))
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *ManageTaskHandlerImpl) DeleteTaskStepHandler(c echo.Context) error {
+	taskId := c.Param("taskId")
+	stepId, err := strconv.Atoi(c.Param("stepId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, "invalid step id parameter")
+	}
+
+	task, err := handler.Usecase.DeleteTaskStepUsecase(taskId, stepId)
+	if err != nil {
+		return taskStepErrorHandler(c, err)
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "step deleted successfully", taskStepsResponse(task))
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func taskStepErrorHandler(c echo.Context, err error) error {
+	if errors.Is(err, pkg.ErrTaskNotFound) {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskNotFound.Error())
+	}
+	if errors.Is(err, pkg.ErrTaskStepNotFound) {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskStepNotFound.Error())
+	}
+	if errors.Is(err, pkg.ErrTaskStepsNull) {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrTaskStepsNull.Error())
+	}
+	if errors.Is(err, pkg.ErrTaskStepOrder) {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrTaskStepOrder.Error())
+	}
+	return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail: "+err.Error())
+}
+
+func taskStepsResponse(challenge *task.TaskChallenge) dto.TaskStepsResponse {
+	steps := make([]dto.TaskSteps, 0, len(challenge.TaskSteps))
+	for _, step := range challenge.TaskSteps {
+		steps = append(steps, dto.TaskSteps{
+			Id:            step.ID,
+			Position:      step.Position,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
+		})
+	}
+	return dto.TaskStepsResponse{TaskId: challenge.ID, Steps: steps}
+}
+
+func taskRules(challenge *task.TaskChallenge) dto.TaskRules {
+	return dto.TaskRules{
+		MaxParticipants:    challenge.MaxParticipants,
+		MinAchievementId:   challenge.MinAchievementId,
+		Provinces:          challenge.ProvinceList(),
+		EnrollmentDeadline: challenge.EnrollmentDeadline,
+		MaxResubmissions:   challenge.MaxResubmissions,
+		AllowRejoin:        challenge.RejoinAllowed(),
+	}
+}
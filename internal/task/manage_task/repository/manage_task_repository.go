@@ -1,16 +1,21 @@
 package repository
 
 import (
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 )
 
 type ManageTaskRepository interface {
 	CreateTask(task *task.TaskChallenge) (*task.TaskChallenge, error)
-	FindLastIdTaskChallenge() (string, error)
+	NextIdTaskChallenge() (string, error)
 	GetTaskChallengePagination(page int, limit int, status string, endDate string) ([]task.TaskChallenge, int, error)
 	GetTaskById(id string) (*task.TaskChallenge, error)
 	FindTask(id string) (*task.TaskChallenge, error)
 	UpdateTaskChallenge(taskChallenge *task.TaskChallenge, taskId string) (*task.TaskChallenge, error)
 	DeleteTaskChallenge(taskId string) error
 	UpdateTaskChallengeStatus() error
+	FindAchievement(id int) (*achievement.Achievement, error)
+	InsertTaskStep(taskId string, step *task.TaskStep) (*task.TaskChallenge, error)
+	ReorderTaskSteps(taskId string, stepIds []int) (*task.TaskChallenge, error)
+	DeleteTaskStep(taskId string, stepId int) (*task.TaskChallenge, error)
 }
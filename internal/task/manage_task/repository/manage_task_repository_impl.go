@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"errors"
 	"log"
 	"time"
 
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/database"
 	"github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"github.com/sawalreverr/recything/pkg"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ManageTaskRepositoryImpl struct {
@@ -25,12 +30,8 @@ func (r *ManageTaskRepositoryImpl) CreateTask(task *task.TaskChallenge) (*task.T
 	return task, nil
 }
 
-func (repository *ManageTaskRepositoryImpl) FindLastIdTaskChallenge() (string, error) {
-	var task *task.TaskChallenge
-	if err := repository.DB.GetDB().Unscoped().Order("id desc").First(&task).Error; err != nil {
-		return "TM0000", err
-	}
-	return task.ID, nil
+func (repository *ManageTaskRepositoryImpl) NextIdTaskChallenge() (string, error) {
+	return database.NextID(repository.DB, "TM")
 }
 
 func (repository *ManageTaskRepositoryImpl) GetTaskChallengePagination(page int, limit int, status string, endDate string) ([]task.TaskChallenge, int, error) {
@@ -53,7 +54,7 @@ func (repository *ManageTaskRepositoryImpl) GetTaskChallengePagination(page int,
 	}
 
 	dataQuery := baseQuery.
-		Preload("TaskSteps").
+		Preload("TaskSteps", entity.OrderSteps).
 		Preload("Admin").
 		Limit(limit).
 		Offset(offset)
@@ -78,7 +79,7 @@ func (repository *ManageTaskRepositoryImpl) GetTaskChallengePagination(page int,
 func (repository *ManageTaskRepositoryImpl) GetTaskById(id string) (*task.TaskChallenge, error) {
 	var task *task.TaskChallenge
 	if err := repository.DB.GetDB().
-		Preload("TaskSteps").
+		Preload("TaskSteps", entity.OrderSteps).
 		Preload("Admin").
 		First(&task, "id = ?", id).
 		Error; err != nil {
@@ -89,7 +90,7 @@ func (repository *ManageTaskRepositoryImpl) GetTaskById(id string) (*task.TaskCh
 
 func (repository *ManageTaskRepositoryImpl) FindTask(id string) (*task.TaskChallenge, error) {
 	var task task.TaskChallenge
-	if err := repository.DB.GetDB().Preload("TaskSteps").Where("id = ?", id).First(&task).Error; err != nil {
+	if err := repository.DB.GetDB().Preload("TaskSteps", entity.OrderSteps).Where("id = ?", id).First(&task).Error; err != nil {
 		log.Println("Error finding task:", err)
 		return nil, err
 	}
@@ -107,19 +108,29 @@ func (repository *ManageTaskRepositoryImpl) UpdateTaskChallenge(taskChallenge *t
 	}()
 
 	if len(taskChallenge.TaskSteps) != 0 {
-		if err := tx.Where("task_challenge_id = ?", taskId).Delete(&task.TaskStep{}).Error; err != nil {
-			log.Println("Error deleting task steps:", err)
+		if err := syncTaskSteps(tx, taskId, taskChallenge.TaskSteps); err != nil {
+			log.Println("Error updating task steps:", err)
 			tx.Rollback()
 			return nil, err
 		}
 	}
 
-	if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Updates(taskChallenge).Error; err != nil {
+	if err := tx.Omit("TaskSteps").Updates(taskChallenge).Error; err != nil {
 		log.Println("Error updating task challenge:", err)
 		tx.Rollback()
 		return nil, err
 	}
 
+	// Updates skips zero values, so fields that can be switched off or cleared
+	// are saved explicitly
+	if err := tx.Model(&task.TaskChallenge{}).Where("id = ?", taskId).
+		Select("status", "max_participants", "min_achievement_id", "provinces", "enrollment_deadline", "max_resubmissions", "allow_rejoin").
+		Updates(taskChallenge).Error; err != nil {
+		log.Println("Error updating task challenge status:", err)
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		log.Println("Error committing transaction:", err)
 		return nil, err
@@ -135,16 +146,241 @@ func (repository *ManageTaskRepositoryImpl) DeleteTaskChallenge(taskId string) e
 	return nil
 }
 
+// UpdateTaskChallengeStatus switches on challenges whose start date has been
+// reached and switches off challenges that have ended or not started yet.
 func (repository *ManageTaskRepositoryImpl) UpdateTaskChallengeStatus() error {
 	now := time.Now()
-	result := repository.DB.GetDB().Model(&entity.TaskChallenge{}).
-		Where("end_date < ? AND status = ?", now, true).Update("status", false)
+	// end dates are inclusive, so a challenge is over once its last day has passed
+	endedBefore := now.AddDate(0, 0, -1)
 
-	if result.Error != nil {
-		log.Printf("Error updating task challenge status: %v", result.Error)
-		return result.Error
-	} else {
-		log.Printf("Updated %d task challenge(s) status to false", result.RowsAffected)
+	activated := repository.DB.GetDB().Model(&entity.TaskChallenge{}).
+		Where("status = ? AND start_date <= ? AND end_date > ?", false, now, endedBefore).
+		Update("status", true)
+	if activated.Error != nil {
+		log.Printf("Error updating task challenge status: %v", activated.Error)
+		return activated.Error
+	}
+
+	deactivated := repository.DB.GetDB().Model(&entity.TaskChallenge{}).
+		Where("status = ? AND (start_date > ? OR end_date <= ?)", true, now, endedBefore).
+		Update("status", false)
+	if deactivated.Error != nil {
+		log.Printf("Error updating task challenge status: %v", deactivated.Error)
+		return deactivated.Error
+	}
+
+	if activated.RowsAffected > 0 || deactivated.RowsAffected > 0 {
+		log.Printf("Activated %d and deactivated %d task challenge(s)", activated.RowsAffected, deactivated.RowsAffected)
+	}
+	return nil
+}
+
+func (repository *ManageTaskRepositoryImpl) FindAchievement(id int) (*achievement.Achievement, error) {
+	var found achievement.Achievement
+	if err := repository.DB.GetDB().Scopes(achievement.PointLevels).Where("id = ?", id).First(&found).Error; err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+func (repository *ManageTaskRepositoryImpl) InsertTaskStep(taskId string, step *task.TaskStep) (*task.TaskChallenge, error) {
+	err := repository.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		steps, err := lockTaskSteps(tx, taskId)
+		if err != nil {
+			return err
+		}
+
+		if step.Position < 1 || step.Position > len(steps) {
+			step.Position = len(steps) + 1
+		}
+		if err := tx.Model(&task.TaskStep{}).
+			Where("task_challenge_id = ? AND position >= ?", taskId, step.Position).
+			Update("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+
+		step.ID = 0
+		step.TaskChallengeId = taskId
+		if err := tx.Create(step).Error; err != nil {
+			return err
+		}
+		return addStepProgress(tx, taskId, step.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetTaskById(taskId)
+}
+
+func (repository *ManageTaskRepositoryImpl) ReorderTaskSteps(taskId string, stepIds []int) (*task.TaskChallenge, error) {
+	err := repository.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		steps, err := lockTaskSteps(tx, taskId)
+		if err != nil {
+			return err
+		}
+
+		if len(stepIds) != len(steps) {
+			return pkg.ErrTaskStepOrder
+		}
+		known := make(map[int]bool, len(steps))
+		for _, step := range steps {
+			known[step.ID] = true
+		}
+		for i, id := range stepIds {
+			if !known[id] {
+				return pkg.ErrTaskStepOrder
+			}
+			delete(known, id)
+
+			if err := tx.Model(&task.TaskStep{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
 		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetTaskById(taskId)
+}
+
+func (repository *ManageTaskRepositoryImpl) DeleteTaskStep(taskId string, stepId int) (*task.TaskChallenge, error) {
+	err := repository.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		steps, err := lockTaskSteps(tx, taskId)
+		if err != nil {
+			return err
+		}
+
+		remaining := make([]task.TaskStep, 0, len(steps))
+		for _, step := range steps {
+			if step.ID != stepId {
+				remaining = append(remaining, step)
+			}
+		}
+		if len(remaining) == len(steps) {
+			return pkg.ErrTaskStepNotFound
+		}
+		if len(remaining) == 0 {
+			return pkg.ErrTaskStepsNull
+		}
+
+		if err := removeTaskStep(tx, stepId); err != nil {
+			return err
+		}
+		for i, step := range remaining {
+			if err := tx.Model(&task.TaskStep{}).Where("id = ?", step.ID).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetTaskById(taskId)
+}
+
+// lockTaskSteps locks the challenge row so concurrent step edits are applied
+// one after another, and returns its steps in position order.
+func lockTaskSteps(tx *gorm.DB, taskId string) ([]task.TaskStep, error) {
+	var challenge task.TaskChallenge
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", taskId).First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	var steps []task.TaskStep
+	if err := task.OrderSteps(tx.Where("task_challenge_id = ?", taskId)).Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// syncTaskSteps stores steps in the given order. Steps that carry the id of an
+// existing step are updated in place, steps without one are created, and
+// existing steps that are no longer listed are removed.
+func syncTaskSteps(tx *gorm.DB, taskId string, steps []task.TaskStep) error {
+	var existing []task.TaskStep
+	if err := tx.Where("task_challenge_id = ?", taskId).Find(&existing).Error; err != nil {
+		return err
+	}
+
+	remaining := make(map[int]bool, len(existing))
+	for _, step := range existing {
+		remaining[step.ID] = true
+	}
+
+	for i := range steps {
+		step := &steps[i]
+		step.TaskChallengeId = taskId
+		step.Position = i + 1
+
+		if remaining[step.ID] {
+			delete(remaining, step.ID)
+			if err := tx.Model(&task.TaskStep{}).Where("id = ?", step.ID).Updates(map[string]interface{}{
+				"title":          step.Title,
+				"description":    step.Description,
+				"position":       step.Position,
+				"requires_photo": step.RequiresPhoto,
+			}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		step.ID = 0
+		if err := tx.Create(step).Error; err != nil {
+			return err
+		}
+		if err := addStepProgress(tx, taskId, step.ID); err != nil {
+			return err
+		}
+	}
+
+	for stepId := range remaining {
+		if err := removeTaskStep(tx, stepId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addStepProgress gives every participant still working on the challenge an
+// open entry for a newly added step.
+func addStepProgress(tx *gorm.DB, taskId string, stepId int) error {
+	var userTaskIds []string
+	if err := tx.Model(&user_task.UserTaskChallenge{}).
+		Where("task_challenge_id = ? AND status_progress = ?", taskId, "in_progress").
+		Pluck("id", &userTaskIds).Error; err != nil {
+		return err
+	}
+	if len(userTaskIds) == 0 {
+		return nil
+	}
+
+	progress := make([]user_task.UserTaskStep, 0, len(userTaskIds))
+	for _, userTaskId := range userTaskIds {
+		progress = append(progress, user_task.UserTaskStep{
+			UserTaskChallengeID: userTaskId,
+			TaskStepID:          stepId,
+		})
+	}
+	return tx.Create(&progress).Error
+}
+
+// removeTaskStep deletes a step and the open entries of participants still
+// working on the challenge. Progress of finished submissions stays as it was
+// reviewed.
+func removeTaskStep(tx *gorm.DB, stepId int) error {
+	inProgress := tx.Model(&user_task.UserTaskChallenge{}).Select("id").Where("status_progress = ?", "in_progress")
+	if err := tx.Where("task_step_id = ? AND user_task_challenge_id IN (?)", stepId, inProgress).
+		Delete(&user_task.UserTaskStep{}).Error; err != nil {
+		return err
 	}
+	return tx.Where("id = ?", stepId).Delete(&task.TaskStep{}).Error
 }
@@ -13,4 +13,7 @@ type ManageTaskUsecase interface {
 	GetTaskByIdUsecase(id string) (*task.TaskChallenge, error)
 	UpdateTaskChallengeUsecase(request *dto.UpdateTaskRequest, thumbnail []*multipart.FileHeader, id string) (*task.TaskChallenge, error)
 	DeleteTaskChallengeUsecase(id string) error
+	AddTaskStepUsecase(request *dto.AddTaskStepRequest, taskId string) (*task.TaskChallenge, error)
+	ReorderTaskStepsUsecase(request *dto.ReorderTaskStepsRequest, taskId string) (*task.TaskChallenge, error)
+	DeleteTaskStepUsecase(taskId string, stepId int) (*task.TaskChallenge, error)
 }
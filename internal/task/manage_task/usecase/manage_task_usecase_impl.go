@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"errors"
 	"mime/multipart"
+	"strings"
 	"time"
 
 	"github.com/sawalreverr/recything/internal/helper"
@@ -39,8 +41,10 @@ func (usecase *ManageTaskUsecaseImpl) CreateTaskUsecase(request *dto.CreateTaskR
 		return nil, pkg.ErrUploadCloudinary
 	}
 
-	findLastId, _ := usecase.ManageTaskRepository.FindLastIdTaskChallenge()
-	id := helper.GenerateCustomID(findLastId, "TM")
+	id, err := usecase.ManageTaskRepository.NextIdTaskChallenge()
+	if err != nil {
+		return nil, err
+	}
 	startDateString := request.StartDate
 	endDateString := request.EndDate
 	parsedStartDate, errParsedStartDate := time.Parse("2006-01-02", startDateString)
@@ -51,13 +55,6 @@ func (usecase *ManageTaskUsecaseImpl) CreateTaskUsecase(request *dto.CreateTaskR
 	if errParsedEndDate != nil {
 		return nil, pkg.ErrParsedTime
 	}
-	var statusTask bool
-	if parsedEndDate.Before(time.Now()) {
-		statusTask = false
-	} else {
-		statusTask = true
-	}
-
 	taskChallange := &task.TaskChallenge{
 		ID:          id,
 		AdminId:     adminId,
@@ -67,16 +64,35 @@ func (usecase *ManageTaskUsecaseImpl) CreateTaskUsecase(request *dto.CreateTaskR
 		StartDate:   parsedStartDate,
 		EndDate:     parsedEndDate,
 		Point:       request.Point,
-		Status:      statusTask,
 		TaskSteps:   []task.TaskStep{},
 		DeletedAt:   gorm.DeletedAt{},
 	}
+	taskChallange.Status = taskChallange.IsActive(time.Now())
 
-	for _, step := range request.TaskSteps {
+	taskChallange.MaxParticipants = request.MaxParticipants
+	taskChallange.MaxResubmissions = task.DefaultMaxResubmissions
+	if request.MaxResubmissions != 0 {
+		if !task.ValidMaxResubmissions(request.MaxResubmissions) {
+			return nil, pkg.ErrMaxResubmissions
+		}
+		taskChallange.MaxResubmissions = request.MaxResubmissions
+	}
+	taskChallange.AllowRejoin = request.AllowRejoin
+	taskChallange.Provinces = joinProvinces(request.Provinces)
+	if err := usecase.setMinAchievement(taskChallange, request.MinAchievementId); err != nil {
+		return nil, err
+	}
+	if err := setEnrollmentDeadline(taskChallange, request.EnrollmentDeadline); err != nil {
+		return nil, err
+	}
+
+	for i, step := range request.TaskSteps {
 		taskStep := task.TaskStep{
 			TaskChallengeId: id,
+			Position:        i + 1,
 			Title:           step.Title,
 			Description:     step.Description,
+			RequiresPhoto:   step.RequiresPhoto,
 		}
 		taskChallange.TaskSteps = append(taskChallange.TaskSteps, taskStep)
 	}
@@ -157,20 +173,50 @@ func (usecase *ManageTaskUsecaseImpl) UpdateTaskChallengeUsecase(request *dto.Up
 			return nil, pkg.ErrParsedTime
 		}
 		tasks.EndDate = parsedEndDate
-		if parsedEndDate.Before(time.Now()) {
-			tasks.Status = false
-		} else {
-			tasks.Status = true
+	}
+	tasks.Status = tasks.IsActive(time.Now())
+
+	if request.MaxParticipants != nil {
+		if *request.MaxParticipants < 0 {
+			return nil, pkg.ErrMaxParticipants
+		}
+		tasks.MaxParticipants = *request.MaxParticipants
+	}
+	if request.MaxResubmissions != nil {
+		if !task.ValidMaxResubmissions(*request.MaxResubmissions) {
+			return nil, pkg.ErrMaxResubmissions
+		}
+		tasks.MaxResubmissions = *request.MaxResubmissions
+	}
+	if request.AllowRejoin != nil {
+		tasks.AllowRejoin = request.AllowRejoin
+	}
+	if request.Provinces != nil {
+		tasks.Provinces = joinProvinces(*request.Provinces)
+	}
+	if request.MinAchievementId != nil {
+		if err := usecase.setMinAchievement(tasks, request.MinAchievementId); err != nil {
+			return nil, err
+		}
+	}
+	if request.EnrollmentDeadline != nil {
+		if err := setEnrollmentDeadline(tasks, *request.EnrollmentDeadline); err != nil {
+			return nil, err
 		}
 	}
 
 	if len(request.TaskSteps) != 0 {
 		tasks.TaskSteps = []task.TaskStep{}
-		for _, step := range request.TaskSteps {
+		for i, step := range request.TaskSteps {
+			// steps that keep their id are updated in place so participants
+			// keep their progress on them
 			taskStep := task.TaskStep{
+				ID:              step.Id,
 				TaskChallengeId: id,
+				Position:        i + 1,
 				Title:           step.Title,
 				Description:     step.Description,
+				RequiresPhoto:   step.RequiresPhoto,
 			}
 			tasks.TaskSteps = append(tasks.TaskSteps, taskStep)
 		}
@@ -193,3 +239,69 @@ func (usecase *ManageTaskUsecaseImpl) DeleteTaskChallengeUsecase(id string) erro
 	}
 	return nil
 }
+
+func (usecase *ManageTaskUsecaseImpl) AddTaskStepUsecase(request *dto.AddTaskStepRequest, taskId string) (*task.TaskChallenge, error) {
+	step := &task.TaskStep{
+		Position:      request.Position,
+		Title:         request.Title,
+		Description:   request.Description,
+		RequiresPhoto: request.RequiresPhoto,
+	}
+	return usecase.ManageTaskRepository.InsertTaskStep(taskId, step)
+}
+
+func (usecase *ManageTaskUsecaseImpl) ReorderTaskStepsUsecase(request *dto.ReorderTaskStepsRequest, taskId string) (*task.TaskChallenge, error) {
+	if len(request.StepIds) == 0 {
+		return nil, pkg.ErrTaskStepsNull
+	}
+	return usecase.ManageTaskRepository.ReorderTaskSteps(taskId, request.StepIds)
+}
+
+func (usecase *ManageTaskUsecaseImpl) DeleteTaskStepUsecase(taskId string, stepId int) (*task.TaskChallenge, error) {
+	return usecase.ManageTaskRepository.DeleteTaskStep(taskId, stepId)
+}
+
+// setMinAchievement sets the achievement users need to join, an id of 0 or
+// nil removes the requirement.
+func (usecase *ManageTaskUsecaseImpl) setMinAchievement(challenge *task.TaskChallenge, achievementId *int) error {
+	challenge.MinAchievementId = nil
+	challenge.MinAchievement = nil
+	if achievementId == nil || *achievementId == 0 {
+		return nil
+	}
+
+	if _, err := usecase.ManageTaskRepository.FindAchievement(*achievementId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return pkg.ErrAchievementNotFound
+		}
+		return err
+	}
+	challenge.MinAchievementId = achievementId
+	return nil
+}
+
+// setEnrollmentDeadline parses the last day users can join, an empty string
+// removes the deadline.
+func setEnrollmentDeadline(challenge *task.TaskChallenge, deadline string) error {
+	if deadline == "" {
+		challenge.EnrollmentDeadline = nil
+		return nil
+	}
+
+	parsedDeadline, err := time.Parse("2006-01-02", deadline)
+	if err != nil {
+		return pkg.ErrParsedTime
+	}
+	challenge.EnrollmentDeadline = &parsedDeadline
+	return nil
+}
+
+func joinProvinces(provinces []string) string {
+	var cleaned []string
+	for _, province := range provinces {
+		if province = strings.TrimSpace(province); province != "" {
+			cleaned = append(cleaned, province)
+		}
+	}
+	return strings.Join(cleaned, ",")
+}
@@ -0,0 +1,21 @@
+package dto
+
+type CreateTaskTemplateRequest struct {
+	Title        string              `json:"title" validate:"required"`
+	Description  string              `json:"description" validate:"required"`
+	Point        int                 `json:"point" validate:"required"`
+	DurationDays int                 `json:"duration_days" validate:"required,min=1"`
+	Frequency    string              `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+	Weekdays     []int               `json:"weekdays" validate:"dive,min=0,max=6"`
+	DayOfMonth   int                 `json:"day_of_month" validate:"omitempty,min=1,max=28"`
+	StartsOn     string              `json:"starts_on" validate:"required"`
+	EndsOn       string              `json:"ends_on"`
+	LeadDays     *int                `json:"lead_days" validate:"omitempty,min=0,max=60"`
+	TaskSteps    []TaskTemplateSteps `json:"task_steps" validate:"required"`
+}
+
+type TaskTemplateSteps struct {
+	Title         string `json:"title" validate:"required"`
+	Description   string `json:"description" validate:"required"`
+	RequiresPhoto bool   `json:"requires_photo"`
+}
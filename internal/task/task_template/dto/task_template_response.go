@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type TaskTemplateStepResponse struct {
+	Id            uint   `json:"id"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	RequiresPhoto bool   `json:"requires_photo"`
+}
+
+type TaskTemplateResponse struct {
+	Id               uint                       `json:"id"`
+	Title            string                     `json:"title"`
+	Description      string                     `json:"description"`
+	Thumbnail        string                     `json:"thumbnail"`
+	Point            int                        `json:"point"`
+	DurationDays     int                        `json:"duration_days"`
+	Frequency        string                     `json:"frequency"`
+	Weekdays         []int                      `json:"weekdays"`
+	DayOfMonth       int                        `json:"day_of_month"`
+	StartsOn         time.Time                  `json:"starts_on"`
+	EndsOn           *time.Time                 `json:"ends_on"`
+	LeadDays         int                        `json:"lead_days"`
+	IsPaused         bool                       `json:"is_paused"`
+	LastGeneratedFor *time.Time                 `json:"last_generated_for"`
+	Steps            []TaskTemplateStepResponse `json:"steps"`
+	CreatedBy        string                     `json:"created_by"`
+}
+
+type TaskTemplatePreview struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Generated bool      `json:"generated"`
+}
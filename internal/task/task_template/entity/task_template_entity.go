@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	admin "github.com/sawalreverr/recything/internal/admin/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// TaskTemplate describes a challenge that repeats. The scheduler turns every
+// occurrence of the recurrence rule into a regular TaskChallenge ahead of time.
+type TaskTemplate struct {
+	ID               uint `gorm:"primaryKey"`
+	Title            string
+	Description      string
+	Thumbnail        string
+	Point            int
+	DurationDays     int    `gorm:"default:1"`
+	Frequency        string `gorm:"type:enum('daily', 'weekly', 'monthly')"`
+	Weekdays         string
+	DayOfMonth       int
+	StartsOn         time.Time
+	EndsOn           *time.Time
+	LeadDays         int `gorm:"default:7"`
+	IsPaused         bool
+	LastGeneratedFor *time.Time
+	TemplateSteps    []TaskTemplateStep `gorm:"foreignKey:TaskTemplateId"`
+	AdminId          string             `gorm:"index;type:varchar(20)"`
+	Admin            admin.Admin        `gorm:"foreignKey:AdminId"`
+	CreatedAt        time.Time          `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time          `gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt     `gorm:"index"`
+}
+
+type TaskTemplateStep struct {
+	ID             uint `gorm:"primaryKey"`
+	TaskTemplateId uint `gorm:"index"`
+	Title          string
+	Description    string
+	RequiresPhoto  bool           `gorm:"default:false"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// WeekdayList parses Weekdays ("1,3,5", Sunday is 0) into time.Weekday values.
+func (t *TaskTemplate) WeekdayList() []time.Weekday {
+	var weekdays []time.Weekday
+	for _, value := range strings.Split(t.Weekdays, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || day < 0 || day > 6 {
+			continue
+		}
+		weekdays = append(weekdays, time.Weekday(day))
+	}
+	return weekdays
+}
+
+// Matches reports whether the recurrence rule has an occurrence starting on day.
+func (t *TaskTemplate) Matches(day time.Time) bool {
+	switch t.Frequency {
+	case FrequencyDaily:
+		return true
+	case FrequencyWeekly:
+		for _, weekday := range t.WeekdayList() {
+			if day.Weekday() == weekday {
+				return true
+			}
+		}
+		return false
+	case FrequencyMonthly:
+		return day.Day() == t.DayOfMonth
+	}
+	return false
+}
+
+// Occurrences returns the start dates of the occurrences after the day of
+// after up to and including the day of until, limited to max results when
+// max is positive.
+func (t *TaskTemplate) Occurrences(after time.Time, until time.Time, max int) []time.Time {
+	var dates []time.Time
+
+	day := TruncateDay(after).AddDate(0, 0, 1)
+	if startsOn := TruncateDay(t.StartsOn); day.Before(startsOn) {
+		day = startsOn
+	}
+
+	last := TruncateDay(until)
+	if t.EndsOn != nil && TruncateDay(*t.EndsOn).Before(last) {
+		last = TruncateDay(*t.EndsOn)
+	}
+
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if !t.Matches(day) {
+			continue
+		}
+		dates = append(dates, day)
+		if max > 0 && len(dates) >= max {
+			break
+		}
+	}
+
+	return dates
+}
+
+// EndDate is the last day of the occurrence starting on startDate.
+func (t *TaskTemplate) EndDate(startDate time.Time) time.Time {
+	duration := t.DurationDays
+	if duration < 1 {
+		duration = 1
+	}
+	return startDate.AddDate(0, 0, duration-1)
+}
+
+// TruncateDay drops the time of day, matching how challenge dates are stored.
+func TruncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,13 @@
+package handler
+
+import "github.com/labstack/echo/v4"
+
+type TaskTemplateHandler interface {
+	CreateTemplateHandler(c echo.Context) error
+	GetAllTemplatesHandler(c echo.Context) error
+	GetTemplateByIdHandler(c echo.Context) error
+	PauseTemplateHandler(c echo.Context) error
+	ResumeTemplateHandler(c echo.Context) error
+	DeleteTemplateHandler(c echo.Context) error
+	PreviewTemplateHandler(c echo.Context) error
+}
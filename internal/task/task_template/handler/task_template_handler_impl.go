@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sawalreverr/recything/internal/helper"
+	"github.com/sawalreverr/recything/internal/task/task_template/dto"
+	"github.com/sawalreverr/recything/internal/task/task_template/entity"
+	"github.com/sawalreverr/recything/internal/task/task_template/usecase"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+type TaskTemplateHandlerImpl struct {
+	Usecase usecase.TaskTemplateUsecase
+}
+
+func NewTaskTemplateHandler(usecase usecase.TaskTemplateUsecase) TaskTemplateHandler {
+	return &TaskTemplateHandlerImpl{Usecase: usecase}
+}
+
+func (handler *TaskTemplateHandlerImpl) CreateTemplateHandler(c echo.Context) error {
+	claims := c.Get("user").(*helper.JwtCustomClaims)
+	var request dto.CreateTaskTemplateRequest
+	json_data := c.FormValue("json_data")
+	if err := json.Unmarshal([]byte(json_data), &request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+	form, errForm := c.MultipartForm()
+	if errForm != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, errForm.Error())
+	}
+	thumbnail := form.File["thumbnail"]
+
+	template, err := handler.Usecase.CreateTemplateUsecase(&request, thumbnail, claims.UserID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrTaskStepsNull) ||
+			errors.Is(err, pkg.ErrParsedTime) ||
+			errors.Is(err, pkg.ErrTaskTemplateRecurrence) ||
+			errors.Is(err, pkg.ErrThumbnail) ||
+			errors.Is(err, pkg.ErrThumbnailMaximum) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, pkg.ErrUploadCloudinary) {
+			return helper.ErrorHandler(c, http.StatusInternalServerError, pkg.ErrUploadCloudinary.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+	}
+
+	responseData := helper.ResponseData(http.StatusCreated, "success", templateResponse(template))
+	return c.JSON(http.StatusCreated, responseData)
+}
+
+func (handler *TaskTemplateHandlerImpl) GetAllTemplatesHandler(c echo.Context) error {
+	templates, err := handler.Usecase.GetAllTemplatesUsecase()
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+	}
+
+	data := []dto.TaskTemplateResponse{}
+	for i := range templates {
+		data = append(data, templateResponse(&templates[i]))
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "success", data)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *TaskTemplateHandlerImpl) GetTemplateByIdHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("templateId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+	}
+
+	template, err := handler.Usecase.GetTemplateByIdUsecase(uint(id))
+	if err != nil {
+		if errors.Is(err, pkg.ErrTaskTemplateNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "success", templateResponse(template))
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *TaskTemplateHandlerImpl) PauseTemplateHandler(c echo.Context) error {
+	return handler.setPaused(c, true)
+}
+
+func (handler *TaskTemplateHandlerImpl) ResumeTemplateHandler(c echo.Context) error {
+	return handler.setPaused(c, false)
+}
+
+func (handler *TaskTemplateHandlerImpl) setPaused(c echo.Context, paused bool) error {
+	id, err := strconv.Atoi(c.Param("templateId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+	}
+
+	message := "template resumed"
+	if paused {
+		message = "template paused"
+		err = handler.Usecase.PauseTemplateUsecase(uint(id))
+	} else {
+		err = handler.Usecase.ResumeTemplateUsecase(uint(id))
+	}
+	if err != nil {
+		if errors.Is(err, pkg.ErrTaskTemplateNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, message, nil)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *TaskTemplateHandlerImpl) DeleteTemplateHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("templateId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+	}
+
+	if err := handler.Usecase.DeleteTemplateUsecase(uint(id)); err != nil {
+		if errors.Is(err, pkg.ErrTaskTemplateNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "data deleted successfully", nil)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *TaskTemplateHandlerImpl) PreviewTemplateHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("templateId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+	}
+
+	count := 5
+	if countParam := c.QueryParam("count"); countParam != "" {
+		count, err = strconv.Atoi(countParam)
+		if err != nil || count <= 0 {
+			return helper.ErrorHandler(c, http.StatusBadRequest, "invalid count parameter")
+		}
+	}
+
+	previews, err := handler.Usecase.PreviewTemplateUsecase(uint(id), count)
+	if err != nil {
+		if errors.Is(err, pkg.ErrTaskTemplateNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrTaskTemplateNotFound.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail : "+err.Error())
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "success", previews)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func templateResponse(template *entity.TaskTemplate) dto.TaskTemplateResponse {
+	weekdays := []int{}
+	for _, day := range strings.Split(template.Weekdays, ",") {
+		if value, err := strconv.Atoi(day); err == nil {
+			weekdays = append(weekdays, value)
+		}
+	}
+
+	steps := []dto.TaskTemplateStepResponse{}
+	for _, step := range template.TemplateSteps {
+		steps = append(steps, dto.TaskTemplateStepResponse{
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
+		})
+	}
+
+	return dto.TaskTemplateResponse{
+		Id:               template.ID,
+		Title:            template.Title,
+		Description:      template.Description,
+		Thumbnail:        template.Thumbnail,
+		Point:            template.Point,
+		DurationDays:     template.DurationDays,
+		Frequency:        template.Frequency,
+		Weekdays:         weekdays,
+		DayOfMonth:       template.DayOfMonth,
+		StartsOn:         template.StartsOn,
+		EndsOn:           template.EndsOn,
+		LeadDays:         template.LeadDays,
+		IsPaused:         template.IsPaused,
+		LastGeneratedFor: template.LastGeneratedFor,
+		Steps:            steps,
+		CreatedBy:        template.AdminId,
+	}
+}
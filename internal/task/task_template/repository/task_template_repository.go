@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"time"
+
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	"github.com/sawalreverr/recything/internal/task/task_template/entity"
+)
+
+type TaskTemplateRepository interface {
+	CreateTemplate(template *entity.TaskTemplate) (*entity.TaskTemplate, error)
+	FindTemplate(id uint) (*entity.TaskTemplate, error)
+	GetAllTemplates() ([]entity.TaskTemplate, error)
+	FindActiveTemplates() ([]entity.TaskTemplate, error)
+	UpdatePaused(id uint, paused bool) error
+	DeleteTemplate(id uint) error
+	CreateInstance(templateId uint, startDate time.Time) (*task.TaskChallenge, error)
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/sawalreverr/recything/internal/database"
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	"github.com/sawalreverr/recything/internal/task/task_template/entity"
+	"gorm.io/gorm/clause"
+)
+
+type TaskTemplateRepositoryImpl struct {
+	DB database.Database
+}
+
+func NewTaskTemplateRepository(db database.Database) TaskTemplateRepository {
+	return &TaskTemplateRepositoryImpl{DB: db}
+}
+
+func (repository *TaskTemplateRepositoryImpl) CreateTemplate(template *entity.TaskTemplate) (*entity.TaskTemplate, error) {
+	if err := repository.DB.GetDB().Create(template).Error; err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (repository *TaskTemplateRepositoryImpl) FindTemplate(id uint) (*entity.TaskTemplate, error) {
+	var template entity.TaskTemplate
+	if err := repository.DB.GetDB().
+		Preload("TemplateSteps").
+		Preload("Admin").
+		Where("id = ?", id).
+		First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (repository *TaskTemplateRepositoryImpl) GetAllTemplates() ([]entity.TaskTemplate, error) {
+	var templates []entity.TaskTemplate
+	if err := repository.DB.GetDB().
+		Preload("TemplateSteps").
+		Preload("Admin").
+		Order("created_at DESC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (repository *TaskTemplateRepositoryImpl) FindActiveTemplates() ([]entity.TaskTemplate, error) {
+	var templates []entity.TaskTemplate
+	if err := repository.DB.GetDB().
+		Where("is_paused = ?", false).
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (repository *TaskTemplateRepositoryImpl) UpdatePaused(id uint, paused bool) error {
+	if err := repository.DB.GetDB().Model(&entity.TaskTemplate{}).Where("id = ?", id).Update("is_paused", paused).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *TaskTemplateRepositoryImpl) DeleteTemplate(id uint) error {
+	if err := repository.DB.GetDB().Where("id = ?", id).Delete(&entity.TaskTemplate{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateInstance turns the occurrence starting on startDate into a task
+// challenge with copied steps. The template row is locked so an occurrence is
+// never instantiated twice; nil is returned when it already was.
+func (repository *TaskTemplateRepositoryImpl) CreateInstance(templateId uint, startDate time.Time) (*task.TaskChallenge, error) {
+	var created *task.TaskChallenge
+
+	err := repository.DB.Transaction(func(tx database.Database) error {
+		var template entity.TaskTemplate
+		if err := tx.GetDB().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("TemplateSteps").
+			Where("id = ?", templateId).
+			First(&template).Error; err != nil {
+			return err
+		}
+
+		if template.LastGeneratedFor != nil && !startDate.After(*template.LastGeneratedFor) {
+			return nil
+		}
+
+		id, err := database.NextID(tx, "TM")
+		if err != nil {
+			return err
+		}
+
+		endDate := template.EndDate(startDate)
+		challenge := &task.TaskChallenge{
+			ID:             id,
+			AdminId:        template.AdminId,
+			Title:          template.Title,
+			Description:    template.Description,
+			Thumbnail:      template.Thumbnail,
+			StartDate:      startDate,
+			EndDate:        endDate,
+			Point:          template.Point,
+			TaskTemplateId: &template.ID,
+		}
+		challenge.Status = challenge.IsActive(time.Now())
+
+		for i, step := range template.TemplateSteps {
+			challenge.TaskSteps = append(challenge.TaskSteps, task.TaskStep{
+				TaskChallengeId: id,
+				Position:        i + 1,
+				Title:           step.Title,
+				Description:     step.Description,
+				RequiresPhoto:   step.RequiresPhoto,
+			})
+		}
+
+		if err := tx.GetDB().Omit("Admin").Create(challenge).Error; err != nil {
+			return err
+		}
+
+		if err := tx.GetDB().Model(&entity.TaskTemplate{}).
+			Where("id = ?", template.ID).
+			Update("last_generated_for", startDate).Error; err != nil {
+			return err
+		}
+
+		created = challenge
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
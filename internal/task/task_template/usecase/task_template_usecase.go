@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/task/task_template/dto"
+	"github.com/sawalreverr/recything/internal/task/task_template/entity"
+)
+
+type TaskTemplateUsecase interface {
+	CreateTemplateUsecase(request *dto.CreateTaskTemplateRequest, thumbnail []*multipart.FileHeader, adminId string) (*entity.TaskTemplate, error)
+	GetAllTemplatesUsecase() ([]entity.TaskTemplate, error)
+	GetTemplateByIdUsecase(id uint) (*entity.TaskTemplate, error)
+	PauseTemplateUsecase(id uint) error
+	ResumeTemplateUsecase(id uint) error
+	DeleteTemplateUsecase(id uint) error
+	PreviewTemplateUsecase(id uint, count int) ([]dto.TaskTemplatePreview, error)
+	GenerateInstancesUsecase(now time.Time) (int, error)
+}
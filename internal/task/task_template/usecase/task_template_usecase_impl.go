@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sawalreverr/recything/internal/helper"
+	"github.com/sawalreverr/recything/internal/task/task_template/dto"
+	"github.com/sawalreverr/recything/internal/task/task_template/entity"
+	"github.com/sawalreverr/recything/internal/task/task_template/repository"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+const (
+	defaultLeadDays = 7
+	maxPreviewCount = 30
+
+	// previewHorizonYears bounds how far ahead a preview searches, so a rule
+	// whose end date cuts it short does not scan forever.
+	previewHorizonYears = 2
+)
+
+type TaskTemplateUsecaseImpl struct {
+	TaskTemplateRepository repository.TaskTemplateRepository
+}
+
+func NewTaskTemplateUsecase(repository repository.TaskTemplateRepository) TaskTemplateUsecase {
+	return &TaskTemplateUsecaseImpl{TaskTemplateRepository: repository}
+}
+
+func (usecase *TaskTemplateUsecaseImpl) CreateTemplateUsecase(request *dto.CreateTaskTemplateRequest, thumbnail []*multipart.FileHeader, adminId string) (*entity.TaskTemplate, error) {
+	if len(thumbnail) == 0 {
+		return nil, pkg.ErrThumbnail
+	}
+	if len(thumbnail) > 1 {
+		return nil, pkg.ErrThumbnailMaximum
+	}
+	if len(request.TaskSteps) == 0 {
+		return nil, pkg.ErrTaskStepsNull
+	}
+	if request.Frequency == entity.FrequencyWeekly && len(request.Weekdays) == 0 {
+		return nil, pkg.ErrTaskTemplateRecurrence
+	}
+	if request.Frequency == entity.FrequencyMonthly && request.DayOfMonth == 0 {
+		return nil, pkg.ErrTaskTemplateRecurrence
+	}
+
+	startsOn, err := time.Parse("2006-01-02", request.StartsOn)
+	if err != nil {
+		return nil, pkg.ErrParsedTime
+	}
+	var endsOn *time.Time
+	if request.EndsOn != "" {
+		parsedEndsOn, err := time.Parse("2006-01-02", request.EndsOn)
+		if err != nil {
+			return nil, pkg.ErrParsedTime
+		}
+		if parsedEndsOn.Before(startsOn) {
+			return nil, pkg.ErrTaskTemplateRecurrence
+		}
+		endsOn = &parsedEndsOn
+	}
+
+	validImages, errImages := helper.ImagesValidation(thumbnail)
+	if errImages != nil {
+		return nil, errImages
+	}
+	urlThumbnail, errUpload := helper.UploadToCloudinary(validImages[0], "task_thumbnail")
+	if errUpload != nil {
+		return nil, pkg.ErrUploadCloudinary
+	}
+
+	leadDays := defaultLeadDays
+	if request.LeadDays != nil {
+		leadDays = *request.LeadDays
+	}
+
+	weekdays := make([]string, len(request.Weekdays))
+	for i, day := range request.Weekdays {
+		weekdays[i] = strconv.Itoa(day)
+	}
+
+	template := &entity.TaskTemplate{
+		Title:        request.Title,
+		Description:  request.Description,
+		Thumbnail:    urlThumbnail,
+		Point:        request.Point,
+		DurationDays: request.DurationDays,
+		Frequency:    request.Frequency,
+		Weekdays:     strings.Join(weekdays, ","),
+		DayOfMonth:   request.DayOfMonth,
+		StartsOn:     startsOn,
+		EndsOn:       endsOn,
+		LeadDays:     leadDays,
+		AdminId:      adminId,
+	}
+	for _, step := range request.TaskSteps {
+		template.TemplateSteps = append(template.TemplateSteps, entity.TaskTemplateStep{
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
+		})
+	}
+
+	if _, err := usecase.TaskTemplateRepository.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (usecase *TaskTemplateUsecaseImpl) GetAllTemplatesUsecase() ([]entity.TaskTemplate, error) {
+	return usecase.TaskTemplateRepository.GetAllTemplates()
+}
+
+func (usecase *TaskTemplateUsecaseImpl) GetTemplateByIdUsecase(id uint) (*entity.TaskTemplate, error) {
+	template, err := usecase.TaskTemplateRepository.FindTemplate(id)
+	if err != nil {
+		return nil, pkg.ErrTaskTemplateNotFound
+	}
+	return template, nil
+}
+
+func (usecase *TaskTemplateUsecaseImpl) PauseTemplateUsecase(id uint) error {
+	if _, err := usecase.TaskTemplateRepository.FindTemplate(id); err != nil {
+		return pkg.ErrTaskTemplateNotFound
+	}
+	return usecase.TaskTemplateRepository.UpdatePaused(id, true)
+}
+
+func (usecase *TaskTemplateUsecaseImpl) ResumeTemplateUsecase(id uint) error {
+	if _, err := usecase.TaskTemplateRepository.FindTemplate(id); err != nil {
+		return pkg.ErrTaskTemplateNotFound
+	}
+	return usecase.TaskTemplateRepository.UpdatePaused(id, false)
+}
+
+func (usecase *TaskTemplateUsecaseImpl) DeleteTemplateUsecase(id uint) error {
+	if _, err := usecase.TaskTemplateRepository.FindTemplate(id); err != nil {
+		return pkg.ErrTaskTemplateNotFound
+	}
+	return usecase.TaskTemplateRepository.DeleteTemplate(id)
+}
+
+// PreviewTemplateUsecase lists the next count occurrences from today,
+// flagging the ones the scheduler has already turned into challenges.
+func (usecase *TaskTemplateUsecaseImpl) PreviewTemplateUsecase(id uint, count int) ([]dto.TaskTemplatePreview, error) {
+	template, err := usecase.TaskTemplateRepository.FindTemplate(id)
+	if err != nil {
+		return nil, pkg.ErrTaskTemplateNotFound
+	}
+
+	if count <= 0 || count > maxPreviewCount {
+		count = maxPreviewCount
+	}
+
+	today := entity.TruncateDay(time.Now())
+	dates := template.Occurrences(today.AddDate(0, 0, -1), today.AddDate(previewHorizonYears, 0, 0), count)
+
+	previews := []dto.TaskTemplatePreview{}
+	for _, date := range dates {
+		previews = append(previews, dto.TaskTemplatePreview{
+			StartDate: date,
+			EndDate:   template.EndDate(date),
+			Generated: template.LastGeneratedFor != nil && !date.After(*template.LastGeneratedFor),
+		})
+	}
+	return previews, nil
+}
+
+// GenerateInstancesUsecase creates challenges for every occurrence of an
+// active template that starts within its lead time. Occurrences in the past
+// are skipped rather than created late. It returns how many were created.
+func (usecase *TaskTemplateUsecaseImpl) GenerateInstancesUsecase(now time.Time) (int, error) {
+	templates, err := usecase.TaskTemplateRepository.FindActiveTemplates()
+	if err != nil {
+		return 0, err
+	}
+
+	today := entity.TruncateDay(now)
+	created := 0
+	for _, template := range templates {
+		after := today.AddDate(0, 0, -1)
+		if template.LastGeneratedFor != nil && template.LastGeneratedFor.After(after) {
+			after = *template.LastGeneratedFor
+		}
+
+		for _, date := range template.Occurrences(after, today.AddDate(0, 0, template.LeadDays), 0) {
+			challenge, err := usecase.TaskTemplateRepository.CreateInstance(template.ID, date)
+			if err != nil {
+				return created, err
+			}
+			if challenge != nil {
+				created++
+			}
+		}
+	}
+
+	return created, nil
+}
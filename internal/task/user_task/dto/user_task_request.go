@@ -13,6 +13,8 @@ type UpdateUserTaskRequest struct {
 }
 
 type UpdateTaskStepRequest struct {
-	UserTaskId string `json:"user_task_id"`
-	TaskStepId int    `json:"task_step_id"`
+	UserTaskId string                  `json:"user_task_id"`
+	TaskStepId int                     `json:"task_step_id"`
+	Note       string                  `json:"note"`
+	Images     []*multipart.FileHeader `json:"-"`
 }
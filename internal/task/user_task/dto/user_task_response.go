@@ -9,21 +9,35 @@ type UserTaskGetAllResponse struct {
 }
 
 type DataUserTask struct {
-	Id          string      `json:"id"`
-	Title       string      `json:"title"`
-	Description string      `json:"description"`
-	Thumbnail   string      `json:"thumbnail"`
-	StartDate   time.Time   `json:"start_date"`
-	EndDate     time.Time   `json:"end_date"`
-	Point       int         `json:"point"`
-	Status      bool        `json:"status"`
-	TaskSteps   []TaskSteps `json:"task_steps"`
+	Id           string      `json:"id"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	Thumbnail    string      `json:"thumbnail"`
+	StartDate    time.Time   `json:"start_date"`
+	EndDate      time.Time   `json:"end_date"`
+	Point        int         `json:"point"`
+	Status       bool        `json:"status"`
+	Availability string      `json:"availability"`
+	TaskSteps    []TaskSteps `json:"task_steps"`
+
+	// RemainingSlots is null when the challenge has no participant limit.
+	RemainingSlots   *int   `json:"remaining_slots"`
+	Eligible         bool   `json:"eligible"`
+	IneligibleReason string `json:"ineligible_reason,omitempty"`
+}
+
+// TaskEnrollment tells the current user whether they can still join a task.
+type TaskEnrollment struct {
+	RemainingSlots *int
+	Eligible       bool
+	Reason         string
 }
 
 type TaskSteps struct {
-	Id          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Id            int    `json:"id"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	RequiresPhoto bool   `json:"requires_photo"`
 }
 
 type UserTaskResponseCreate struct {
@@ -56,10 +70,12 @@ type TaskChallengeResponseCreate struct {
 }
 
 type DataUserSteps struct {
-	Id                  int    `json:"id"`
-	UserTaskChallengeID string `json:"user_task_challenge_id"`
-	TaskStepID          int    `json:"task_step_id"`
-	Completed           bool   `json:"completed"`
+	Id                  int      `json:"id"`
+	UserTaskChallengeID string   `json:"user_task_challenge_id"`
+	TaskStepID          int      `json:"task_step_id"`
+	Completed           bool     `json:"completed"`
+	Note                string   `json:"note"`
+	Images              []string `json:"images"`
 }
 
 type UserTaskUploadImageResponse struct {
@@ -128,11 +144,19 @@ type DataImages struct {
 	ImageUrl string `json:"image_url"`
 }
 
+// DataHistoryPoint is one point ledger entry. Id is the source of the entry,
+// the user task for task approvals.
 type DataHistoryPoint struct {
-	Id         string    `json:"id"`
-	TitleTask  string    `json:"title_task"`
-	Point      int       `json:"point"`
-	AcceptedAt time.Time `json:"accepted_at"`
+	Id           string    `json:"id"`
+	TitleTask    string    `json:"title_task"`
+	Point        int       `json:"point"`
+	AcceptedAt   time.Time `json:"accepted_at"`
+	EntryId      uint      `json:"entry_id"`
+	SourceType   string    `json:"source_type"`
+	BasePoint    int       `json:"base_point"`
+	BonusPercent int       `json:"bonus_percent"`
+	BonusPoint   int       `json:"bonus_point"`
+	BalanceAfter int       `json:"balance_after"`
 }
 
 type HistoryPointResponse struct {
@@ -166,3 +190,30 @@ type GetUserTaskByUserTaskIdResponse struct {
 	Reason         string                      `json:"reason"`
 	TaskChalenge   DataGetUserTaskByUserTaskId `json:"task_challenge"`
 }
+
+type SubmissionHistoryResponse struct {
+	UserTaskId        string           `json:"user_task_id"`
+	TaskTitle         string           `json:"task_title"`
+	StatusAccept      string           `json:"status_accepted"`
+	MaxResubmissions  int              `json:"max_resubmissions"`
+	ResubmissionsLeft int              `json:"resubmissions_left"`
+	Submissions       []DataSubmission `json:"submissions"`
+}
+
+type DataSubmission struct {
+	Attempt      int        `json:"attempt"`
+	Description  string     `json:"description"`
+	Images       []string   `json:"images"`
+	SubmittedAt  time.Time  `json:"submitted_at"`
+	Decision     string     `json:"decision"`
+	Reason       string     `json:"reason"`
+	ReviewerName string     `json:"reviewer_name"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+}
+
+type UserTaskWithdrawResponse struct {
+	Id              string     `json:"id"`
+	TaskChallengeId string     `json:"task_challenge_id"`
+	StatusProgress  string     `json:"status_progress"`
+	AbandonedAt     *time.Time `json:"abandoned_at"`
+}
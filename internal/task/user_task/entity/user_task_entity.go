@@ -3,18 +3,19 @@ package entity
 import (
 	"time"
 
+	admin "github.com/sawalreverr/recything/internal/admin/entity"
 	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	"github.com/sawalreverr/recything/internal/user"
 	"gorm.io/gorm"
 )
 
 type UserTaskChallenge struct {
-	ID               string             `gorm:"primaryKey"`
-	UserId           string             `gorm:"index"`
+	ID               string             `gorm:"primaryKey;type:varchar(20)"`
+	UserId           string             `gorm:"index;type:varchar(20)"`
 	User             user.User          `gorm:"foreignKey:UserId"`
-	TaskChallengeId  string             `gorm:"index"`
+	TaskChallengeId  string             `gorm:"index;type:varchar(20)"`
 	TaskChallenge    task.TaskChallenge `gorm:"foreignKey:TaskChallengeId"`
-	StatusProgress   string             `gorm:"type:enum('in_progress', 'done');default:'in_progress'"`
+	StatusProgress   string             `gorm:"type:enum('in_progress', 'done', 'abandoned');default:'in_progress'"`
 	StatusAccept     string             `gorm:"type:enum('accept','need_rivew', 'reject');default:'need_rivew'"`
 	ImageTask        []UserTaskImage    `gorm:"foreignKey:UserTaskChallengeID"`
 	DescriptionImage string
@@ -22,14 +23,23 @@ type UserTaskChallenge struct {
 	Reason           string
 	UserTaskSteps    []UserTaskStep `gorm:"foreignKey:UserTaskChallengeID"`
 	AcceptedAt       time.Time      `gorm:"column:accepted_at;type:datetime"`
-	CreatedAt        time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt        time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	// SubmittedAt is the time the latest submission was sent for review.
+	SubmittedAt *time.Time `gorm:"index"`
+	// ClaimedBy is the admin reviewing the submission until ClaimedUntil.
+	ClaimedBy    *string     `gorm:"index;type:varchar(20)"`
+	Claimer      admin.Admin `gorm:"foreignKey:ClaimedBy"`
+	ClaimedUntil *time.Time
+	// AbandonedAt is set when the user withdraws from the challenge.
+	AbandonedAt *time.Time
+	Submissions []UserTaskSubmission `gorm:"foreignKey:UserTaskChallengeID"`
+	CreatedAt   time.Time            `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time            `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt       `gorm:"index"`
 }
 
 type UserTaskImage struct {
 	ID                  int    `gorm:"primaryKey"`
-	UserTaskChallengeID string `gorm:"index"`
+	UserTaskChallengeID string `gorm:"index;type:varchar(20)"`
 	ImageUrl            string
 	CreatedAt           time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt           time.Time      `gorm:"autoUpdateTime"`
@@ -37,11 +47,64 @@ type UserTaskImage struct {
 }
 
 type UserTaskStep struct {
-	ID                  int            `gorm:"primaryKey"`
-	UserTaskChallengeID string         `gorm:"index"`
-	TaskStepID          int            `gorm:"index"`
-	Completed           bool           `gorm:"default:false"`
-	CreatedAt           time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt           time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt           gorm.DeletedAt `gorm:"index"`
+	ID                  int                 `gorm:"primaryKey"`
+	UserTaskChallengeID string              `gorm:"index;type:varchar(20)"`
+	TaskStepID          int                 `gorm:"index"`
+	Completed           bool                `gorm:"default:false"`
+	Note                string              `gorm:"type:text"`
+	Images              []UserTaskStepImage `gorm:"foreignKey:UserTaskStepID"`
+	CreatedAt           time.Time           `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time           `gorm:"autoUpdateTime"`
+	DeletedAt           gorm.DeletedAt      `gorm:"index"`
+}
+
+// UserTaskStepImage is a photo submitted as evidence for a single step.
+type UserTaskStepImage struct {
+	ID             int `gorm:"primaryKey"`
+	UserTaskStepID int `gorm:"index"`
+	ImageUrl       string
+	CreatedAt      time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// UserTaskSubmission is one attempt at finishing a user task, kept as it was
+// sent together with the review it received.
+type UserTaskSubmission struct {
+	ID                  int    `gorm:"primaryKey"`
+	UserTaskChallengeID string `gorm:"index;type:varchar(20)"`
+	// Attempt is 1 for the first submission and grows with every resubmission.
+	Attempt          int
+	DescriptionImage string
+	Images           []UserTaskSubmissionImage `gorm:"foreignKey:UserTaskSubmissionID"`
+	SubmittedAt      time.Time
+	Decision         string `gorm:"type:enum('need_rivew', 'accept', 'reject');default:'need_rivew'"`
+	Reason           string
+	ReviewedBy       *string     `gorm:"type:varchar(20)"`
+	Reviewer         admin.Admin `gorm:"foreignKey:ReviewedBy"`
+	ReviewedAt       *time.Time
+	CreatedAt        time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+type UserTaskSubmissionImage struct {
+	ID                   int `gorm:"primaryKey"`
+	UserTaskSubmissionID int `gorm:"index"`
+	ImageUrl             string
+	CreatedAt            time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt            gorm.DeletedAt `gorm:"index"`
+}
+
+// OrderSubmissions is a preload scope that returns submissions oldest first.
+func OrderSubmissions(db *gorm.DB) *gorm.DB {
+	return db.Order("attempt ASC")
+}
+
+// ClaimedByOther reports whether another admin holds an unexpired claim on
+// the submission.
+func (u *UserTaskChallenge) ClaimedByOther(adminId string, now time.Time) bool {
+	return u.ClaimedBy != nil && *u.ClaimedBy != adminId &&
+		u.ClaimedUntil != nil && u.ClaimedUntil.After(now)
 }
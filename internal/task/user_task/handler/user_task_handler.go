@@ -15,4 +15,6 @@ type UserTaskHandler interface {
 	UpdateTaskStepHandler(c echo.Context) error
 	GetUserTaskByUserTaskIdHandler(c echo.Context) error
 	GetUserTaskRejectedByUserIdHandler(c echo.Context) error
+	GetSubmissionHistoryHandler(c echo.Context) error
+	WithdrawUserTaskHandler(c echo.Context) error
 }
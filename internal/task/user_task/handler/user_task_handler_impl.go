@@ -3,11 +3,14 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sawalreverr/recything/internal/helper"
 	"github.com/sawalreverr/recything/internal/task/user_task/dto"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 	"github.com/sawalreverr/recything/internal/task/user_task/usecase"
 	"github.com/sawalreverr/recything/pkg"
 )
@@ -21,8 +24,12 @@ func NewUserTaskHandler(usecase usecase.UserTaskUsecase) UserTaskHandler {
 }
 
 func (handler *UserTaskHandlerImpl) GetAllTasksHandler(c echo.Context) error {
-	userTask, err := handler.Usecase.GetAllTasksUsecase()
+	userId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	userTask, enrollments, err := handler.Usecase.GetAllTasksUsecase(userId)
 	if err != nil {
+		if errors.Is(err, pkg.ErrUserNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserNotFound.Error())
+		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail: "+err.Error())
 	}
 
@@ -33,21 +40,27 @@ func (handler *UserTaskHandlerImpl) GetAllTasksHandler(c echo.Context) error {
 
 		for _, step := range task.TaskSteps {
 			taskStep = append(taskStep, dto.TaskSteps{
-				Id:          step.ID,
-				Title:       step.Title,
-				Description: step.Description,
+				Id:            step.ID,
+				Title:         step.Title,
+				Description:   step.Description,
+				RequiresPhoto: step.RequiresPhoto,
 			})
 		}
 		data = append(data, dto.DataUserTask{
-			Id:          task.ID,
-			Title:       task.Title,
-			Description: task.Description,
-			Thumbnail:   task.Thumbnail,
-			StartDate:   task.StartDate,
-			EndDate:     task.EndDate,
-			Point:       task.Point,
-			Status:      task.Status,
-			TaskSteps:   taskStep,
+			Id:           task.ID,
+			Title:        task.Title,
+			Description:  task.Description,
+			Thumbnail:    task.Thumbnail,
+			StartDate:    task.StartDate,
+			EndDate:      task.EndDate,
+			Point:        task.Point,
+			Status:       task.Status,
+			Availability: task.Availability(time.Now()),
+			TaskSteps:    taskStep,
+
+			RemainingSlots:   enrollments[task.ID].RemainingSlots,
+			Eligible:         enrollments[task.ID].Eligible,
+			IneligibleReason: enrollments[task.ID].Reason,
 		})
 	}
 	responseData := helper.ResponseData(http.StatusOK, "success", data)
@@ -66,21 +79,23 @@ func (handler *UserTaskHandlerImpl) GetTaskByIdHandler(c echo.Context) error {
 	var taskStep []dto.TaskSteps
 	for _, step := range task.TaskSteps {
 		taskStep = append(taskStep, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 	data := dto.DataUserTask{
-		Id:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		Thumbnail:   task.Thumbnail,
-		StartDate:   task.StartDate,
-		EndDate:     task.EndDate,
-		Point:       task.Point,
-		Status:      task.Status,
-		TaskSteps:   taskStep,
+		Id:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Thumbnail:    task.Thumbnail,
+		StartDate:    task.StartDate,
+		EndDate:      task.EndDate,
+		Point:        task.Point,
+		Status:       task.Status,
+		Availability: task.Availability(time.Now()),
+		TaskSteps:    taskStep,
 	}
 	responseData := helper.ResponseData(http.StatusOK, "success", data)
 	return c.JSON(http.StatusOK, responseData)
@@ -97,9 +112,30 @@ func (handler *UserTaskHandlerImpl) CreateUserTaskHandler(c echo.Context) error
 		if errors.Is(err, pkg.ErrUserTaskExist) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskExist.Error())
 		}
+		if errors.Is(err, pkg.ErrTaskRejoinNotAllowed) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrTaskRejoinNotAllowed.Error())
+		}
 		if errors.Is(err, pkg.ErrTaskCannotBeFollowed) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrTaskCannotBeFollowed.Error())
 		}
+		if errors.Is(err, pkg.ErrTaskNotStarted) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrTaskNotStarted.Error())
+		}
+		if errors.Is(err, pkg.ErrTaskFull) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrTaskFull.Error())
+		}
+		if errors.Is(err, pkg.ErrTaskEnrollmentClosed) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrTaskEnrollmentClosed.Error())
+		}
+		if errors.Is(err, pkg.ErrTaskRegionRestricted) {
+			return helper.ErrorHandler(c, http.StatusForbidden, pkg.ErrTaskRegionRestricted.Error())
+		}
+		if errors.Is(err, pkg.ErrTaskAchievementRequired) {
+			return helper.ErrorHandler(c, http.StatusForbidden, pkg.ErrTaskAchievementRequired.Error())
+		}
+		if errors.Is(err, pkg.ErrUserNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserNotFound.Error())
+		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail: "+err.Error())
 	}
 
@@ -108,9 +144,10 @@ func (handler *UserTaskHandlerImpl) CreateUserTaskHandler(c echo.Context) error
 
 	for _, step := range userTask.TaskChallenge.TaskSteps {
 		taskStep = append(taskStep, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 
@@ -120,6 +157,8 @@ func (handler *UserTaskHandlerImpl) CreateUserTaskHandler(c echo.Context) error
 			UserTaskChallengeID: step.UserTaskChallengeID,
 			TaskStepID:          step.TaskStepID,
 			Completed:           step.Completed,
+			Note:                step.Note,
+			Images:              stepImageUrls(step.Images),
 		})
 	}
 	data := dto.TaskChallengeResponseCreate{
@@ -161,16 +200,18 @@ func (handler *UserTaskHandlerImpl) UploadImageTaskHandler(c echo.Context) error
 	if errForm != nil {
 		return helper.ErrorHandler(c, http.StatusBadRequest, errForm.Error())
 	}
+	// photos proving each step are attached when the step is completed, the
+	// images sent with the final submission are optional
 	images := form.File["images"]
-	if len(images) == 0 {
-		return helper.ErrorHandler(c, http.StatusBadRequest, "image is required")
-	}
 
 	userTask, err := handler.Usecase.UploadImageTaskUsecase(&request, images, claims.UserID, userTaskId)
 	if err != nil {
 		if errors.Is(err, pkg.ErrUserTaskNotFound) {
 			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserTaskNotFound.Error())
 		}
+		if errors.Is(err, pkg.ErrUserTaskAbandoned) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskAbandoned.Error())
+		}
 		if errors.Is(err, pkg.ErrUserTaskDone) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskDone.Error())
 		}
@@ -189,6 +230,9 @@ func (handler *UserTaskHandlerImpl) UploadImageTaskHandler(c echo.Context) error
 		if errors.Is(err, pkg.ErrImagesExceed) {
 			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrImagesExceed.Error())
 		}
+		if errors.Is(err, pkg.ErrResubmissionLimit) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrResubmissionLimit.Error())
+		}
 		if errors.Is(err, pkg.ErrUserTaskNotCompleted) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskNotCompleted.Error())
 		}
@@ -222,14 +266,17 @@ func (handler *UserTaskHandlerImpl) UploadImageTaskHandler(c echo.Context) error
 			UserTaskChallengeID: step.UserTaskChallengeID,
 			TaskStepID:          step.TaskStepID,
 			Completed:           step.Completed,
+			Note:                step.Note,
+			Images:              stepImageUrls(step.Images),
 		})
 	}
 
 	for _, step := range userTask.TaskChallenge.TaskSteps {
 		taskStep = append(taskStep, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 	for _, image := range userTask.ImageTask {
@@ -276,9 +323,10 @@ func (handler *UserTaskHandlerImpl) GetUserTaskByUserIdHandler(c echo.Context) e
 
 		for _, step := range userTask.TaskChallenge.TaskSteps {
 			data[len(data)-1].TaskChallenge.TaskSteps = append(data[len(data)-1].TaskChallenge.TaskSteps, dto.TaskSteps{
-				Id:          step.ID,
-				Title:       step.Title,
-				Description: step.Description,
+				Id:            step.ID,
+				Title:         step.Title,
+				Description:   step.Description,
+				RequiresPhoto: step.RequiresPhoto,
 			})
 		}
 
@@ -288,6 +336,8 @@ func (handler *UserTaskHandlerImpl) GetUserTaskByUserIdHandler(c echo.Context) e
 				UserTaskChallengeID: step.UserTaskChallengeID,
 				TaskStepID:          step.TaskStepID,
 				Completed:           step.Completed,
+				Note:                step.Note,
+				Images:              stepImageUrls(step.Images),
 			})
 		}
 	}
@@ -329,9 +379,10 @@ func (handler *UserTaskHandlerImpl) GetUserTaskDoneByUserIdHandler(c echo.Contex
 
 		for _, step := range userTask.TaskChallenge.TaskSteps {
 			data[len(data)-1].TaskChallenge.TaskSteps = append(data[len(data)-1].TaskChallenge.TaskSteps, dto.TaskSteps{
-				Id:          step.ID,
-				Title:       step.Title,
-				Description: step.Description,
+				Id:            step.ID,
+				Title:         step.Title,
+				Description:   step.Description,
+				RequiresPhoto: step.RequiresPhoto,
 			})
 		}
 
@@ -341,6 +392,8 @@ func (handler *UserTaskHandlerImpl) GetUserTaskDoneByUserIdHandler(c echo.Contex
 				UserTaskChallengeID: step.UserTaskChallengeID,
 				TaskStepID:          step.TaskStepID,
 				Completed:           step.Completed,
+				Note:                step.Note,
+				Images:              stepImageUrls(step.Images),
 			})
 		}
 	}
@@ -377,6 +430,9 @@ func (handler *UserTaskHandlerImpl) UpdateUserTaskHandler(c echo.Context) error
 		if errors.Is(err, pkg.ErrUserTaskNotFound) {
 			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserTaskNotFound.Error())
 		}
+		if errors.Is(err, pkg.ErrUserTaskAbandoned) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskAbandoned.Error())
+		}
 		if errors.Is(err, pkg.ErrUserTaskDone) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskDone.Error())
 		}
@@ -398,6 +454,9 @@ func (handler *UserTaskHandlerImpl) UpdateUserTaskHandler(c echo.Context) error
 		if errors.Is(err, pkg.ErrUserTaskNotReject) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskNotReject.Error())
 		}
+		if errors.Is(err, pkg.ErrResubmissionLimit) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrResubmissionLimit.Error())
+		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail: "+err.Error())
 	}
 	var taskStep []dto.TaskSteps
@@ -428,14 +487,17 @@ func (handler *UserTaskHandlerImpl) UpdateUserTaskHandler(c echo.Context) error
 			UserTaskChallengeID: step.UserTaskChallengeID,
 			TaskStepID:          step.TaskStepID,
 			Completed:           step.Completed,
+			Note:                step.Note,
+			Images:              stepImageUrls(step.Images),
 		})
 	}
 
 	for _, step := range userTask.TaskChallenge.TaskSteps {
 		taskStep = append(taskStep, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 	for _, image := range userTask.ImageTask {
@@ -486,7 +548,7 @@ func (handler *UserTaskHandlerImpl) GetUserTaskDetailsHandler(c echo.Context) er
 
 func (handler *UserTaskHandlerImpl) GetHistoryPointByUserIdHandler(c echo.Context) error {
 	userId := c.Get("user").(*helper.JwtCustomClaims).UserID
-	userTask, totalPoint, err := handler.Usecase.GetHistoryPointByUserIdUsecase(userId)
+	entries, totalPoint, err := handler.Usecase.GetHistoryPointByUserIdUsecase(userId)
 	if err != nil {
 		if errors.Is(err, pkg.ErrUserNoHasTask) {
 			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserNoHasTask.Error())
@@ -495,12 +557,18 @@ func (handler *UserTaskHandlerImpl) GetHistoryPointByUserIdHandler(c echo.Contex
 	}
 
 	var dataHistoryPoints []*dto.DataHistoryPoint
-	for _, task := range userTask {
+	for _, entry := range entries {
 		dataHistoryPoints = append(dataHistoryPoints, &dto.DataHistoryPoint{
-			Id:         task.ID,
-			TitleTask:  task.TaskChallenge.Title,
-			Point:      task.Point,
-			AcceptedAt: task.AcceptedAt,
+			Id:           entry.SourceID,
+			TitleTask:    entry.Description,
+			Point:        entry.Amount,
+			AcceptedAt:   entry.CreatedAt,
+			EntryId:      entry.ID,
+			SourceType:   entry.SourceType,
+			BasePoint:    entry.BasePoint,
+			BonusPercent: entry.BonusPercent,
+			BonusPoint:   entry.BonusPoint,
+			BalanceAfter: entry.BalanceAfter,
 		})
 	}
 
@@ -516,15 +584,31 @@ func (handler *UserTaskHandlerImpl) GetHistoryPointByUserIdHandler(c echo.Contex
 func (handler *UserTaskHandlerImpl) UpdateTaskStepHandler(c echo.Context) error {
 	userId := c.Get("user").(*helper.JwtCustomClaims).UserID
 	request := new(dto.UpdateTaskStepRequest)
-	if err := c.Bind(request); err != nil {
+
+	// steps with photo evidence are sent as multipart form with the fields in
+	// json_data, steps without photos may still be sent as a plain JSON body
+	var images []*multipart.FileHeader
+	if jsonData := c.FormValue("json_data"); jsonData != "" {
+		if err := json.Unmarshal([]byte(jsonData), request); err != nil {
+			return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+		}
+		form, errForm := c.MultipartForm()
+		if errForm != nil {
+			return helper.ErrorHandler(c, http.StatusBadRequest, errForm.Error())
+		}
+		images = form.File["images"]
+	} else if err := c.Bind(request); err != nil {
 		return helper.ErrorHandler(c, http.StatusBadRequest, "invalid request body")
 	}
 
-	userTask, err := handler.Usecase.UpdateTaskStepUsecase(request, userId)
+	userTask, err := handler.Usecase.UpdateTaskStepUsecase(request, images, userId)
 	if err != nil {
 		if errors.Is(err, pkg.ErrUserTaskNotFound) {
 			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserTaskNotFound.Error())
 		}
+		if errors.Is(err, pkg.ErrUserTaskAbandoned) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskAbandoned.Error())
+		}
 		if errors.Is(err, pkg.ErrUserTaskDone) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskDone.Error())
 		}
@@ -540,6 +624,15 @@ func (handler *UserTaskHandlerImpl) UpdateTaskStepHandler(c echo.Context) error
 		if errors.Is(err, pkg.ErrUserTaskStepAlreadyCompleted) {
 			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskStepAlreadyCompleted.Error())
 		}
+		if errors.Is(err, pkg.ErrStepPhotoRequired) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrStepPhotoRequired.Error())
+		}
+		if errors.Is(err, pkg.ErrImagesExceed) {
+			return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrImagesExceed.Error())
+		}
+		if errors.Is(err, pkg.ErrUploadCloudinary) {
+			return helper.ErrorHandler(c, http.StatusInternalServerError, pkg.ErrUploadCloudinary.Error())
+		}
 		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail: "+err.Error())
 	}
 
@@ -548,9 +641,10 @@ func (handler *UserTaskHandlerImpl) UpdateTaskStepHandler(c echo.Context) error
 
 	for _, step := range userTask.TaskChallenge.TaskSteps {
 		taskStep = append(taskStep, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 
@@ -560,6 +654,8 @@ func (handler *UserTaskHandlerImpl) UpdateTaskStepHandler(c echo.Context) error
 			UserTaskChallengeID: step.UserTaskChallengeID,
 			TaskStepID:          step.TaskStepID,
 			Completed:           step.Completed,
+			Note:                step.Note,
+			Images:              stepImageUrls(step.Images),
 		})
 	}
 	data := dto.TaskChallengeResponseCreate{
@@ -598,9 +694,10 @@ func (handler *UserTaskHandlerImpl) GetUserTaskByUserTaskIdHandler(c echo.Contex
 
 	for _, step := range userTask.TaskChallenge.TaskSteps {
 		taskStep = append(taskStep, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 
@@ -610,6 +707,8 @@ func (handler *UserTaskHandlerImpl) GetUserTaskByUserTaskIdHandler(c echo.Contex
 			UserTaskChallengeID: step.UserTaskChallengeID,
 			TaskStepID:          step.TaskStepID,
 			Completed:           step.Completed,
+			Note:                step.Note,
+			Images:              stepImageUrls(step.Images),
 		})
 	}
 	data := dto.DataGetUserTaskByUserTaskId{
@@ -653,9 +752,10 @@ func (handler *UserTaskHandlerImpl) GetUserTaskRejectedByUserIdHandler(c echo.Co
 
 	for _, step := range userTask.TaskChallenge.TaskSteps {
 		taskStep = append(taskStep, dto.TaskSteps{
-			Id:          step.ID,
-			Title:       step.Title,
-			Description: step.Description,
+			Id:            step.ID,
+			Title:         step.Title,
+			Description:   step.Description,
+			RequiresPhoto: step.RequiresPhoto,
 		})
 	}
 
@@ -665,6 +765,8 @@ func (handler *UserTaskHandlerImpl) GetUserTaskRejectedByUserIdHandler(c echo.Co
 			UserTaskChallengeID: step.UserTaskChallengeID,
 			TaskStepID:          step.TaskStepID,
 			Completed:           step.Completed,
+			Note:                step.Note,
+			Images:              stepImageUrls(step.Images),
 		})
 	}
 	dataTaskChallenges := dto.DataTaskChallenges{
@@ -690,3 +792,78 @@ func (handler *UserTaskHandlerImpl) GetUserTaskRejectedByUserIdHandler(c echo.Co
 	responseData := helper.ResponseData(http.StatusCreated, "success", dataUsertask)
 	return c.JSON(http.StatusOK, responseData)
 }
+
+func (handler *UserTaskHandlerImpl) GetSubmissionHistoryHandler(c echo.Context) error {
+	userId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	userTaskId := c.Param("userTaskId")
+	userTask, resubmissionsLeft, err := handler.Usecase.GetSubmissionHistoryUsecase(userId, userTaskId)
+	if err != nil {
+		if errors.Is(err, pkg.ErrUserTaskNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserTaskNotFound.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail: "+err.Error())
+	}
+
+	data := dto.SubmissionHistoryResponse{
+		UserTaskId:        userTask.ID,
+		TaskTitle:         userTask.TaskChallenge.Title,
+		StatusAccept:      userTask.StatusAccept,
+		MaxResubmissions:  userTask.TaskChallenge.MaxResubmissions,
+		ResubmissionsLeft: resubmissionsLeft,
+		Submissions:       []dto.DataSubmission{},
+	}
+	for _, submission := range userTask.Submissions {
+		images := make([]string, 0, len(submission.Images))
+		for _, image := range submission.Images {
+			images = append(images, image.ImageUrl)
+		}
+		data.Submissions = append(data.Submissions, dto.DataSubmission{
+			Attempt:      submission.Attempt,
+			Description:  submission.DescriptionImage,
+			Images:       images,
+			SubmittedAt:  submission.SubmittedAt,
+			Decision:     submission.Decision,
+			Reason:       submission.Reason,
+			ReviewerName: submission.Reviewer.Name,
+			ReviewedAt:   submission.ReviewedAt,
+		})
+	}
+
+	responseData := helper.ResponseData(http.StatusOK, "success get submission history", data)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func (handler *UserTaskHandlerImpl) WithdrawUserTaskHandler(c echo.Context) error {
+	userId := c.Get("user").(*helper.JwtCustomClaims).UserID
+	userTaskId := c.Param("userTaskId")
+	userTask, err := handler.Usecase.WithdrawUserTaskUsecase(userId, userTaskId)
+	if err != nil {
+		if errors.Is(err, pkg.ErrUserTaskNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, pkg.ErrUserTaskNotFound.Error())
+		}
+		if errors.Is(err, pkg.ErrUserTaskAbandoned) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskAbandoned.Error())
+		}
+		if errors.Is(err, pkg.ErrUserTaskNotWithdrawable) {
+			return helper.ErrorHandler(c, http.StatusConflict, pkg.ErrUserTaskNotWithdrawable.Error())
+		}
+		return helper.ErrorHandler(c, http.StatusInternalServerError, "internal server error, detail: "+err.Error())
+	}
+
+	data := dto.UserTaskWithdrawResponse{
+		Id:              userTask.ID,
+		TaskChallengeId: userTask.TaskChallengeId,
+		StatusProgress:  userTask.StatusProgress,
+		AbandonedAt:     userTask.AbandonedAt,
+	}
+	responseData := helper.ResponseData(http.StatusOK, "success withdraw from task", data)
+	return c.JSON(http.StatusOK, responseData)
+}
+
+func stepImageUrls(images []user_task.UserTaskStepImage) []string {
+	urls := make([]string, 0, len(images))
+	for _, image := range images {
+		urls = append(urls, image.ImageUrl)
+	}
+	return urls
+}
@@ -1,14 +1,17 @@
 package repository
 
 import (
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
+	"github.com/sawalreverr/recything/internal/point"
 	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"github.com/sawalreverr/recything/internal/user"
 )
 
 type UserTaskRepository interface {
 	GetAllTasks() ([]task.TaskChallenge, error)
 	GetTaskById(id string) (*task.TaskChallenge, error)
-	FindLastIdTaskChallenge() (string, error)
+	NextIdUserTask() (string, error)
 	FindUserTask(userId string, userTaskId string) (*user_task.UserTaskChallenge, error)
 	CreateUserTask(userTask *user_task.UserTaskChallenge) (*user_task.UserTaskChallenge, error)
 	FindTask(taskId string) (*task.TaskChallenge, error)
@@ -16,13 +19,19 @@ type UserTaskRepository interface {
 	GetUserTaskByUserId(userId string) ([]user_task.UserTaskChallenge, error)
 	GetUserTaskDoneByUserId(userId string) ([]user_task.UserTaskChallenge, error)
 	FindUserHasSameTask(userId string, taskId string) (*user_task.UserTaskChallenge, error)
+	FindUser(userId string) (*user.User, error)
+	FindLevel(achievementId int) (*achievement.Achievement, error)
+	FindLevelReachedBy(userId string) (*achievement.Achievement, error)
+	CountParticipants(taskIds []string) (map[string]int, error)
 	UpdateUserTask(userTask *user_task.UserTaskChallenge, userTaskId string) (*user_task.UserTaskChallenge, error)
 	GetUserTaskDetails(userTaskId string, userId string) (*user_task.UserTaskChallenge, []*user_task.UserTaskImage, error)
-	GetHistoryPointByUserId(userId string) ([]user_task.UserTaskChallenge, error)
+	GetHistoryPointByUserId(userId string) ([]point.Entry, error)
 	FindTaskStep(stepId int, taskId string) (*task.TaskStep, error)
 	FindUserTaskStep(userTaskChallengeID string, taskStepID int) (*user_task.UserTaskStep, error)
 	UpdateUserTaskStep(userTaskStep *user_task.UserTaskStep) error
 	FindUserSteps(userTaskChallengeID string) ([]user_task.UserTaskStep, error)
-	FindCompletedUserSteps(userTaskChallengeID string) ([]user_task.UserTaskStep, error)
 	GetUserTaskRejectedByUserId(userId string, userTaskId string) (*user_task.UserTaskChallenge, error)
+	CountSubmissions(userId string, taskChallengeId string) (int, error)
+	GetSubmissionHistory(userId string, userTaskId string) (*user_task.UserTaskChallenge, error)
+	WithdrawUserTask(userId string, userTaskId string) (*user_task.UserTaskChallenge, error)
 }
@@ -1,10 +1,18 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/database"
+	"github.com/sawalreverr/recything/internal/point"
 	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	"github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/pkg"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserTaskRepositoryImpl struct {
@@ -18,7 +26,8 @@ func NewUserTaskRepository(db database.Database) UserTaskRepository {
 func (repository *UserTaskRepositoryImpl) GetAllTasks() ([]task.TaskChallenge, error) {
 	var tasks []task.TaskChallenge
 	if err := repository.DB.GetDB().
-		Preload("TaskSteps").
+		Preload("TaskSteps", task.OrderSteps).
+		Preload("MinAchievement").
 		Order("id desc").
 		Find(&tasks, "status = ?", true).
 		Error; err != nil {
@@ -28,30 +37,68 @@ func (repository *UserTaskRepositoryImpl) GetAllTasks() ([]task.TaskChallenge, e
 }
 
 func (repository *UserTaskRepositoryImpl) GetTaskById(id string) (*task.TaskChallenge, error) {
-	var task task.TaskChallenge
+	var taskChallenge task.TaskChallenge
 	if err := repository.DB.GetDB().
-		Preload("TaskSteps").
+		Preload("TaskSteps", task.OrderSteps).
 		Where("id = ?", id).
-		First(&task).
+		First(&taskChallenge).
 		Error; err != nil {
 		return nil, err
 	}
-	return &task, nil
+	return &taskChallenge, nil
 }
 
-func (repository *UserTaskRepositoryImpl) FindLastIdTaskChallenge() (string, error) {
-	var task user_task.UserTaskChallenge
-	if err := repository.DB.GetDB().Unscoped().Order("id desc").First(&task).Error; err != nil {
-		return "UT0000", err
-	}
-	return task.ID, nil
+func (repository *UserTaskRepositoryImpl) NextIdUserTask() (string, error) {
+	return database.NextID(repository.DB, "UT")
 }
 
 func (repository *UserTaskRepositoryImpl) CreateUserTask(userTask *user_task.UserTaskChallenge) (*user_task.UserTaskChallenge, error) {
 	var result user_task.UserTaskChallenge
 	err := repository.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Lock the challenge so concurrent joins are counted one at a time
+		var challenge task.TaskChallenge
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userTask.TaskChallengeId).
+			First(&challenge).Error; err != nil {
+			return err
+		}
+
+		var joined int64
+		if err := tx.Model(&user_task.UserTaskChallenge{}).
+			Where("user_id = ? AND task_challenge_id = ? AND status_progress <> ?", userTask.UserId, userTask.TaskChallengeId, "abandoned").
+			Count(&joined).Error; err != nil {
+			return err
+		}
+		if joined > 0 {
+			return pkg.ErrUserTaskExist
+		}
+
+		if !challenge.RejoinAllowed() {
+			var withdrawn int64
+			if err := tx.Model(&user_task.UserTaskChallenge{}).
+				Where("user_id = ? AND task_challenge_id = ?", userTask.UserId, userTask.TaskChallengeId).
+				Count(&withdrawn).Error; err != nil {
+				return err
+			}
+			if withdrawn > 0 {
+				return pkg.ErrTaskRejoinNotAllowed
+			}
+		}
+
+		if challenge.MaxParticipants > 0 {
+			var participants int64
+			if err := tx.Model(&user_task.UserTaskChallenge{}).
+				Where("task_challenge_id = ? AND status_progress <> ?", userTask.TaskChallengeId, "abandoned").
+				Count(&participants).Error; err != nil {
+				return err
+			}
+			if participants >= int64(challenge.MaxParticipants) {
+				return pkg.ErrTaskFull
+			}
+		}
+
 		// Create the UserTaskChallenge
-		if err := tx.Create(userTask).Error; err != nil {
+		if err := tx.Omit(clause.Associations).Create(userTask).Error; err != nil {
 			return err
 		}
 
@@ -67,8 +114,8 @@ func (repository *UserTaskRepositoryImpl) CreateUserTask(userTask *user_task.Use
 			}
 		}
 
-		if err := tx.Preload("TaskChallenge.TaskSteps").
-			Preload("UserTaskSteps").
+		if err := tx.Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+			Preload("UserTaskSteps.Images").
 			Where("user_task_challenges.id = ?", userTask.ID).
 			First(&result).Error; err != nil {
 			return err
@@ -87,8 +134,8 @@ func (repository *UserTaskRepositoryImpl) CreateUserTask(userTask *user_task.Use
 func (repository *UserTaskRepositoryImpl) FindUserTask(userId string, userTaskId string) (*user_task.UserTaskChallenge, error) {
 	var userTask user_task.UserTaskChallenge
 	if err := repository.DB.GetDB().
-		Preload("TaskChallenge.TaskSteps").
-		Preload("UserTaskSteps").
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps.Images").
 		Where("user_id = ? and id = ?", userId, userTaskId).
 		First(&userTask).Error; err != nil {
 		return nil, err
@@ -97,26 +144,34 @@ func (repository *UserTaskRepositoryImpl) FindUserTask(userId string, userTaskId
 }
 
 func (repository *UserTaskRepositoryImpl) FindTask(taskId string) (*task.TaskChallenge, error) {
-	var task task.TaskChallenge
+	var taskChallenge task.TaskChallenge
 	if err := repository.DB.GetDB().
-		Preload("TaskSteps").
-		Where("id = ?", taskId).First(&task).Error; err != nil {
+		Preload("TaskSteps", task.OrderSteps).
+		Preload("MinAchievement").
+		Where("id = ?", taskId).First(&taskChallenge).Error; err != nil {
 		return nil, err
 	}
-	return &task, nil
+	return &taskChallenge, nil
 }
 
 func (repository *UserTaskRepositoryImpl) UploadImageTask(userTask *user_task.UserTaskChallenge, userTaskId string) (*user_task.UserTaskChallenge, error) {
 	tx := repository.DB.GetDB().Begin()
+	submittedAt := time.Now()
 	if err := tx.Model(&user_task.UserTaskChallenge{}).Where("id = ?", userTaskId).Updates(map[string]interface{}{
 		"description_image": userTask.DescriptionImage,
 		"status_progress":   userTask.StatusProgress,
 		"point":             userTask.Point,
+		"submitted_at":      submittedAt,
 	}).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
+	if err := createSubmission(tx, userTaskId, userTask, submittedAt); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	var count int64
 	if err := tx.Model(&user_task.UserTaskImage{}).Where("user_task_challenge_id = ?", userTaskId).Count(&count).Error; err != nil {
 		tx.Rollback()
@@ -139,8 +194,8 @@ func (repository *UserTaskRepositoryImpl) UploadImageTask(userTask *user_task.Us
 	}
 
 	tx.Preload("UserTaskImage").
-		Preload("TaskChallenge.TaskSteps").
-		Preload("UserTaskSteps").
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps.Images").
 		Where("id = ?", userTaskId).
 		First(&userTask)
 
@@ -155,8 +210,8 @@ func (repository *UserTaskRepositoryImpl) UploadImageTask(userTask *user_task.Us
 func (repository *UserTaskRepositoryImpl) GetUserTaskByUserId(userId string) ([]user_task.UserTaskChallenge, error) {
 	var userTask []user_task.UserTaskChallenge
 	if err := repository.DB.GetDB().
-		Preload("TaskChallenge.TaskSteps").
-		Preload("UserTaskSteps").
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps.Images").
 		Where("user_id = ? and status_progress = ?", userId, "in_progress").
 		Order("id desc").
 		Find(&userTask).Error; err != nil {
@@ -168,8 +223,8 @@ func (repository *UserTaskRepositoryImpl) GetUserTaskByUserId(userId string) ([]
 func (repository *UserTaskRepositoryImpl) GetUserTaskDoneByUserId(userId string) ([]user_task.UserTaskChallenge, error) {
 	var userTask []user_task.UserTaskChallenge
 	if err := repository.DB.GetDB().
-		Preload("TaskChallenge.TaskSteps").
-		Preload("UserTaskSteps").
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps.Images").
 		Where("user_id = ? and status_progress = ?", userId, "done").
 		Order("id desc").
 		Find(&userTask).Error; err != nil {
@@ -178,10 +233,72 @@ func (repository *UserTaskRepositoryImpl) GetUserTaskDoneByUserId(userId string)
 	return userTask, nil
 }
 
+func (repository *UserTaskRepositoryImpl) FindUser(userId string) (*user.User, error) {
+	var found user.User
+	if err := repository.DB.GetDB().Where("id = ?", userId).First(&found).Error; err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+// FindLevel returns an achievement that is a level, the kind users hold one of.
+func (repository *UserTaskRepositoryImpl) FindLevel(achievementId int) (*achievement.Achievement, error) {
+	var found achievement.Achievement
+	if err := repository.DB.GetDB().Scopes(achievement.PointLevels).Where("id = ?", achievementId).First(&found).Error; err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+// FindLevelReachedBy returns the highest level the earned points of the user
+// reach.
+func (repository *UserTaskRepositoryImpl) FindLevelReachedBy(userId string) (*achievement.Achievement, error) {
+	var earned int
+	if err := repository.DB.GetDB().Model(&point.Entry{}).
+		Scopes(point.Earned).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userId).
+		Scan(&earned).Error; err != nil {
+		return nil, err
+	}
+
+	var found achievement.Achievement
+	if err := repository.DB.GetDB().Scopes(achievement.ReachedBy(earned)).Order("target_point desc").First(&found).Error; err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+func (repository *UserTaskRepositoryImpl) CountParticipants(taskIds []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(taskIds) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TaskChallengeId string
+		Total           int
+	}
+	if err := repository.DB.GetDB().Model(&user_task.UserTaskChallenge{}).
+		Select("task_challenge_id, COUNT(*) AS total").
+		Where("task_challenge_id IN ? AND status_progress <> ?", taskIds, "abandoned").
+		Group("task_challenge_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.TaskChallengeId] = row.Total
+	}
+	return counts, nil
+}
+
 func (repository *UserTaskRepositoryImpl) FindUserHasSameTask(userId string, taskId string) (*user_task.UserTaskChallenge, error) {
 
 	var userTask user_task.UserTaskChallenge
-	if err := repository.DB.GetDB().Where("user_id = ? and task_challenge_id = ?", userId, taskId).First(&userTask).Error; err != nil {
+	if err := repository.DB.GetDB().
+		Where("user_id = ? and task_challenge_id = ? and status_progress <> ?", userId, taskId, "abandoned").
+		First(&userTask).Error; err != nil {
 		return nil, err
 	}
 	return &userTask, nil
@@ -190,39 +307,57 @@ func (repository *UserTaskRepositoryImpl) FindUserHasSameTask(userId string, tas
 // update user task if reject
 func (repository *UserTaskRepositoryImpl) UpdateUserTask(userTask *user_task.UserTaskChallenge, userTaskId string) (*user_task.UserTaskChallenge, error) {
 	tx := repository.DB.GetDB().Begin()
+
+	// the row lock makes concurrent resubmissions of the same rejection wait,
+	// the later one then sees it is no longer rejected
+	var current user_task.UserTaskChallenge
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", userTaskId).
+		First(&current).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if current.StatusProgress != "done" || current.StatusAccept != "reject" {
+		tx.Rollback()
+		return nil, pkg.ErrUserTaskNotReject
+	}
+
+	submittedAt := time.Now()
 	if err := tx.Model(&user_task.UserTaskChallenge{}).Where("id = ?", userTaskId).Updates(map[string]interface{}{
 		"description_image": userTask.DescriptionImage,
 		"status_accept":     userTask.StatusAccept,
+		"submitted_at":      submittedAt,
+		"claimed_by":        nil,
+		"claimed_until":     nil,
 	}).
 		Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	var count int64
-	if err := tx.Model(&user_task.UserTaskImage{}).Where("user_task_challenge_id = ?", userTaskId).Count(&count).Error; err != nil {
+	// the current images always show the latest attempt, earlier ones stay on
+	// their submission
+	if err := tx.Where("user_task_challenge_id = ?", userTaskId).Delete(&user_task.UserTaskImage{}).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	if count != 0 {
-		if err := tx.Where("user_task_challenge_id = ?", userTaskId).Delete(&user_task.UserTaskImage{}).Error; err != nil {
+	for _, img := range userTask.ImageTask {
+		img.UserTaskChallengeID = userTaskId
+		if err := tx.Create(&img).Error; err != nil {
 			tx.Rollback()
 			return nil, err
 		}
+	}
 
-		for _, img := range userTask.ImageTask {
-			img.UserTaskChallengeID = userTaskId
-			if err := tx.Create(&img).Error; err != nil {
-				tx.Rollback()
-				return nil, err
-			}
-		}
+	if err := createSubmission(tx, userTaskId, userTask, submittedAt); err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
 	tx.Preload("UserTaskImage").
-		Preload("TaskChallenge.TaskSteps").
-		Preload("UserTaskSteps").
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps.Images").
 		Where("id = ?", userTaskId).
 		First(&userTask)
 
@@ -239,7 +374,7 @@ func (repository *UserTaskRepositoryImpl) GetUserTaskDetails(userTaskId string,
 	if err := repository.DB.GetDB().
 		Preload("User").
 		Preload("TaskChallenge").
-		Preload("UserTaskSteps").
+		Preload("UserTaskSteps.Images").
 		Where("id = ? ", userTaskId).
 		Where("user_id = ?", userId).
 		First(&userTask).Error; err != nil {
@@ -255,17 +390,15 @@ func (repository *UserTaskRepositoryImpl) GetUserTaskDetails(userTaskId string,
 	return &userTask, images, nil
 }
 
-func (repository *UserTaskRepositoryImpl) GetHistoryPointByUserId(userId string) ([]user_task.UserTaskChallenge, error) {
-	var userTask []user_task.UserTaskChallenge
+func (repository *UserTaskRepositoryImpl) GetHistoryPointByUserId(userId string) ([]point.Entry, error) {
+	var entries []point.Entry
 	if err := repository.DB.GetDB().
-		Preload("TaskChallenge").
 		Where("user_id = ?", userId).
-		Where("status_accept = ?", "accept").
-		Order("accepted_at desc").
-		Find(&userTask).Error; err != nil {
+		Order("id desc").
+		Find(&entries).Error; err != nil {
 		return nil, err
 	}
-	return userTask, nil
+	return entries, nil
 }
 
 func (repository *UserTaskRepositoryImpl) FindTaskStep(stepId int, taskId string) (*task.TaskStep, error) {
@@ -295,18 +428,12 @@ func (repository *UserTaskRepositoryImpl) FindUserSteps(userTaskChallengeID stri
 	return userTaskStep, err
 }
 
-func (repository *UserTaskRepositoryImpl) FindCompletedUserSteps(userTaskChallengeID string) ([]user_task.UserTaskStep, error) {
-	var userTaskSteps []user_task.UserTaskStep
-	err := repository.DB.GetDB().Where("user_task_challenge_id = ? AND completed = ?", userTaskChallengeID, true).Order("task_step_id asc").Find(&userTaskSteps).Error
-	return userTaskSteps, err
-}
-
 func (repository *UserTaskRepositoryImpl) GetUserTaskRejectedByUserId(userId string, userTaskId string) (*user_task.UserTaskChallenge, error) {
 	var userTask user_task.UserTaskChallenge
 	if err := repository.DB.GetDB().
 		Preload("User").
-		Preload("TaskChallenge.TaskSteps").
-		Preload("UserTaskSteps").
+		Preload("TaskChallenge.TaskSteps", task.OrderSteps).
+		Preload("UserTaskSteps.Images").
 		Where("id = ? ", userTaskId).
 		Where("user_id = ?", userId).
 		Where("status_accept = ?", "reject").
@@ -315,3 +442,93 @@ func (repository *UserTaskRepositoryImpl) GetUserTaskRejectedByUserId(userId str
 	}
 	return &userTask, nil
 }
+
+// createSubmission stores the description and images of a user task as its
+// next attempt.
+func createSubmission(tx *gorm.DB, userTaskId string, userTask *user_task.UserTaskChallenge, submittedAt time.Time) error {
+	var attempts int64
+	if err := tx.Model(&user_task.UserTaskSubmission{}).
+		Where("user_task_challenge_id = ?", userTaskId).
+		Count(&attempts).Error; err != nil {
+		return err
+	}
+
+	submission := user_task.UserTaskSubmission{
+		UserTaskChallengeID: userTaskId,
+		Attempt:             int(attempts) + 1,
+		DescriptionImage:    userTask.DescriptionImage,
+		SubmittedAt:         submittedAt,
+		Decision:            "need_rivew",
+	}
+	for _, img := range userTask.ImageTask {
+		submission.Images = append(submission.Images, user_task.UserTaskSubmissionImage{ImageUrl: img.ImageUrl})
+	}
+	return tx.Create(&submission).Error
+}
+
+// CountSubmissions counts what a user sent for review on a challenge over all
+// their attempts, so withdrawing and joining again does not reset the count.
+func (repository *UserTaskRepositoryImpl) CountSubmissions(userId string, taskChallengeId string) (int, error) {
+	var total int64
+	if err := repository.DB.GetDB().Model(&user_task.UserTaskSubmission{}).
+		Joins("JOIN user_task_challenges ON user_task_challenges.id = user_task_submissions.user_task_challenge_id").
+		Where("user_task_challenges.user_id = ? AND user_task_challenges.task_challenge_id = ?", userId, taskChallengeId).
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+func (repository *UserTaskRepositoryImpl) GetSubmissionHistory(userId string, userTaskId string) (*user_task.UserTaskChallenge, error) {
+	var userTask user_task.UserTaskChallenge
+	if err := repository.DB.GetDB().
+		Preload("TaskChallenge").
+		Preload("Submissions", user_task.OrderSubmissions).
+		Preload("Submissions.Images").
+		Preload("Submissions.Reviewer").
+		Where("id = ? AND user_id = ?", userTaskId, userId).
+		First(&userTask).Error; err != nil {
+		return nil, err
+	}
+	return &userTask, nil
+}
+
+// WithdrawUserTask marks a user task as abandoned. It stays stored for
+// statistics but no longer takes a participant slot.
+func (repository *UserTaskRepositoryImpl) WithdrawUserTask(userId string, userTaskId string) (*user_task.UserTaskChallenge, error) {
+	var userTask user_task.UserTaskChallenge
+	err := repository.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", userTaskId, userId).
+			First(&userTask).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ErrUserTaskNotFound
+			}
+			return err
+		}
+
+		switch {
+		case userTask.StatusProgress == "abandoned":
+			return pkg.ErrUserTaskAbandoned
+		case userTask.StatusProgress == "done" && userTask.StatusAccept != "reject":
+			return pkg.ErrUserTaskNotWithdrawable
+		}
+
+		abandonedAt := time.Now()
+		if err := tx.Model(&userTask).Updates(map[string]interface{}{
+			"status_progress": "abandoned",
+			"abandoned_at":    abandonedAt,
+			"claimed_by":      nil,
+			"claimed_until":   nil,
+		}).Error; err != nil {
+			return err
+		}
+		userTask.StatusProgress = "abandoned"
+		userTask.AbandonedAt = &abandonedAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &userTask, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	admin "github.com/sawalreverr/recything/internal/admin/entity"
+	"github.com/sawalreverr/recything/internal/database/databasetest"
+	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
+	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
+	user_entity "github.com/sawalreverr/recything/internal/user"
+	"github.com/sawalreverr/recything/pkg"
+	"gorm.io/gorm/clause"
+)
+
+func TestCreateUserTaskConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	repository := NewUserTaskRepository(db)
+
+	const (
+		maxParticipants = 3
+		users           = 6
+	)
+
+	now := time.Now()
+	// ids are at most 20 characters
+	suffix := strconv.FormatInt(now.UnixNano(), 36)
+	adminId := "TAD" + suffix
+	challengeId := "TTC" + suffix
+
+	records := []interface{}{
+		&admin.Admin{ID: adminId, Name: "test admin", Role: "admin"},
+		&task.TaskChallenge{
+			ID:              challengeId,
+			Title:           "test challenge",
+			StartDate:       now,
+			EndDate:         now.AddDate(0, 0, 7),
+			Point:           10,
+			AdminId:         adminId,
+			Status:          true,
+			MaxParticipants: maxParticipants,
+		},
+	}
+	userIds := make([]string, users)
+	for i := range userIds {
+		userIds[i] = fmt.Sprintf("TUS%s%02d", suffix, i)
+		records = append(records, &user_entity.User{ID: userIds[i], Name: "test user", Gender: "-", BirthDate: now})
+	}
+	for _, record := range records {
+		if err := db.GetDB().Omit(clause.Associations).Create(record).Error; err != nil {
+			t.Fatalf("seed %T: %v", record, err)
+		}
+	}
+
+	// every user joins twice at the same time, and there are more users than
+	// places, so joins race on both the per-user limit and the cap
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	joined := 0
+	for i, userId := range userIds {
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(userTaskId, userId string) {
+				defer wg.Done()
+				_, err := repository.CreateUserTask(&user_task.UserTaskChallenge{
+					ID:              userTaskId,
+					UserId:          userId,
+					TaskChallengeId: challengeId,
+					StatusProgress:  "in_progress",
+					AcceptedAt:      now,
+				})
+				switch {
+				case err == nil:
+					mu.Lock()
+					joined++
+					mu.Unlock()
+				case errors.Is(err, pkg.ErrTaskFull), errors.Is(err, pkg.ErrUserTaskExist):
+				default:
+					t.Errorf("join: %v", err)
+				}
+			}(fmt.Sprintf("TUT%s%02d%d", suffix, i, j), userId)
+		}
+	}
+	wg.Wait()
+
+	if joined != maxParticipants {
+		t.Errorf("%d joins succeeded, want %d", joined, maxParticipants)
+	}
+
+	var rows []struct {
+		UserId string
+		Total  int
+	}
+	if err := db.GetDB().Model(&user_task.UserTaskChallenge{}).
+		Select("user_id, COUNT(*) AS total").
+		Where("task_challenge_id = ?", challengeId).
+		Group("user_id").
+		Scan(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	participants := 0
+	for _, row := range rows {
+		participants += row.Total
+		if row.Total > 1 {
+			t.Errorf("user %s joined %d times, want once", row.UserId, row.Total)
+		}
+	}
+	if participants > maxParticipants {
+		t.Errorf("challenge has %d participants, want at most %d", participants, maxParticipants)
+	}
+}
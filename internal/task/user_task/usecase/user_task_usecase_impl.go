@@ -2,32 +2,133 @@ package usecase
 
 import (
 	"errors"
+	"log"
 	"mime/multipart"
+	"strconv"
 	"time"
 
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/helper"
+	"github.com/sawalreverr/recything/internal/point"
+	"github.com/sawalreverr/recything/internal/streak"
 	task "github.com/sawalreverr/recything/internal/task/manage_task/entity"
 	"github.com/sawalreverr/recything/internal/task/user_task/dto"
 	user_task "github.com/sawalreverr/recything/internal/task/user_task/entity"
 	"github.com/sawalreverr/recything/internal/task/user_task/repository"
+	"github.com/sawalreverr/recything/internal/user"
 	"github.com/sawalreverr/recything/pkg"
 	"gorm.io/gorm"
 )
 
+// MaxStepImages is the number of photos a participant can attach to one step.
+const MaxStepImages = 3
+
+// MaxFinalImages is the number of photos a participant can attach to the
+// final submission of a challenge, however many steps it has.
+const MaxFinalImages = 10
+
 type UserTaskUsecaseImpl struct {
 	UserTaskRepository repository.UserTaskRepository
+	Streak             streak.Recorder
 }
 
-func NewUserTaskUsecase(repository repository.UserTaskRepository) UserTaskUsecase {
-	return &UserTaskUsecaseImpl{UserTaskRepository: repository}
+func NewUserTaskUsecase(repository repository.UserTaskRepository, recorder streak.Recorder) UserTaskUsecase {
+	return &UserTaskUsecaseImpl{UserTaskRepository: repository, Streak: recorder}
 }
 
-func (usecase *UserTaskUsecaseImpl) GetAllTasksUsecase() ([]task.TaskChallenge, error) {
+func (usecase *UserTaskUsecaseImpl) GetAllTasksUsecase(userId string) ([]task.TaskChallenge, map[string]dto.TaskEnrollment, error) {
 	userTask, err := usecase.UserTaskRepository.GetAllTasks()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := usecase.UserTaskRepository.FindUser(userId)
+	if err != nil {
+		return nil, nil, pkg.ErrUserNotFound
+	}
+	level, err := usecase.heldLevel(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taskIds := make([]string, 0, len(userTask))
+	for _, challenge := range userTask {
+		taskIds = append(taskIds, challenge.ID)
+	}
+	participants, err := usecase.UserTaskRepository.CountParticipants(taskIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	enrollments := make(map[string]dto.TaskEnrollment, len(userTask))
+	for i := range userTask {
+		challenge := &userTask[i]
+		enrollment := dto.TaskEnrollment{Eligible: true}
+
+		if challenge.MaxParticipants > 0 {
+			remaining := challenge.MaxParticipants - participants[challenge.ID]
+			if remaining < 0 {
+				remaining = 0
+			}
+			enrollment.RemainingSlots = &remaining
+		}
+
+		if err := checkEligibility(challenge, user, level, now); err != nil {
+			enrollment.Eligible = false
+			enrollment.Reason = err.Error()
+		} else if enrollment.RemainingSlots != nil && *enrollment.RemainingSlots == 0 {
+			enrollment.Eligible = false
+			enrollment.Reason = pkg.ErrTaskFull.Error()
+		}
+		enrollments[challenge.ID] = enrollment
+	}
+
+	return userTask, enrollments, nil
+}
+
+// checkEligibility applies the rules a challenge sets on who can join it. The
+// participant limit is enforced by the repository while the join is stored.
+func checkEligibility(challenge *task.TaskChallenge, user *user.User, level *achievement.Achievement, now time.Time) error {
+	switch challenge.Availability(now) {
+	case task.AvailabilityUpcoming:
+		return pkg.ErrTaskNotStarted
+	case task.AvailabilityEnded:
+		return pkg.ErrTaskCannotBeFollowed
+	}
+
+	if !challenge.EnrollmentOpen(now) {
+		return pkg.ErrTaskEnrollmentClosed
+	}
+	if !challenge.AllowsProvince(user.Province) {
+		return pkg.ErrTaskRegionRestricted
+	}
+	// levels rank by the points they take to reach, the user needs to hold the
+	// required level or a higher one
+	if challenge.MinAchievement != nil && (level == nil || level.TargetPoint < challenge.MinAchievement.TargetPoint) {
+		return pkg.ErrTaskAchievementRequired
+	}
+	return nil
+}
+
+// heldLevel returns the achievement level the user holds, nil when it was
+// removed. Users that were never given one hold the level their points reach,
+// if any.
+func (usecase *UserTaskUsecaseImpl) heldLevel(user *user.User) (*achievement.Achievement, error) {
+	var level *achievement.Achievement
+	var err error
+	if user.AchievementID == 0 {
+		level, err = usecase.UserTaskRepository.FindLevelReachedBy(user.ID)
+	} else {
+		level, err = usecase.UserTaskRepository.FindLevel(user.AchievementID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	return userTask, nil
+	return level, nil
 }
 
 func (usecase *UserTaskUsecaseImpl) GetTaskByIdUsecase(id string) (*task.TaskChallenge, error) {
@@ -46,20 +147,28 @@ func (usecase *UserTaskUsecaseImpl) CreateUserTaskUsecase(taskChallengeId string
 		return nil, pkg.ErrTaskNotFound
 	}
 
-	if !findtask.Status {
-		return nil, pkg.ErrTaskCannotBeFollowed
+	user, errFindUser := usecase.UserTaskRepository.FindUser(userId)
+	if errFindUser != nil {
+		return nil, pkg.ErrUserNotFound
 	}
 
-	if _, err := usecase.UserTaskRepository.FindUserTask(userId, taskChallengeId); err == nil {
-		return nil, pkg.ErrUserTaskExist
+	level, err := usecase.heldLevel(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEligibility(findtask, user, level, time.Now()); err != nil {
+		return nil, err
 	}
 
 	if _, err := usecase.UserTaskRepository.FindUserHasSameTask(userId, taskChallengeId); err == nil {
 		return nil, pkg.ErrUserTaskExist
 	}
 
-	lastId, _ := usecase.UserTaskRepository.FindLastIdTaskChallenge()
-	id := helper.GenerateCustomID(lastId, "UT")
+	id, err := usecase.UserTaskRepository.NextIdUserTask()
+	if err != nil {
+		return nil, err
+	}
 	userTask := &user_task.UserTaskChallenge{
 		ID:              id,
 		UserId:          userId,
@@ -95,16 +204,28 @@ func (usecase *UserTaskUsecaseImpl) UploadImageTaskUsecase(request *dto.UploadIm
 	if !findTask.Status {
 		return nil, pkg.ErrTaskCannotBeFollowed
 	}
-	countImage := len(fileImage)
-	countTaskSteps := len(findTask.TaskSteps) * 3
-	if countImage > countTaskSteps {
+	if len(fileImage) > MaxFinalImages {
 		return nil, pkg.ErrImagesExceed
 	}
 
+	if findUserTask.StatusProgress == "abandoned" {
+		return nil, pkg.ErrUserTaskAbandoned
+	}
+
 	if findUserTask.StatusProgress == "done" {
 		return nil, pkg.ErrUserTaskDone
 	}
 
+	// after withdrawing and joining again the first submission is already a
+	// resubmission
+	attempts, err := usecase.UserTaskRepository.CountSubmissions(userId, findUserTask.TaskChallengeId)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > 0 && resubmissionsLeft(findTask, attempts) == 0 {
+		return nil, pkg.ErrResubmissionLimit
+	}
+
 	findUserSteps, errFindUserSteps := usecase.UserTaskRepository.FindUserSteps(userTaskId)
 
 	if errFindUserSteps != nil {
@@ -181,6 +302,10 @@ func (usecase *UserTaskUsecaseImpl) UpdateUserTaskUsecase(request *dto.UpdateUse
 		return nil, pkg.ErrUserTaskNotFound
 	}
 
+	if findUserTask.StatusProgress == "abandoned" {
+		return nil, pkg.ErrUserTaskAbandoned
+	}
+
 	if findUserTask.StatusAccept != "reject" {
 		return nil, pkg.ErrUserTaskNotReject
 	}
@@ -190,11 +315,16 @@ func (usecase *UserTaskUsecaseImpl) UpdateUserTaskUsecase(request *dto.UpdateUse
 	if errFindTask != nil {
 		return nil, pkg.ErrTaskNotFound
 	}
-	countImage := len(fileImage)
-	lenTaskSteps := len(findTask.TaskSteps)
-	countTaskSteps := lenTaskSteps * 3
 
-	if countImage > countTaskSteps {
+	attempts, err := usecase.UserTaskRepository.CountSubmissions(userId, findUserTask.TaskChallengeId)
+	if err != nil {
+		return nil, err
+	}
+	if resubmissionsLeft(findTask, attempts) == 0 {
+		return nil, pkg.ErrResubmissionLimit
+	}
+
+	if len(fileImage) > MaxFinalImages {
 		return nil, pkg.ErrImagesExceed
 	}
 
@@ -231,6 +361,35 @@ func (usecase *UserTaskUsecaseImpl) UpdateUserTaskUsecase(request *dto.UpdateUse
 	return userTask, nil
 }
 
+// resubmissionsLeft returns how many more times a rejected user task can be
+// sent again, the first submission is not counted as a resubmission.
+func resubmissionsLeft(challenge *task.TaskChallenge, attempts int) int {
+	used := attempts - 1
+	if used < 0 {
+		used = 0
+	}
+	if left := challenge.MaxResubmissions - used; left > 0 {
+		return left
+	}
+	return 0
+}
+
+func (usecase *UserTaskUsecaseImpl) GetSubmissionHistoryUsecase(userId string, userTaskId string) (*user_task.UserTaskChallenge, int, error) {
+	userTask, err := usecase.UserTaskRepository.GetSubmissionHistory(userId, userTaskId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, pkg.ErrUserTaskNotFound
+		}
+		return nil, 0, err
+	}
+
+	attempts, err := usecase.UserTaskRepository.CountSubmissions(userId, userTask.TaskChallengeId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return userTask, resubmissionsLeft(&userTask.TaskChallenge, attempts), nil
+}
+
 func (usecase *UserTaskUsecaseImpl) GetUserTaskDetailsUsecase(userTaskId string, userId string) (*user_task.UserTaskChallenge, []*user_task.UserTaskImage, error) {
 	userTask, imageTask, err := usecase.UserTaskRepository.GetUserTaskDetails(userTaskId, userId)
 	if err != nil {
@@ -239,29 +398,30 @@ func (usecase *UserTaskUsecaseImpl) GetUserTaskDetailsUsecase(userTaskId string,
 	return userTask, imageTask, nil
 }
 
-func (usecase *UserTaskUsecaseImpl) GetHistoryPointByUserIdUsecase(userId string) ([]user_task.UserTaskChallenge, int, error) {
+func (usecase *UserTaskUsecaseImpl) GetHistoryPointByUserIdUsecase(userId string) ([]point.Entry, int, error) {
 
-	userTask, err := usecase.UserTaskRepository.GetHistoryPointByUserId(userId)
+	entries, err := usecase.UserTaskRepository.GetHistoryPointByUserId(userId)
 	if err != nil {
 		return nil, 0, err
 	}
-	if len(userTask) == 0 {
+	if len(entries) == 0 {
 		return nil, 0, pkg.ErrUserNoHasTask
 	}
 
-	var totalPoint int
-	for _, task := range userTask {
-		totalPoint += task.Point
-	}
-	return userTask, totalPoint, nil
+	// entries are newest first, so the first balance is the current one
+	return entries, entries[0].BalanceAfter, nil
 }
 
-func (usecase *UserTaskUsecaseImpl) UpdateTaskStepUsecase(request *dto.UpdateTaskStepRequest, userId string) (*user_task.UserTaskChallenge, error) {
+func (usecase *UserTaskUsecaseImpl) UpdateTaskStepUsecase(request *dto.UpdateTaskStepRequest, fileImage []*multipart.FileHeader, userId string) (*user_task.UserTaskChallenge, error) {
 	userTask, errUserTask := usecase.UserTaskRepository.FindUserTask(userId, request.UserTaskId)
 	if errUserTask != nil {
 		return nil, pkg.ErrUserTaskNotFound
 	}
 
+	if userTask.StatusProgress == "abandoned" {
+		return nil, pkg.ErrUserTaskAbandoned
+	}
+
 	if userTask.StatusProgress != "in_progress" {
 		return nil, pkg.ErrUserTaskDone
 	}
@@ -287,28 +447,55 @@ func (usecase *UserTaskUsecaseImpl) UpdateTaskStepUsecase(request *dto.UpdateTas
 		return nil, pkg.ErrUserTaskStepAlreadyCompleted
 	}
 
-	// Find completed user task steps
-	completedSteps, err := usecase.UserTaskRepository.FindCompletedUserSteps(userTask.ID)
-	if err != nil {
-		return nil, err
+	// steps are completed in position order, the next step is the first one
+	// the participant has not completed yet
+	completedSteps := make(map[int]bool, len(userTask.UserTaskSteps))
+	for _, step := range userTask.UserTaskSteps {
+		if step.Completed {
+			completedSteps[step.TaskStepID] = true
+		}
 	}
-
-	if len(completedSteps) > 0 {
-		nextStepID := completedSteps[len(completedSteps)-1].TaskStepID + 1
-		if request.TaskStepId != nextStepID {
-			return nil, pkg.ErrStepNotInOrder
+	for _, step := range userTask.TaskChallenge.TaskSteps {
+		if completedSteps[step.ID] {
+			continue
 		}
-	} else {
-		firstStep := userTask.TaskChallenge.TaskSteps[0]
-		if request.TaskStepId != firstStep.ID {
+		if step.ID != request.TaskStepId {
 			return nil, pkg.ErrStepNotInOrder
 		}
+		break
+	}
+
+	if taskStep.RequiresPhoto && len(fileImage) == 0 {
+		return nil, pkg.ErrStepPhotoRequired
+	}
+	if len(fileImage) > MaxStepImages {
+		return nil, pkg.ErrImagesExceed
+	}
+
+	validImages, errImages := helper.ImagesValidation(fileImage)
+	if errImages != nil {
+		return nil, errImages
+	}
+
+	userTaskStep.Images = []user_task.UserTaskStepImage{}
+	for _, image := range validImages {
+		imageUrl, err := helper.UploadToCloudinary(image, "task_step_images+"+userTask.ID)
+		if err != nil {
+			return nil, pkg.ErrUploadCloudinary
+		}
+		userTaskStep.Images = append(userTaskStep.Images, user_task.UserTaskStepImage{
+			ImageUrl: imageUrl,
+		})
 	}
 
 	userTaskStep.Completed = true
+	userTaskStep.Note = request.Note
 	if err := usecase.UserTaskRepository.UpdateUserTaskStep(userTaskStep); err != nil {
 		return nil, err
 	}
+	if err := usecase.Streak.Record(userId, streak.ActionTaskStep, strconv.Itoa(userTaskStep.ID), time.Now()); err != nil {
+		log.Printf("record streak for user task step %d: %v", userTaskStep.ID, err)
+	}
 
 	updatedUserTask, err := usecase.UserTaskRepository.FindUserTask(userId, request.UserTaskId)
 	if err != nil {
@@ -342,3 +529,7 @@ func (usecase *UserTaskUsecaseImpl) GetUserTaskRejectedByUserId(userId string, u
 	}
 	return userTask, nil
 }
+
+func (usecase *UserTaskUsecaseImpl) WithdrawUserTaskUsecase(userId string, userTaskId string) (*user_task.UserTaskChallenge, error) {
+	return usecase.UserTaskRepository.WithdrawUserTask(userId, userTaskId)
+}
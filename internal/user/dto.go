@@ -1,6 +1,10 @@
 package user
 
-import "time"
+import (
+	"time"
+
+	"github.com/sawalreverr/recything/internal/streak"
+)
 
 type UserDetail struct {
 	Name  string `json:"name"`
@@ -10,6 +14,8 @@ type UserDetail struct {
 	BirthDate       string    `json:"birth_date"`
 	ParsedBirthDate time.Time `json:"-"`
 	Address         string    `json:"address"`
+	Province        string    `json:"province"`
+	City            string    `json:"city"`
 }
 
 type UserResponse struct {
@@ -22,8 +28,13 @@ type UserResponse struct {
 	Gender     string    `json:"gender"`
 	BirthDate  time.Time `json:"birth_date"`
 	Address    string    `json:"address"`
+	Province   string    `json:"province"`
+	City       string    `json:"city"`
 	PictureURL string    `json:"picture_url"`
 	CreatedAt  time.Time `json:"created_at"`
+
+	// Streak is only filled in when a single user is looked up.
+	Streak *streak.Summary `json:"streak,omitempty"`
 }
 
 type UserPaginationResponse struct {
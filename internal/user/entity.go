@@ -4,12 +4,13 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"gorm.io/gorm"
 )
 
 // struct
 type User struct {
-	ID    string `json:"id" gorm:"primaryKey"`
+	ID    string `json:"id" gorm:"primaryKey;type:varchar(20)"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
 	// PhoneNumber string    `json:"phone_number"`
@@ -18,10 +19,15 @@ type User struct {
 	Gender     string    `json:"gender" gorm:"type:enum('laki-laki', 'perempuan', '-');default:-"`
 	BirthDate  time.Time `json:"birth_date"`
 	Address    string    `json:"address"`
+	Province   string    `json:"province"`
+	City       string    `json:"city"`
 	PictureURL string    `json:"picture_url"`
 	OTP        uint      `json:"otp"`
 	IsVerified bool      `json:"is_verified" gorm:"default:false"`
 	Badge      string    `json:"badge" gorm:"default:'https://res.cloudinary.com/dymhvau8n/image/upload/v1718189121/user_badge/htaemsjtlhfof7ww01ss.png'"`
+	// AchievementID is the level the user currently holds, Badge is a copy of
+	// its user badge image.
+	AchievementID int `json:"achievement_id" gorm:"index;default:0"`
 
 	CreatedAt time.Time      `json:"-"`
 	UpdatedAt time.Time      `json:"-"`
@@ -34,8 +40,10 @@ type UserRepository interface {
 	FindByEmail(email string) (*User, error)
 	FindByPhoneNumber(phoneNumber string) (*User, error)
 	FindByID(userID string) (*User, error)
+	FindByIDs(userIDs []string) (*[]User, error)
 	FindAll(page int, limit int, sortBy string, sortType string) (*[]User, error)
-	FindLastID() (string, error)
+	NextID() (string, error)
+	FindEntryLevel() (*achievement.Achievement, error)
 	Update(user User) error
 	Delete(userID string) error
 	CountAllUser() (int, error)
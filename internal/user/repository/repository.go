@@ -3,6 +3,7 @@ package user
 import (
 	"fmt"
 
+	achievement "github.com/sawalreverr/recything/internal/achievements/manage_achievements/entity"
 	"github.com/sawalreverr/recything/internal/database"
 	u "github.com/sawalreverr/recything/internal/user"
 )
@@ -50,6 +51,19 @@ func (r *userRepository) FindByID(userID string) (*u.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) FindByIDs(userIDs []string) (*[]u.User, error) {
+	var users []u.User
+	if len(userIDs) == 0 {
+		return &users, nil
+	}
+
+	if err := r.DB.GetDB().Unscoped().Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return &users, nil
+}
+
 func (r *userRepository) FindAll(page int, limit int, sortBy string, sortType string) (*[]u.User, error) {
 	var users []u.User
 
@@ -68,13 +82,18 @@ func (r *userRepository) FindAll(page int, limit int, sortBy string, sortType st
 	return &users, nil
 }
 
-func (r *userRepository) FindLastID() (string, error) {
-	var user u.User
-	if err := r.DB.GetDB().Unscoped().Order("id DESC").First(&user).Error; err != nil {
-		return "USR0000", err
+func (r *userRepository) NextID() (string, error) {
+	return database.NextID(r.DB, "USR")
+}
+
+// FindEntryLevel returns the lowest level, the one new users start at.
+func (r *userRepository) FindEntryLevel() (*achievement.Achievement, error) {
+	var level achievement.Achievement
+	if err := r.DB.GetDB().Scopes(achievement.PointLevels).Order("target_point asc").First(&level).Error; err != nil {
+		return nil, err
 	}
 
-	return user.ID, nil
+	return &level, nil
 }
 
 func (r *userRepository) Update(user u.User) error {
@@ -1,16 +1,18 @@
 package user
 
 import (
+	"github.com/sawalreverr/recything/internal/streak"
 	u "github.com/sawalreverr/recything/internal/user"
 	"github.com/sawalreverr/recything/pkg"
 )
 
 type userUsecase struct {
 	userRepository u.UserRepository
+	streak         streak.Reader
 }
 
-func NewUserUsecase(userRepo u.UserRepository) u.UserUsecase {
-	return &userUsecase{userRepository: userRepo}
+func NewUserUsecase(userRepo u.UserRepository, reader streak.Reader) u.UserUsecase {
+	return &userUsecase{userRepository: userRepo, streak: reader}
 }
 
 func (uc *userUsecase) UpdateUserDetail(userID string, user u.UserDetail) error {
@@ -25,6 +27,8 @@ func (uc *userUsecase) UpdateUserDetail(userID string, user u.UserDetail) error
 	userFound.Gender = user.Gender
 	userFound.BirthDate = user.ParsedBirthDate
 	userFound.Address = user.Address
+	userFound.Province = user.Province
+	userFound.City = user.City
 
 	if err := uc.userRepository.Update(*userFound); err != nil {
 		return pkg.ErrStatusInternalError
@@ -63,10 +67,18 @@ func (uc *userUsecase) FindUserByID(userID string) (*u.UserResponse, error) {
 		Gender:     userFound.Gender,
 		BirthDate:  userFound.BirthDate,
 		Address:    userFound.Address,
+		Province:   userFound.Province,
+		City:       userFound.City,
 		PictureURL: userFound.PictureURL,
 		CreatedAt:  userFound.CreatedAt,
 	}
 
+	summary, err := uc.streak.Summary(userID)
+	if err != nil {
+		return nil, err
+	}
+	response.Streak = summary
+
 	return &response, nil
 }
 
@@ -93,6 +105,8 @@ func (uc *userUsecase) FindAllUser(page int, limit int, sortBy string, sortType
 			Gender:     user.Gender,
 			BirthDate:  user.BirthDate,
 			Address:    user.Address,
+			Province:   user.Province,
+			City:       user.City,
 			PictureURL: user.PictureURL,
 			CreatedAt:  user.CreatedAt,
 		}
@@ -40,7 +40,7 @@ type Comment struct {
 	ID        int       `gorm:"primaryKey"`
 	VideoID   int       `gorm:"index"`
 	Video     Video     `gorm:"foreignKey:VideoID"`
-	UserID    string    `gorm:"index"`
+	UserID    string    `gorm:"index;type:varchar(20)"`
 	User      user.User `gorm:"foreignKey:UserID"`
 	Comment   string
 	CreatedAt time.Time      `gorm:"autoCreateTime"`
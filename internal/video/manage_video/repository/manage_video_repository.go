@@ -6,6 +6,7 @@ import (
 )
 
 type ManageVideoRepository interface {
+	Transaction(fn func(repo ManageVideoRepository) error) error
 	CreateVideoAndCategories(video *video.Video) (*video.Video, error)
 	CreateVideoCategories(videoCategories []video.VideoCategory) error
 	FindTitleVideo(title string) error
@@ -1,8 +1,6 @@
 package repository
 
 import (
-	"log"
-
 	"github.com/sawalreverr/recything/internal/database"
 	video "github.com/sawalreverr/recything/internal/video/manage_video/entity"
 	"gorm.io/gorm"
@@ -18,11 +16,32 @@ func NewManageVideoRepository(db database.Database) *ManageVideoRepositoryImpl {
 	return &ManageVideoRepositoryImpl{DB: db}
 }
 
-func (repository *ManageVideoRepositoryImpl) CreateVideoAndCategories(video *video.Video) (*video.Video, error) {
-	if err := repository.DB.GetDB().Create(&video).Error; err != nil {
+func (repository *ManageVideoRepositoryImpl) Transaction(fn func(repo ManageVideoRepository) error) error {
+	return repository.DB.Transaction(func(tx database.Database) error {
+		return fn(&ManageVideoRepositoryImpl{DB: tx})
+	})
+}
+
+func (repository *ManageVideoRepositoryImpl) CreateVideoAndCategories(videos *video.Video) (*video.Video, error) {
+	err := repository.DB.Transaction(func(tx database.Database) error {
+		if err := tx.GetDB().Omit("Categories").Create(videos).Error; err != nil {
+			return err
+		}
+
+		if len(videos.Categories) == 0 {
+			return nil
+		}
+
+		for i := range videos.Categories {
+			videos.Categories[i].VideoID = videos.ID
+		}
+
+		return tx.GetDB().Omit("Video", "ContentCategory", "WasteCategory").Create(&videos.Categories).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return video, nil
+	return videos, nil
 }
 
 func (repository *ManageVideoRepositoryImpl) CreateVideoCategories(videoCategories []video.VideoCategory) error {
@@ -109,33 +128,16 @@ func (repository *ManageVideoRepositoryImpl) GetDetailsDataVideoById(id int) (*v
 }
 
 func (repository *ManageVideoRepositoryImpl) UpdateDataVideo(videos *video.Video, id int) error {
-	tx := repository.DB.GetDB().Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			log.Println("Transaction rollback due to panic:", r)
+	return repository.DB.Transaction(func(tx database.Database) error {
+		if len(videos.Categories) > 0 {
+			if err := tx.GetDB().Where("video_id = ?", id).Delete(&video.VideoCategory{}).Error; err != nil {
+				return err
+			}
 		}
-	}()
 
-	if len(videos.Categories) > 0 {
-		if err := tx.Where("video_id = ?", id).Delete(&video.VideoCategory{}).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
-	}
-
-	// Update video details along with associations
-	if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&videos).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	return nil
+		// Update video details along with associations
+		return tx.GetDB().Session(&gorm.Session{FullSaveAssociations: true}).Save(videos).Error
+	})
 }
 
 func (repository *ManageVideoRepositoryImpl) DeleteDataVideo(id int) error {
@@ -94,7 +94,14 @@ func (usecase *ManageVideoUsecaseImpl) CreateDataVideoUseCase(request *dto.Creat
 		Categories:  videoCategories,
 	}
 
-	_, errVideo := usecase.manageVideoRepository.CreateVideoAndCategories(&videos)
+	errVideo := usecase.manageVideoRepository.Transaction(func(repo repository.ManageVideoRepository) error {
+		if err := repo.FindTitleVideo(request.Title); err == nil {
+			return pkg.ErrVideoTitleAlreadyExist
+		}
+
+		_, err := repo.CreateVideoAndCategories(&videos)
+		return err
+	})
 	if errVideo != nil {
 		return errVideo
 	}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SubscriptionInput struct {
+	Name     string   `json:"name" validate:"required,max=100"`
+	URL      string   `json:"url" validate:"required,url"`
+	Secret   string   `json:"secret" validate:"omitempty,min=16"`
+	Events   []string `json:"events" validate:"required,min=1,dive,oneof='report.approved' 'report.resolved' 'task.approved'"`
+	Province string   `json:"province"`
+	City     string   `json:"city"`
+	IsActive *bool    `json:"is_active"`
+}
+
+// SubscriptionResponse carries the secret only when the subscription is
+// created, it is never shown again afterwards.
+type SubscriptionResponse struct {
+	ID        uint     `json:"id"`
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"secret,omitempty"`
+	Events    []string `json:"events"`
+	Province  string   `json:"province"`
+	City      string   `json:"city"`
+	IsActive  bool     `json:"is_active"`
+	CreatedBy string   `json:"created_by"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DeliveryResponse struct {
+	ID             string          `json:"id"`
+	SubscriptionID uint            `json:"subscription_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code"`
+	LastError      string          `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	ReplayOf       *string         `json:"replay_of"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type DeliveryPaginationResponse struct {
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	Deliveries []DeliveryResponse `json:"deliveries"`
+}
+
+// Envelope is the JSON body posted to subscribers.
+type Envelope struct {
+	ID         string      `json:"id"`
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// events
+const (
+	EventReportApproved = "report.approved"
+	EventReportResolved = "report.resolved"
+	EventTaskApproved   = "task.approved"
+)
+
+// delivery status
+const (
+	DeliveryPending = "pending"
+	DeliverySuccess = "success"
+	DeliveryFailed  = "failed"
+)
+
+// struct
+type Subscription struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Secret    string `json:"-"`
+	Events    string `json:"events"`
+	Province  string `json:"province"`
+	City      string `json:"city"`
+	IsActive  bool   `json:"is_active" gorm:"default:true"`
+	CreatedBy string `json:"created_by"`
+
+	CreatedAt time.Time      `json:"-"`
+	UpdatedAt time.Time      `json:"-"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Delivery is a single event sent to a single subscription. Pending rows act
+// as the retry queue and every row stays behind as the delivery log.
+type Delivery struct {
+	ID             uuid.UUID    `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint         `json:"subscription_id" gorm:"index"`
+	Subscription   Subscription `json:"-"`
+	Event          string       `json:"event"`
+	Payload        string       `json:"payload" gorm:"type:text"`
+	Status         string       `json:"status" gorm:"type:enum('pending', 'success', 'failed');default:'pending';index"`
+	Attempts       int          `json:"attempts" gorm:"default:0"`
+	NextAttemptAt  time.Time    `json:"next_attempt_at" gorm:"index"`
+	LastStatusCode int          `json:"last_status_code"`
+	LastError      string       `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time   `json:"delivered_at"`
+	ReplayOf       *uuid.UUID   `json:"replay_of"`
+
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Region is where an event happened. Subscriptions with a province or city
+// filter only receive events from a matching region.
+type Region struct {
+	Province string
+	City     string
+}
+
+// interface
+type Publisher interface {
+	Publish(event string, region Region, data interface{}) error
+}
+
+type Sender interface {
+	Send(url string, headers map[string]string, body []byte) (int, error)
+}
+
+type WebhookRepository interface {
+	CreateSubscription(subscription Subscription) (*Subscription, error)
+	FindSubscriptionByID(subscriptionID uint) (*Subscription, error)
+	FindAllSubscriptions() (*[]Subscription, error)
+	FindActiveSubscriptionsByEvent(event string) (*[]Subscription, error)
+	UpdateSubscription(subscription Subscription) error
+	DeleteSubscription(subscriptionID uint) error
+
+	CreateDelivery(delivery Delivery) (*Delivery, error)
+	FindDeliveryByID(deliveryID string) (*Delivery, error)
+	FindDeliveries(subscriptionID uint, status string, page, limit int) (*[]Delivery, int64, error)
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) (*[]Delivery, error)
+	RenewDeliveryLease(deliveryID string, claimedUntil, until time.Time) (bool, error)
+	UpdateDelivery(delivery Delivery) error
+}
+
+type WebhookUsecase interface {
+	Publisher
+
+	NewSubscription(input SubscriptionInput, adminID string) (*SubscriptionResponse, error)
+	GetSubscriptionByID(subscriptionID uint) (*SubscriptionResponse, error)
+	GetAllSubscriptions() (*[]SubscriptionResponse, error)
+	UpdateSubscription(subscriptionID uint, input SubscriptionInput) error
+	DeleteSubscription(subscriptionID uint) error
+
+	GetDeliveries(subscriptionID uint, status string, page, limit int) (*DeliveryPaginationResponse, error)
+	ReplayDelivery(deliveryID string) (*DeliveryResponse, error)
+	ProcessDueDeliveries(limit int) (int, error)
+}
+
+type WebhookHandler interface {
+	NewSubscription(c echo.Context) error
+	GetSubscriptionByID(c echo.Context) error
+	GetAllSubscriptions(c echo.Context) error
+	UpdateSubscription(c echo.Context) error
+	DeleteSubscription(c echo.Context) error
+
+	GetDeliveries(c echo.Context) error
+	ReplayDelivery(c echo.Context) error
+}
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sawalreverr/recything/internal/helper"
+	whk "github.com/sawalreverr/recything/internal/webhook"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+type webhookHandler struct {
+	webhookUsecase whk.WebhookUsecase
+}
+
+func NewWebhookHandler(uc whk.WebhookUsecase) whk.WebhookHandler {
+	return &webhookHandler{webhookUsecase: uc}
+}
+
+func (h *webhookHandler) NewSubscription(c echo.Context) error {
+	var request whk.SubscriptionInput
+
+	adminID := c.Get("user").(*helper.JwtCustomClaims).UserID
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	newSubscription, err := h.webhookUsecase.NewSubscription(request, adminID)
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusCreated, "webhook created!", newSubscription)
+}
+
+func (h *webhookHandler) GetSubscriptionByID(c echo.Context) error {
+	subscriptionID, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrWebhookNotFound.Error())
+	}
+
+	subscription, err := h.webhookUsecase.GetSubscriptionByID(uint(subscriptionID))
+	if err != nil {
+		if errors.Is(err, pkg.ErrWebhookNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", subscription)
+}
+
+func (h *webhookHandler) GetAllSubscriptions(c echo.Context) error {
+	subscriptions, err := h.webhookUsecase.GetAllSubscriptions()
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", subscriptions)
+}
+
+func (h *webhookHandler) UpdateSubscription(c echo.Context) error {
+	var request whk.SubscriptionInput
+
+	subscriptionID, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrWebhookNotFound.Error())
+	}
+
+	if err := c.Bind(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.webhookUsecase.UpdateSubscription(uint(subscriptionID), request); err != nil {
+		if errors.Is(err, pkg.ErrWebhookNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "webhook updated!", nil)
+}
+
+func (h *webhookHandler) DeleteSubscription(c echo.Context) error {
+	subscriptionID, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrWebhookNotFound.Error())
+	}
+
+	if err := h.webhookUsecase.DeleteSubscription(uint(subscriptionID)); err != nil {
+		if errors.Is(err, pkg.ErrWebhookNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "webhook deleted!", nil)
+}
+
+func (h *webhookHandler) GetDeliveries(c echo.Context) error {
+	subscriptionID, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		return helper.ErrorHandler(c, http.StatusBadRequest, pkg.ErrWebhookNotFound.Error())
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+	status := c.QueryParam("status")
+
+	deliveries, err := h.webhookUsecase.GetDeliveries(uint(subscriptionID), status, page, limit)
+	if err != nil {
+		if errors.Is(err, pkg.ErrWebhookNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusOK, "ok", deliveries)
+}
+
+func (h *webhookHandler) ReplayDelivery(c echo.Context) error {
+	deliveryID := c.Param("deliveryId")
+
+	replay, err := h.webhookUsecase.ReplayDelivery(deliveryID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrWebhookDeliveryNotFound) || errors.Is(err, pkg.ErrWebhookNotFound) {
+			return helper.ErrorHandler(c, http.StatusNotFound, err.Error())
+		}
+
+		return helper.ErrorHandler(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.ResponseHandler(c, http.StatusCreated, "webhook delivery queued!", replay)
+}
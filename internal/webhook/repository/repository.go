@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/sawalreverr/recything/internal/database"
+	whk "github.com/sawalreverr/recything/internal/webhook"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	DB database.Database
+}
+
+func NewWebhookRepository(db database.Database) whk.WebhookRepository {
+	return &webhookRepository{DB: db}
+}
+
+// Subscription
+func (r *webhookRepository) CreateSubscription(subscription whk.Subscription) (*whk.Subscription, error) {
+	if err := r.DB.GetDB().Create(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *webhookRepository) FindSubscriptionByID(subscriptionID uint) (*whk.Subscription, error) {
+	var subscription whk.Subscription
+	if err := r.DB.GetDB().Where("id = ?", subscriptionID).First(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *webhookRepository) FindAllSubscriptions() (*[]whk.Subscription, error) {
+	var subscriptions []whk.Subscription
+	if err := r.DB.GetDB().Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	return &subscriptions, nil
+}
+
+func (r *webhookRepository) FindActiveSubscriptionsByEvent(event string) (*[]whk.Subscription, error) {
+	var subscriptions []whk.Subscription
+	if err := r.DB.GetDB().Where("is_active = ? AND FIND_IN_SET(?, events) > 0", true, event).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	return &subscriptions, nil
+}
+
+func (r *webhookRepository) UpdateSubscription(subscription whk.Subscription) error {
+	if err := r.DB.GetDB().Save(&subscription).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(subscriptionID uint) error {
+	if err := r.DB.GetDB().Where("id = ?", subscriptionID).Delete(&whk.Subscription{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delivery
+func (r *webhookRepository) CreateDelivery(delivery whk.Delivery) (*whk.Delivery, error) {
+	if err := r.DB.GetDB().Omit("Subscription").Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookRepository) FindDeliveryByID(deliveryID string) (*whk.Delivery, error) {
+	var delivery whk.Delivery
+	if err := r.DB.GetDB().Where("id = ?", deliveryID).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookRepository) FindDeliveries(subscriptionID uint, status string, page, limit int) (*[]whk.Delivery, int64, error) {
+	var deliveries []whk.Delivery
+	var total int64
+
+	db := r.DB.GetDB().Model(&whk.Delivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &deliveries, total, nil
+}
+
+// ClaimDueDeliveries picks pending deliveries that are due and pushes their
+// next attempt back by lease, so another worker does not send them while this
+// one is still waiting on the subscriber.
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) (*[]whk.Delivery, error) {
+	var deliveries []whk.Delivery
+	var ids []string
+
+	err := r.DB.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", whk.DeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID.String())
+		}
+
+		return tx.Model(&whk.Delivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []whk.Delivery
+	if len(ids) == 0 {
+		return &claimed, nil
+	}
+
+	if err := r.DB.GetDB().Preload("Subscription", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("id IN ?", ids).Order("next_attempt_at").Find(&claimed).Error; err != nil {
+		return nil, err
+	}
+
+	return &claimed, nil
+}
+
+// RenewDeliveryLease pushes the next attempt of a claimed delivery to until.
+// It reports false when the delivery is no longer pending or its claim, known
+// by claimedUntil, was replaced by another worker.
+func (r *webhookRepository) RenewDeliveryLease(deliveryID string, claimedUntil, until time.Time) (bool, error) {
+	result := r.DB.GetDB().Model(&whk.Delivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", deliveryID, whk.DeliveryPending, claimedUntil).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery whk.Delivery) error {
+	if err := r.DB.GetDB().Omit("Subscription").Save(&delivery).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
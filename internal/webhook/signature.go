@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// headers sent with every delivery
+const (
+	HeaderEvent     = "X-Recything-Event"
+	HeaderDelivery  = "X-Recything-Delivery"
+	HeaderTimestamp = "X-Recything-Timestamp"
+	HeaderSignature = "X-Recything-Signature"
+)
+
+// SendTimeout bounds how long a subscriber may take to answer a delivery.
+const SendTimeout = 10 * time.Second
+
+// MaxDeliveryAttempts is the number of tries before a delivery is marked failed.
+const MaxDeliveryAttempts = 8
+
+const (
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 6 * time.Hour
+)
+
+// Sign returns the signature partners use to verify a delivery: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret,
+// prefixed with "sha256=".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDelay is the wait before the next try after attempts failed tries. It
+// doubles each time, starting at 30 seconds and capped at 6 hours.
+func RetryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+
+	return delay
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration) Sender {
+	return &httpSender{client: &http.Client{Timeout: timeout}}
+}
+
+func (s *httpSender) Send(url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
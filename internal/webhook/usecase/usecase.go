@@ -0,0 +1,378 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	whk "github.com/sawalreverr/recything/internal/webhook"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+// sendLease is how long a delivery stays hidden from other workers once it is
+// about to be sent, enough for the subscriber to time out and the outcome to
+// be saved.
+const sendLease = whk.SendTimeout + 30*time.Second
+
+// claimLease hides a claimed batch from other workers until every delivery in
+// it could have been sent one after another.
+func claimLease(limit int) time.Duration {
+	return time.Duration(limit)*whk.SendTimeout + time.Minute
+}
+
+type webhookUsecase struct {
+	webhookRepository whk.WebhookRepository
+	sender            whk.Sender
+}
+
+func NewWebhookUsecase(repo whk.WebhookRepository, sender whk.Sender) whk.WebhookUsecase {
+	return &webhookUsecase{webhookRepository: repo, sender: sender}
+}
+
+// Subscription
+func (uc *webhookUsecase) NewSubscription(input whk.SubscriptionInput, adminID string) (*whk.SubscriptionResponse, error) {
+	secret := input.Secret
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, pkg.ErrStatusInternalError
+		}
+		secret = generated
+	}
+
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	newSubscription := whk.Subscription{
+		Name:      input.Name,
+		URL:       input.URL,
+		Secret:    secret,
+		Events:    strings.Join(input.Events, ","),
+		Province:  input.Province,
+		City:      input.City,
+		IsActive:  isActive,
+		CreatedBy: adminID,
+	}
+
+	created, err := uc.webhookRepository.CreateSubscription(newSubscription)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := subscriptionResponse(*created)
+	response.Secret = created.Secret
+	return &response, nil
+}
+
+func (uc *webhookUsecase) GetSubscriptionByID(subscriptionID uint) (*whk.SubscriptionResponse, error) {
+	subscription, err := uc.webhookRepository.FindSubscriptionByID(subscriptionID)
+	if err != nil {
+		return nil, pkg.ErrWebhookNotFound
+	}
+
+	response := subscriptionResponse(*subscription)
+	return &response, nil
+}
+
+func (uc *webhookUsecase) GetAllSubscriptions() (*[]whk.SubscriptionResponse, error) {
+	subscriptions, err := uc.webhookRepository.FindAllSubscriptions()
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	responses := make([]whk.SubscriptionResponse, len(*subscriptions))
+	for i, subscription := range *subscriptions {
+		responses[i] = subscriptionResponse(subscription)
+	}
+
+	return &responses, nil
+}
+
+func (uc *webhookUsecase) UpdateSubscription(subscriptionID uint, input whk.SubscriptionInput) error {
+	subscription, err := uc.webhookRepository.FindSubscriptionByID(subscriptionID)
+	if err != nil {
+		return pkg.ErrWebhookNotFound
+	}
+
+	subscription.Name = input.Name
+	subscription.URL = input.URL
+	subscription.Events = strings.Join(input.Events, ",")
+	subscription.Province = input.Province
+	subscription.City = input.City
+
+	if input.Secret != "" {
+		subscription.Secret = input.Secret
+	}
+	if input.IsActive != nil {
+		subscription.IsActive = *input.IsActive
+	}
+
+	if err := uc.webhookRepository.UpdateSubscription(*subscription); err != nil {
+		return pkg.ErrStatusInternalError
+	}
+
+	return nil
+}
+
+func (uc *webhookUsecase) DeleteSubscription(subscriptionID uint) error {
+	subscription, err := uc.webhookRepository.FindSubscriptionByID(subscriptionID)
+	if err != nil {
+		return pkg.ErrWebhookNotFound
+	}
+
+	if err := uc.webhookRepository.DeleteSubscription(subscription.ID); err != nil {
+		return pkg.ErrStatusInternalError
+	}
+
+	return nil
+}
+
+// Publish queues a delivery of event for every active subscription whose
+// event and region filters match. Sending happens in ProcessDueDeliveries.
+func (uc *webhookUsecase) Publish(event string, region whk.Region, data interface{}) error {
+	subscriptions, err := uc.webhookRepository.FindActiveSubscriptionsByEvent(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, subscription := range *subscriptions {
+		if !regionMatches(subscription, region) {
+			continue
+		}
+
+		deliveryID := uuid.New()
+		payload, err := json.Marshal(whk.Envelope{
+			ID:         deliveryID.String(),
+			Event:      event,
+			OccurredAt: now,
+			Data:       data,
+		})
+		if err != nil {
+			return err
+		}
+
+		delivery := whk.Delivery{
+			ID:             deliveryID,
+			SubscriptionID: subscription.ID,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         whk.DeliveryPending,
+			NextAttemptAt:  now,
+		}
+
+		if _, err := uc.webhookRepository.CreateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delivery
+func (uc *webhookUsecase) GetDeliveries(subscriptionID uint, status string, page, limit int) (*whk.DeliveryPaginationResponse, error) {
+	if _, err := uc.webhookRepository.FindSubscriptionByID(subscriptionID); err != nil {
+		return nil, pkg.ErrWebhookNotFound
+	}
+
+	deliveries, total, err := uc.webhookRepository.FindDeliveries(subscriptionID, status, page, limit)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	responses := make([]whk.DeliveryResponse, len(*deliveries))
+	for i, delivery := range *deliveries {
+		responses[i] = deliveryResponse(delivery)
+	}
+
+	return &whk.DeliveryPaginationResponse{
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		Deliveries: responses,
+	}, nil
+}
+
+// ReplayDelivery queues the payload of an earlier delivery again as a new
+// delivery, leaving the original entry in the log untouched.
+func (uc *webhookUsecase) ReplayDelivery(deliveryID string) (*whk.DeliveryResponse, error) {
+	original, err := uc.webhookRepository.FindDeliveryByID(deliveryID)
+	if err != nil {
+		return nil, pkg.ErrWebhookDeliveryNotFound
+	}
+
+	if _, err := uc.webhookRepository.FindSubscriptionByID(original.SubscriptionID); err != nil {
+		return nil, pkg.ErrWebhookNotFound
+	}
+
+	replay := whk.Delivery{
+		ID:             uuid.New(),
+		SubscriptionID: original.SubscriptionID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         whk.DeliveryPending,
+		NextAttemptAt:  time.Now(),
+		ReplayOf:       &original.ID,
+	}
+
+	created, err := uc.webhookRepository.CreateDelivery(replay)
+	if err != nil {
+		return nil, pkg.ErrStatusInternalError
+	}
+
+	response := deliveryResponse(*created)
+	return &response, nil
+}
+
+// ProcessDueDeliveries sends up to limit due deliveries and schedules a retry
+// with exponential backoff for each one that fails. It returns how many were
+// attempted.
+func (uc *webhookUsecase) ProcessDueDeliveries(limit int) (int, error) {
+	deliveries, err := uc.webhookRepository.ClaimDueDeliveries(time.Now(), claimLease(limit), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range *deliveries {
+		delivery := &(*deliveries)[i]
+
+		// renew the claim right before sending, a delivery whose claim was
+		// taken over by another worker is left to that worker
+		until := time.Now().Add(sendLease)
+		renewed, err := uc.webhookRepository.RenewDeliveryLease(delivery.ID.String(), delivery.NextAttemptAt, until)
+		if err != nil {
+			return attempted, err
+		}
+		if !renewed {
+			continue
+		}
+		delivery.NextAttemptAt = until
+
+		uc.attempt(delivery, time.Now())
+		attempted++
+
+		if err := uc.webhookRepository.UpdateDelivery(*delivery); err != nil {
+			return attempted, err
+		}
+	}
+
+	return attempted, nil
+}
+
+// attempt sends delivery once and records the outcome on it.
+func (uc *webhookUsecase) attempt(delivery *whk.Delivery, now time.Time) {
+	delivery.Attempts++
+
+	subscription := delivery.Subscription
+	if subscription.ID == 0 || subscription.DeletedAt.Valid || !subscription.IsActive {
+		delivery.Status = whk.DeliveryFailed
+		delivery.LastError = "subscription is inactive or deleted"
+		return
+	}
+
+	timestamp := now.Unix()
+	body := []byte(delivery.Payload)
+	headers := map[string]string{
+		whk.HeaderEvent:     delivery.Event,
+		whk.HeaderDelivery:  delivery.ID.String(),
+		whk.HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		whk.HeaderSignature: whk.Sign(subscription.Secret, timestamp, body),
+	}
+
+	statusCode, err := uc.sender.Send(subscription.URL, headers, body)
+	delivery.LastStatusCode = statusCode
+
+	if err == nil && statusCode >= 200 && statusCode < 300 {
+		delivery.Status = whk.DeliverySuccess
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	if err != nil {
+		delivery.LastError = err.Error()
+	} else {
+		delivery.LastError = fmt.Sprintf("unexpected status code %d", statusCode)
+	}
+
+	if delivery.Attempts >= whk.MaxDeliveryAttempts {
+		delivery.Status = whk.DeliveryFailed
+		return
+	}
+
+	delivery.NextAttemptAt = now.Add(whk.RetryDelay(delivery.Attempts))
+}
+
+func regionMatches(subscription whk.Subscription, region whk.Region) bool {
+	if subscription.Province != "" && !strings.EqualFold(subscription.Province, region.Province) {
+		return false
+	}
+	if subscription.City != "" && !strings.EqualFold(subscription.City, region.City) {
+		return false
+	}
+
+	return true
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+func subscriptionResponse(subscription whk.Subscription) whk.SubscriptionResponse {
+	var events []string
+	if subscription.Events != "" {
+		events = strings.Split(subscription.Events, ",")
+	}
+
+	return whk.SubscriptionResponse{
+		ID:        subscription.ID,
+		Name:      subscription.Name,
+		URL:       subscription.URL,
+		Events:    events,
+		Province:  subscription.Province,
+		City:      subscription.City,
+		IsActive:  subscription.IsActive,
+		CreatedBy: subscription.CreatedBy,
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+}
+
+func deliveryResponse(delivery whk.Delivery) whk.DeliveryResponse {
+	response := whk.DeliveryResponse{
+		ID:             delivery.ID.String(),
+		SubscriptionID: delivery.SubscriptionID,
+		Event:          delivery.Event,
+		Payload:        json.RawMessage(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+
+	if delivery.Status == whk.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.ReplayOf != nil {
+		replayOf := delivery.ReplayOf.String()
+		response.ReplayOf = &replayOf
+	}
+
+	return response
+}
@@ -0,0 +1,385 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	whk "github.com/sawalreverr/recything/internal/webhook"
+	"github.com/sawalreverr/recything/pkg"
+)
+
+// fakeRepository keeps subscriptions and deliveries in memory. Only the
+// methods used by publishing and sending are implemented.
+type fakeRepository struct {
+	whk.WebhookRepository
+
+	mu            sync.Mutex
+	subscriptions []whk.Subscription
+	deliveries    map[uuid.UUID]whk.Delivery
+}
+
+func newFakeRepository(subscriptions ...whk.Subscription) *fakeRepository {
+	return &fakeRepository{subscriptions: subscriptions, deliveries: map[uuid.UUID]whk.Delivery{}}
+}
+
+func (r *fakeRepository) FindActiveSubscriptionsByEvent(event string) (*[]whk.Subscription, error) {
+	var found []whk.Subscription
+	for _, subscription := range r.subscriptions {
+		if subscription.IsActive && subscription.Events == event {
+			found = append(found, subscription)
+		}
+	}
+	return &found, nil
+}
+
+func (r *fakeRepository) CreateDelivery(delivery whk.Delivery) (*whk.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.ID] = delivery
+	return &delivery, nil
+}
+
+func (r *fakeRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) (*[]whk.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []whk.Delivery
+	for id, delivery := range r.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if delivery.Status != whk.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		delivery.NextAttemptAt = now.Add(lease)
+		r.deliveries[id] = delivery
+
+		for _, subscription := range r.subscriptions {
+			if subscription.ID == delivery.SubscriptionID {
+				delivery.Subscription = subscription
+			}
+		}
+		claimed = append(claimed, delivery)
+	}
+	return &claimed, nil
+}
+
+func (r *fakeRepository) RenewDeliveryLease(deliveryID string, claimedUntil, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, ok := r.deliveries[uuid.MustParse(deliveryID)]
+	if !ok || delivery.Status != whk.DeliveryPending || !delivery.NextAttemptAt.Equal(claimedUntil) {
+		return false, nil
+	}
+
+	delivery.NextAttemptAt = until
+	r.deliveries[delivery.ID] = delivery
+	return true, nil
+}
+
+func (r *fakeRepository) UpdateDelivery(delivery whk.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.Subscription = whk.Subscription{}
+	r.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+// only returns the single delivery queued by a test
+func (r *fakeRepository) only(t *testing.T) whk.Delivery {
+	t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(r.deliveries))
+	}
+	for _, delivery := range r.deliveries {
+		return delivery
+	}
+	return whk.Delivery{}
+}
+
+// makeDue moves the next attempt of every pending delivery to the past.
+func (r *fakeRepository) makeDue() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, delivery := range r.deliveries {
+		delivery.NextAttemptAt = time.Now().Add(-time.Second)
+		r.deliveries[id] = delivery
+	}
+}
+
+// receiver is a subscriber answering with the queued status codes, 200 once
+// they run out. It counts the requests it got.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	recv := &receiver{statuses: statuses}
+	recv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		recv.mu.Lock()
+		recv.requests = append(recv.requests, r)
+		recv.bodies = append(recv.bodies, body)
+		status := http.StatusOK
+		if len(recv.statuses) > 0 {
+			status, recv.statuses = recv.statuses[0], recv.statuses[1:]
+		}
+		recv.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(recv.Close)
+	return recv
+}
+
+func (recv *receiver) count() int {
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+
+	return len(recv.requests)
+}
+
+func setup(t *testing.T, statuses ...int) (*fakeRepository, *receiver, whk.WebhookUsecase) {
+	recv := newReceiver(t, statuses...)
+	repo := newFakeRepository(whk.Subscription{
+		ID:       1,
+		URL:      recv.URL,
+		Secret:   "secret",
+		Events:   whk.EventTaskApproved,
+		IsActive: true,
+	})
+	uc := NewWebhookUsecase(repo, whk.NewHTTPSender(whk.SendTimeout))
+
+	if err := uc.Publish(whk.EventTaskApproved, whk.Region{}, map[string]string{"user_id": "USR0001"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	return repo, recv, uc
+}
+
+func process(t *testing.T, uc whk.WebhookUsecase, want int) {
+	t.Helper()
+
+	attempted, err := uc.ProcessDueDeliveries(50)
+	if err != nil {
+		t.Fatalf("process deliveries: %v", err)
+	}
+	if attempted != want {
+		t.Fatalf("attempted %d deliveries, want %d", attempted, want)
+	}
+}
+
+func TestProcessDueDeliveriesSendsSignedPayload(t *testing.T) {
+	repo, recv, uc := setup(t)
+
+	process(t, uc, 1)
+
+	delivery := repo.only(t)
+	if delivery.Status != whk.DeliverySuccess || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Fatalf("delivery is %s after %d attempts, want success after 1", delivery.Status, delivery.Attempts)
+	}
+	if recv.count() != 1 {
+		t.Fatalf("receiver got %d requests, want 1", recv.count())
+	}
+
+	request, body := recv.requests[0], recv.bodies[0]
+	if string(body) != delivery.Payload {
+		t.Errorf("receiver got body %s, want %s", body, delivery.Payload)
+	}
+	if got := request.Header.Get(whk.HeaderEvent); got != whk.EventTaskApproved {
+		t.Errorf("event header is %q", got)
+	}
+	if got := request.Header.Get(whk.HeaderDelivery); got != delivery.ID.String() {
+		t.Errorf("delivery header is %q, want %q", got, delivery.ID)
+	}
+	timestamp, err := strconv.ParseInt(request.Header.Get(whk.HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if got, want := request.Header.Get(whk.HeaderSignature), whk.Sign("secret", timestamp, body); got != want {
+		t.Errorf("signature header is %q, want %q", got, want)
+	}
+
+	// a delivered event is not sent again
+	process(t, uc, 0)
+	if recv.count() != 1 {
+		t.Errorf("receiver got %d requests after a second run, want 1", recv.count())
+	}
+}
+
+func TestProcessDueDeliveriesRetriesWithBackoff(t *testing.T) {
+	repo, recv, uc := setup(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+	before := time.Now()
+	process(t, uc, 1)
+
+	delivery := repo.only(t)
+	if delivery.Status != whk.DeliveryPending || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("delivery is %s after %d attempts with status %d, want pending after 1 with 500",
+			delivery.Status, delivery.Attempts, delivery.LastStatusCode)
+	}
+	if delay := delivery.NextAttemptAt.Sub(before); delay < whk.RetryDelay(1) || delay > whk.RetryDelay(1)+time.Minute {
+		t.Errorf("next attempt in %s, want about %s", delay, whk.RetryDelay(1))
+	}
+
+	// not due yet, nothing is sent
+	process(t, uc, 0)
+
+	repo.makeDue()
+	process(t, uc, 1)
+	if delivery := repo.only(t); delivery.Status != whk.DeliveryPending || delivery.Attempts != 2 {
+		t.Fatalf("delivery is %s after %d attempts, want pending after 2", delivery.Status, delivery.Attempts)
+	}
+
+	repo.makeDue()
+	process(t, uc, 1)
+	delivery = repo.only(t)
+	if delivery.Status != whk.DeliverySuccess || delivery.Attempts != 3 || delivery.LastError != "" {
+		t.Fatalf("delivery is %s after %d attempts (%q), want success after 3", delivery.Status, delivery.Attempts, delivery.LastError)
+	}
+	if recv.count() != 3 {
+		t.Errorf("receiver got %d requests, want 3", recv.count())
+	}
+}
+
+func TestProcessDueDeliveriesGivesUp(t *testing.T) {
+	statuses := make([]int, whk.MaxDeliveryAttempts)
+	for i := range statuses {
+		statuses[i] = http.StatusServiceUnavailable
+	}
+	repo, recv, uc := setup(t, statuses...)
+
+	for i := 0; i < whk.MaxDeliveryAttempts; i++ {
+		repo.makeDue()
+		process(t, uc, 1)
+	}
+
+	delivery := repo.only(t)
+	if delivery.Status != whk.DeliveryFailed || delivery.Attempts != whk.MaxDeliveryAttempts {
+		t.Fatalf("delivery is %s after %d attempts, want failed after %d", delivery.Status, delivery.Attempts, whk.MaxDeliveryAttempts)
+	}
+
+	repo.makeDue()
+	process(t, uc, 0)
+	if recv.count() != whk.MaxDeliveryAttempts {
+		t.Errorf("receiver got %d requests, want %d", recv.count(), whk.MaxDeliveryAttempts)
+	}
+}
+
+// stealingRepository hands the claimed batch to a second worker as soon as
+// the first one claimed it, as happens when the first claim expired.
+type stealingRepository struct {
+	*fakeRepository
+	stolen bool
+}
+
+func (r *stealingRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) (*[]whk.Delivery, error) {
+	claimed, err := r.fakeRepository.ClaimDueDeliveries(now, lease, limit)
+	if err != nil || r.stolen {
+		return claimed, err
+	}
+
+	r.stolen = true
+	r.makeDue()
+	if _, err := r.fakeRepository.ClaimDueDeliveries(time.Now(), lease, limit); err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func TestProcessDueDeliveriesSkipsDeliveriesClaimedByAnotherWorker(t *testing.T) {
+	repo, recv, _ := setup(t)
+	uc := NewWebhookUsecase(&stealingRepository{fakeRepository: repo}, whk.NewHTTPSender(whk.SendTimeout))
+
+	process(t, uc, 0)
+	if recv.count() != 0 {
+		t.Errorf("receiver got %d requests, want 0", recv.count())
+	}
+	if delivery := repo.only(t); delivery.Status != whk.DeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("delivery is %s after %d attempts, want untouched", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestClaimLeaseOutlastsBatch(t *testing.T) {
+	for _, limit := range []int{1, 50, 200} {
+		if lease := claimLease(limit); lease <= time.Duration(limit)*whk.SendTimeout {
+			t.Errorf("lease for %d deliveries is %s, shorter than sending them all", limit, lease)
+		}
+	}
+	if sendLease <= whk.SendTimeout {
+		t.Errorf("send lease %s does not outlast the send timeout %s", sendLease, whk.SendTimeout)
+	}
+}
+
+func (r *fakeRepository) CreateSubscription(subscription whk.Subscription) (*whk.Subscription, error) {
+	subscription.ID = uint(len(r.subscriptions) + 1)
+	r.subscriptions = append(r.subscriptions, subscription)
+	return &subscription, nil
+}
+
+func (r *fakeRepository) FindSubscriptionByID(subscriptionID uint) (*whk.Subscription, error) {
+	for _, subscription := range r.subscriptions {
+		if subscription.ID == subscriptionID {
+			return &subscription, nil
+		}
+	}
+	return nil, pkg.ErrWebhookNotFound
+}
+
+func (r *fakeRepository) FindAllSubscriptions() (*[]whk.Subscription, error) {
+	return &r.subscriptions, nil
+}
+
+func TestSubscriptionSecretOnlyOnCreate(t *testing.T) {
+	uc := NewWebhookUsecase(newFakeRepository(), whk.NewHTTPSender(whk.SendTimeout))
+
+	created, err := uc.NewSubscription(whk.SubscriptionInput{
+		Name:   "partner",
+		URL:    "https://partner.example/hook",
+		Events: []string{whk.EventReportApproved},
+	}, "ADM0001")
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	if created.Secret == "" {
+		t.Fatal("create response has no secret")
+	}
+
+	found, err := uc.GetSubscriptionByID(created.ID)
+	if err != nil {
+		t.Fatalf("get subscription: %v", err)
+	}
+	if found.Secret != "" {
+		t.Error("get response shows the secret")
+	}
+
+	all, err := uc.GetAllSubscriptions()
+	if err != nil {
+		t.Fatalf("list subscriptions: %v", err)
+	}
+	for _, subscription := range *all {
+		if subscription.Secret != "" {
+			t.Errorf("list response shows the secret of subscription %d", subscription.ID)
+		}
+	}
+}
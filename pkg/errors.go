@@ -24,7 +24,8 @@ var (
 	ErrRole          = errors.New("role must be admin or super admin")
 
 	// Report
-	ErrReportNotFound = errors.New("report not found")
+	ErrReportNotFound    = errors.New("report not found")
+	ErrReportNotApproved = errors.New("only approved reports can be resolved")
 
 	// Date
 	ErrDateFormat = errors.New("invalid date format")
@@ -32,32 +33,56 @@ var (
 	// Manage Task
 	ErrTaskStepsNull           = errors.New("steps cannot be null")
 	ErrTaskNotFound            = errors.New("task not found")
+	ErrTaskStepOrder           = errors.New("step order must list every step of the task once")
+	ErrMaxParticipants         = errors.New("max participants cannot be negative")
+	ErrMaxResubmissions        = errors.New("max resubmissions must be between 1 and 10")
 	ErrParsedTime              = errors.New("start date or end data is invalid")
 	ErrThumbnail               = errors.New("thumbnail is required")
 	ErrThumbnailMaximum        = errors.New("thumbnail must be one image")
 	ErrUserTaskAlreadyAccepted = errors.New("cannot reject user task because it already accepted")
 
+	// Task Template
+	ErrTaskTemplateNotFound   = errors.New("task template not found")
+	ErrTaskTemplateRecurrence = errors.New("invalid recurrence rule")
+
 	// User Task
 	ErrImageTaskNull                = errors.New("image task cannot be null")
 	ErrUserTaskExist                = errors.New("user task already exist")
 	ErrUserTaskNotFound             = errors.New("user task not found")
 	ErrUserTaskDone                 = errors.New("user task already done")
 	ErrTaskCannotBeFollowed         = errors.New("task cannot be followed")
+	ErrTaskNotStarted               = errors.New("task has not started yet")
+	ErrTaskFull                     = errors.New("task has reached its participant limit")
+	ErrTaskEnrollmentClosed         = errors.New("task enrollment is closed")
+	ErrTaskRegionRestricted         = errors.New("task is not available in your province")
+	ErrTaskAchievementRequired      = errors.New("task requires a higher achievement level")
 	ErrUserNoHasTask                = errors.New("user has no task")
 	ErrImagesExceed                 = errors.New("image exceed limit")
 	ErrUserTaskNotReject            = errors.New("user task not reject")
 	ErrUserTaskAlreadyReject        = errors.New("user task already reject")
 	ErrUserTaskAlreadyApprove       = errors.New("user task already approve")
+	ErrRejectReasonRequired         = errors.New("reject reason is required")
+	ErrUserTaskClaimed              = errors.New("user task is being reviewed by another admin")
+	ErrUserTaskNotClaimed           = errors.New("user task is not claimed by you")
+	ErrUserTaskNotReviewable        = errors.New("user task is not waiting for review")
+	ErrResubmissionLimit            = errors.New("user task has no resubmissions left")
+	ErrUserTaskAbandoned            = errors.New("user task already withdrawn")
+	ErrTaskRejoinNotAllowed         = errors.New("task cannot be joined again after withdrawing")
+	ErrUserTaskNotWithdrawable      = errors.New("only user tasks in progress or rejected can be withdrawn")
 	ErrTaskStepNotFound             = errors.New("task step not found")
 	ErrTaskStepDone                 = errors.New("task step already done")
 	ErrUserTaskStepNotFound         = errors.New("user task step not found")
 	ErrUserTaskNotCompleted         = errors.New("task step not completed")
 	ErrStepNotInOrder               = errors.New("task step must be completed in order")
+	ErrStepPhotoRequired            = errors.New("photo is required for this task step")
 	ErrUserTaskStepAlreadyCompleted = errors.New("user task step already completed")
 
 	// manage achievement
 	ErrAchievementLevelAlreadyExist = errors.New("achievement level already exist")
 	ErrAchievementNotFound          = errors.New("achievement not found")
+	ErrLevelJobNotFound             = errors.New("level recompute job not found")
+	ErrAchievementTarget            = errors.New("target count must be at least 1 for this achievement kind")
+	ErrAchievementWasteMaterial     = errors.New("waste material not found")
 	ErrBadge                        = errors.New("badge is required")
 	ErrBadgeMaximum                 = errors.New("badge must be one image")
 
@@ -86,6 +111,42 @@ var (
 	// Article
 	ErrArticleNotFound         = errors.New("article not found")
 	ErrCategoryArticleNotFound = errors.New("invalid category type")
+	ErrArticleStatus           = errors.New("article cannot move to this status")
+	ErrArticlePublishAt        = errors.New("publish_at must be a future time in RFC3339 format")
+
+	// Webhook
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// Point Ledger
+	ErrPointEntryNotFound      = errors.New("point entry not found")
+	ErrPointEntryExists        = errors.New("point entry for this source already exists")
+	ErrPointEntryReversed      = errors.New("point entry already reversed")
+	ErrPointEntryNotReversible = errors.New("reversal entries cannot be reversed")
+	ErrPointBalanceNegative    = errors.New("point balance cannot go below zero")
+	ErrPointInsufficient       = errors.New("not enough points")
+
+	// Reward
+	ErrRewardNotFound       = errors.New("reward not found")
+	ErrRewardUnavailable    = errors.New("reward is not available")
+	ErrRewardOutOfStock     = errors.New("reward is out of stock")
+	ErrRewardInvalidDate    = errors.New("invalid reward validity, use YYYY-MM-DD and end on or after the start")
+	ErrRedemptionNotFound   = errors.New("redemption not found")
+	ErrRedemptionNotPending = errors.New("redemption is no longer pending")
+
+	// Leaderboard
+	ErrLeaderboardPeriod = errors.New("leaderboard period must be weekly, monthly or all_time")
+	ErrLeaderboardLimit  = errors.New("limit must be at most 100")
+	ErrSeasonNotFound    = errors.New("season not found")
+	ErrSeasonInvalidDate = errors.New("invalid season dates, use YYYY-MM-DD and end on or after the start")
+	ErrSeasonOverlap     = errors.New("season overlaps another season")
+	ErrSeasonRewardRank  = errors.New("every season reward needs a different rank")
+	ErrSeasonEnded       = errors.New("season has ended, its results can no longer change")
+
+	// Streak
+	ErrStreakPeriod            = errors.New("streak period must be daily or weekly")
+	ErrStreakMilestoneExists   = errors.New("streak milestone with this period and length already exists")
+	ErrStreakMilestoneNotFound = errors.New("streak milestone not found")
 
 	// Error file
 	ErrFileTooLarge    = errors.New("upload image size must less than 2MB")